@@ -0,0 +1,274 @@
+/*
+Servidor local que substitui a API externa https://bc-desafio.mybluemix.net/atualizar,
+permitindo testar as integrações do chaincode sem acesso à internet.
+
+Endpoints:
+	POST   /atualizar                  registra a proposta recebida (JSON)
+	GET    /atualizar[?id_proposta=X]  lista as propostas recebidas
+	DELETE /atualizar                  limpa as propostas recebidas
+
+Falhas podem ser injetadas globalmente pelas flags (-latencia, -taxa-erro,
+-taxa-timeout) ou por requisição, através dos headers:
+	X-Mock-Latencia: 2s   atrasa a resposta
+	X-Mock-Status: 503    responde com o status informado
+	X-Mock-Timeout: true  segura a requisição sem responder
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PropostaRecebida - registro de uma proposta recebida pelo POST /atualizar
+type PropostaRecebida struct {
+	RecebidoEm time.Time       `json:"recebido_em"`
+	IDProposta string          `json:"id_proposta"`
+	Proposta   json.RawMessage `json:"proposta"`
+}
+
+// Falhas - configuração global de injeção de falhas
+type Falhas struct {
+	Latencia       time.Duration // atraso aplicado a todas as requisições
+	TaxaErro       float64       // fração das requisições respondidas com 503
+	TaxaTimeout    float64       // fração das requisições que nunca são respondidas
+	DuracaoTimeout time.Duration // tempo máximo que uma requisição fica presa
+}
+
+// servidor - estado do mock
+type servidor struct {
+	mu        sync.Mutex
+	recebidas []PropostaRecebida
+	arquivo   string
+	falhas    Falhas
+}
+
+// ============================================================================================================================
+// Main
+// ============================================================================================================================
+func main() {
+	endereco := flag.String("endereco", ":8080", "endereço em que o servidor irá escutar")
+	arquivo := flag.String("arquivo", "", "arquivo JSON para persistir as propostas recebidas (vazio = apenas em memória)")
+	latencia := flag.Duration("latencia", 0, "latência adicionada a todas as requisições")
+	taxaErro := flag.Float64("taxa-erro", 0, "fração (0 a 1) das requisições respondidas com erro 5xx")
+	taxaTimeout := flag.Float64("taxa-timeout", 0, "fração (0 a 1) das requisições que não serão respondidas")
+	duracaoTimeout := flag.Duration("duracao-timeout", 30*time.Second, "tempo que uma requisição em timeout fica presa")
+	flag.Parse()
+
+	s := &servidor{
+		arquivo: *arquivo,
+		falhas: Falhas{
+			Latencia:       *latencia,
+			TaxaErro:       *taxaErro,
+			TaxaTimeout:    *taxaTimeout,
+			DuracaoTimeout: *duracaoTimeout,
+		},
+	}
+	if err := s.carregar(); err != nil {
+		log.Fatalf("Falha ao carregar o arquivo %s: %v", s.arquivo, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/atualizar", s.atualizar)
+
+	log.Printf("bc-desafio-mock escutando em %s", *endereco)
+	log.Fatal(http.ListenAndServe(*endereco, mux))
+}
+
+// atualizar: handler do endpoint /atualizar
+func (s *servidor) atualizar(w http.ResponseWriter, r *http.Request) {
+	if s.aplicarFalhas(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		s.receberProposta(w, r)
+	case "GET":
+		s.listarPropostas(w, r)
+	case "DELETE":
+		s.limparPropostas(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		responderErro(w, http.StatusMethodNotAllowed, "Método não suportado: "+r.Method)
+	}
+}
+
+// receberProposta: registra a proposta enviada no corpo da requisição
+func (s *servidor) receberProposta(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		responderErro(w, http.StatusBadRequest, "Falha ao ler o corpo da requisição")
+		return
+	}
+
+	// Apenas o id_proposta é obrigatório, o restante é armazenado como recebido
+	var proposta struct {
+		ID string `json:"id_proposta"`
+	}
+	if err := json.Unmarshal(body, &proposta); err != nil {
+		responderErro(w, http.StatusBadRequest, "JSON inválido: "+err.Error())
+		return
+	}
+	if proposta.ID == "" {
+		responderErro(w, http.StatusBadRequest, "Campo id_proposta não informado")
+		return
+	}
+
+	s.mu.Lock()
+	s.recebidas = append(s.recebidas, PropostaRecebida{
+		RecebidoEm: time.Now().UTC(),
+		IDProposta: proposta.ID,
+		Proposta:   json.RawMessage(body),
+	})
+	err = s.salvar()
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("Falha ao persistir as propostas recebidas: %v", err)
+		responderErro(w, http.StatusInternalServerError, "Falha ao persistir a proposta")
+		return
+	}
+
+	log.Printf("Proposta [%s] recebida", proposta.ID)
+	responderJSON(w, http.StatusOK, map[string]interface{}{"recebido": true, "id_proposta": proposta.ID})
+}
+
+// listarPropostas: retorna as propostas recebidas, opcionalmente filtradas por id_proposta
+func (s *servidor) listarPropostas(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id_proposta")
+
+	s.mu.Lock()
+	lista := []PropostaRecebida{}
+	for _, p := range s.recebidas {
+		if id == "" || p.IDProposta == id {
+			lista = append(lista, p)
+		}
+	}
+	s.mu.Unlock()
+
+	responderJSON(w, http.StatusOK, lista)
+}
+
+// limparPropostas: descarta todas as propostas recebidas
+func (s *servidor) limparPropostas(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.recebidas = nil
+	err := s.salvar()
+	s.mu.Unlock()
+	if err != nil {
+		responderErro(w, http.StatusInternalServerError, "Falha ao persistir a limpeza")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// aplicarFalhas: aplica latência, erros e timeouts configurados.
+// Retorna true se a requisição já foi respondida.
+func (s *servidor) aplicarFalhas(w http.ResponseWriter, r *http.Request) bool {
+	latencia := s.falhas.Latencia
+	if v := r.Header.Get("X-Mock-Latencia"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			responderErro(w, http.StatusBadRequest, "X-Mock-Latencia inválido: "+v)
+			return true
+		}
+		latencia = d
+	}
+	if latencia > 0 {
+		select {
+		case <-time.After(latencia):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+
+	if r.Header.Get("X-Mock-Timeout") == "true" || sortear(s.falhas.TaxaTimeout) {
+		log.Printf("Simulando timeout para %s %s", r.Method, r.URL.Path)
+		select {
+		case <-time.After(s.falhas.DuracaoTimeout):
+			responderErro(w, http.StatusGatewayTimeout, "Timeout simulado")
+		case <-r.Context().Done():
+		}
+		return true
+	}
+
+	if v := r.Header.Get("X-Mock-Status"); v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil || status < 100 || status > 599 {
+			responderErro(w, http.StatusBadRequest, "X-Mock-Status inválido: "+v)
+			return true
+		}
+		responderErro(w, status, "Erro simulado")
+		return true
+	}
+	if sortear(s.falhas.TaxaErro) {
+		responderErro(w, http.StatusServiceUnavailable, "Erro simulado")
+		return true
+	}
+
+	return false
+}
+
+// sortear: retorna true com a probabilidade informada
+func sortear(taxa float64) bool {
+	return taxa > 0 && rand.Float64() < taxa
+}
+
+// carregar: lê as propostas persistidas em execuções anteriores
+func (s *servidor) carregar() error {
+	if s.arquivo == "" {
+		return nil
+	}
+	dados, err := ioutil.ReadFile(s.arquivo)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(dados, &s.recebidas)
+}
+
+// salvar: persiste as propostas recebidas no arquivo configurado.
+// Deve ser chamada com s.mu bloqueado.
+func (s *servidor) salvar() error {
+	if s.arquivo == "" {
+		return nil
+	}
+	dados, err := json.MarshalIndent(s.recebidas, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Escreve em um arquivo temporário para não corromper o arquivo em caso de falha
+	tmp := s.arquivo + ".tmp"
+	if err := ioutil.WriteFile(tmp, dados, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.arquivo)
+}
+
+// responderJSON: escreve v em formato JSON com o status informado
+func responderJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Falha ao escrever a resposta: %v", err)
+	}
+}
+
+// responderErro: escreve uma mensagem de erro em formato JSON
+func responderErro(w http.ResponseWriter, status int, mensagem string) {
+	responderJSON(w, status, map[string]string{"erro": fmt.Sprintf("%d %s", status, mensagem)})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// identidadeCaller: identificação única (subject e issuer do certificado X.509) do caller da transação
+var identidadeCaller = func(stub shim.ChaincodeStubInterface) (string, error) {
+	return cid.GetID(stub)
+}
+
+// ============================================================================================================================
+// Módulo autenticacao
+// ============================================================================================================================
+
+// verificarAdmin: verifica se o caller da chamada é o administrador registrado no Init.
+// Sem o módulo autenticacao qualquer caller é aceito
+func (t *BoletoPropostaChaincode) verificarAdmin(stub shim.ChaincodeStubInterface) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Autenticacao {
+		return nil
+	}
+
+	adminID, err := stub.GetState("admin")
+	if err != nil {
+		return errors.New("Failed fetching admin identity")
+	}
+
+	ok, err := t.isCaller(stub, string(adminID))
+	if err != nil {
+		return errors.New("Failed checking admin identity")
+	}
+	if !ok {
+		return errors.New("The caller is not an administrator")
+	}
+	return nil
+}
+
+// isCaller: função utilizada para verificar quem é o caller da chamada.
+// A identidade é obtida do certificado do criador da transação, já validado pelo peer
+func (t *BoletoPropostaChaincode) isCaller(stub shim.ChaincodeStubInterface, id string) (bool, error) {
+	fmt.Println("Check caller...")
+
+	callerID, err := identidadeCaller(stub)
+	if err != nil {
+		return false, errors.New("Failed getting caller identity")
+	}
+
+	fmt.Printf("passed id [%s]\n", id)
+	fmt.Printf("caller id [%s]\n", callerID)
+
+	if id == "" || callerID != id {
+		fmt.Println("Invalid identity")
+		return false, errors.New("Certificado inválido")
+	}
+
+	fmt.Println("Check caller...Verified!")
+	// Certificado válido
+	return true, nil
+}
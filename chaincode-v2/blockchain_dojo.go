@@ -0,0 +1,274 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Implementação iniciada por Caue Garcia Polimanti e Vitor Diego dos Santos de Sousa
+
+Versão do chaincode de propostas de boleto para o Fabric 2.x (fabric-chaincode-go),
+com as mesmas funções e respostas JSON do chaincode da versão 0.6 (diretório chaincode):
+	- as funções são obtidas com GetFunctionAndParameters, e as consultas são executadas pelo Invoke
+	- as tabelas foram substituídas por chaves compostas com o JSON dos registros
+	- o administrador é identificado pelo certificado do caller (pkg/cid), e não pela metadata
+
+Os comportamentos opcionais são módulos habilitados pelos argumentos do init:
+	autenticacao: apenas o administrador (caller do Init) pode alterar propostas e oráculos
+	notificacao:  as alterações das propostas emitem eventos para entrega pelo relay
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// BoletoPropostaChaincode - implementacao do chaincode
+type BoletoPropostaChaincode struct {
+}
+
+// Definição da Struct Modulos, módulos opcionais habilitados no Init
+type Modulos struct {
+	Autenticacao bool `json:"autenticacao"`
+	Notificacao  bool `json:"notificacao"`
+}
+
+// nomes dos módulos aceitos como argumento do Init
+const (
+	moduloAutenticacao = "autenticacao"
+	moduloNotificacao  = "notificacao"
+	chaveModulos       = "modulos" // chave do estado com os módulos habilitados
+)
+
+// ============================================================================================================================
+// Main
+// ============================================================================================================================
+func main() {
+	err := shim.Start(new(BoletoPropostaChaincode))
+	if err != nil {
+		fmt.Printf("Error starting BoletoPropostaChaincode chaincode: %s", err)
+	}
+}
+
+// ============================================================================================================================
+// Init
+// 		Inicia/Reinicia o estado das propostas
+// ============================================================================================================================
+
+// Init - chamado na instanciação do chaincode (--isInit), recebendo os argumentos da função "init"
+func (t *BoletoPropostaChaincode) Init(stub shim.ChaincodeStubInterface) *pb.Response {
+	_, args := stub.GetFunctionAndParameters()
+	return resposta(t.init(stub, args))
+}
+
+// init recebe como argumentos os nomes dos módulos a habilitar ("autenticacao", "notificacao").
+// Sem argumentos, nenhum módulo é habilitado.
+func (t *BoletoPropostaChaincode) init(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("Init Chaincode...")
+
+	modulos, err := lerModulos(args)
+	if err != nil {
+		return nil, err
+	}
+	modulosAsBytes, err := json.Marshal(modulos)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling Modulos: %s", err)
+	}
+	err = stub.PutState(chaveModulos, modulosAsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao registrar os módulos: [%s]", err)
+	}
+	fmt.Printf("Módulos: autenticacao [%t], notificacao [%t]\n", modulos.Autenticacao, modulos.Notificacao)
+
+	// Exclui as propostas existentes. Os eventos e entregas são mantidos no reset
+	fmt.Println("Excluindo as propostas existentes...")
+	propostas, err := stub.GetStateByPartialCompositeKey(tipoChaveProposta, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao excluir as propostas. [%v]", err)
+	}
+	defer propostas.Close()
+	for propostas.HasNext() {
+		kv, err := propostas.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Falha ao excluir as propostas. [%v]", err)
+		}
+		err = stub.DelState(kv.Key)
+		if err != nil {
+			return nil, fmt.Errorf("Falha ao excluir as propostas. [%v]", err)
+		}
+	}
+
+	if modulos.Autenticacao {
+		// Set the admin
+		// The creator certificate of the transaction identifies the administrator
+		adminID, err := identidadeCaller(stub)
+		if err != nil {
+			return nil, errors.New("Failed getting caller identity")
+		}
+
+		fmt.Printf("The administrator is [%s]\n", adminID)
+		err = stub.PutState("admin", []byte(adminID))
+		if err != nil {
+			return nil, fmt.Errorf("Falha ao registrar o administrador: [%s]", err)
+		}
+	}
+
+	fmt.Println("Init Chaincode... Finalizado!")
+
+	return nil, nil
+}
+
+// lerModulos: converte os argumentos do Init nos módulos habilitados
+func lerModulos(args []string) (Modulos, error) {
+	var modulos Modulos
+	for _, nome := range args {
+		switch nome {
+		case moduloAutenticacao:
+			modulos.Autenticacao = true
+		case moduloNotificacao:
+			modulos.Notificacao = true
+		default:
+			return modulos, errors.New("Módulo desconhecido: " + nome)
+		}
+	}
+	return modulos, nil
+}
+
+// obterModulos: retorna os módulos habilitados no Init
+func (t *BoletoPropostaChaincode) obterModulos(stub shim.ChaincodeStubInterface) (Modulos, error) {
+	var modulos Modulos
+	modulosAsBytes, err := stub.GetState(chaveModulos)
+	if err != nil {
+		return modulos, fmt.Errorf("Falha ao obter os módulos: [%s]", err)
+	}
+	if len(modulosAsBytes) == 0 {
+		return modulos, nil
+	}
+	err = json.Unmarshal(modulosAsBytes, &modulos)
+	if err != nil {
+		return modulos, fmt.Errorf("Falha ao decodificar os módulos: [%s]", err)
+	}
+	return modulos, nil
+}
+
+// resposta: converte o retorno das funções do chaincode na resposta do peer
+func resposta(payload []byte, err error) *pb.Response {
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(payload)
+}
+
+// ============================================================================================================================
+// Invoke Functions
+// ============================================================================================================================
+
+// Invoke - Ponto de entrada para chamadas do chaincode. As funções de consulta
+// (ver consultar) também são executadas pelo Invoke, como query no peer.
+// Funções suportadas:
+// "init": reinicia o estado do chaincode, recebendo os mesmos argumentos do Init.
+// With the autenticacao module, only an administrator can call this function.
+// "registrarProposta(Id, cpfPagador, pagadorAceitou,
+// beneficiarioAceitou, boletoPago[, valor])": para registrar uma nova proposta ou atualizar uma já existente.
+// With the autenticacao module, only an administrator can call this function.
+// "confirmarEntrega(idEvento, assinante, status)": para registrar o resultado da entrega de um evento.
+// Requires the notificacao module. With the autenticacao module, only an administrator can call this function.
+// "registrarOraculo(idOraculo, chavePublica)", "removerOraculo(idOraculo)" e "configurarQuorumOraculos(quorum)":
+// para manter os oráculos de pagamento. With the autenticacao module, only an administrator can call these functions.
+// "confirmarPagamentoOracle(atestado, assinatura)": para registrar o atestado de pagamento de um oráculo.
+// O boleto é marcado como pago quando o quorum de oráculos envia atestados coincidentes.
+// "resolverDisputaPagamento(Id[, idOraculo...])": para descartar os atestados pendentes de uma proposta em disputa.
+// With the autenticacao module, only an administrator can call this function.
+func (t *BoletoPropostaChaincode) Invoke(stub shim.ChaincodeStubInterface) *pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	fmt.Println("Invoke Chaincode...")
+	fmt.Println("invoke is running " + function)
+
+	// Estrutura de Seleção para escolher qual função será chamada,
+	// de acordo com a funcao chamada
+	if function == "init" {
+		// Com o módulo autenticacao habilitado, somente o administrador pode reiniciar o estado
+		if err := t.verificarAdmin(stub); err != nil {
+			return resposta(nil, err)
+		}
+		return resposta(t.init(stub, args))
+	} else if function == "registrarProposta" {
+		return resposta(t.registrarProposta(stub, args))
+	} else if function == "confirmarEntrega" {
+		return resposta(t.confirmarEntrega(stub, args))
+	} else if function == "registrarOraculo" {
+		return resposta(t.registrarOraculo(stub, args))
+	} else if function == "removerOraculo" {
+		return resposta(t.removerOraculo(stub, args))
+	} else if function == "configurarQuorumOraculos" {
+		return resposta(t.configurarQuorumOraculos(stub, args))
+	} else if function == "confirmarPagamentoOracle" {
+		return resposta(t.confirmarPagamentoOracle(stub, args))
+	} else if function == "resolverDisputaPagamento" {
+		return resposta(t.resolverDisputaPagamento(stub, args))
+	}
+
+	return resposta(t.consultar(stub, function, args))
+}
+
+// ============================================================================================================================
+// Query
+// ============================================================================================================================
+
+// consultar - funções de consulta, sem alteração do estado:
+// "consultarProposta(Id)": para consultar uma proposta existente
+// "consultarEventos(aPartirDe)": para consultar os eventos emitidos após a sequência informada (módulo notificacao)
+// "consultarEntregas(Id)": para consultar quais assinantes confirmaram a entrega dos eventos de uma proposta (módulo notificacao)
+// "consultarOraculos()": para consultar os oráculos de pagamento registrados e o quorum
+// "consultarPagamento(Id)": para consultar os atestados que confirmaram o pagamento de uma proposta
+// "consultarAtestadosPendentes(Id)": para consultar os atestados de uma proposta que ainda não atingiram o quorum
+// "versao()": para consultar a versão do chaincode, os módulos habilitados e a quantidade de registros
+func (t *BoletoPropostaChaincode) consultar(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	fmt.Println("query is running " + function)
+
+	// Estrutura de Seleção para escolher qual função será chamada,
+	// de acordo com a funcao chamada
+	if function == "consultarProposta" {
+		// Consultar uma Proposta existente
+		return t.consultarProposta(stub, args)
+	} else if function == "consultarEventos" {
+		// Consultar os eventos emitidos a partir de uma sequência
+		return t.consultarEventos(stub, args)
+	} else if function == "consultarEntregas" {
+		// Consultar as entregas dos eventos de uma proposta
+		return t.consultarEntregas(stub, args)
+	} else if function == "consultarOraculos" {
+		// Consultar os oráculos de pagamento registrados
+		return t.consultarOraculos(stub, args)
+	} else if function == "consultarPagamento" {
+		// Consultar o atestado que confirmou o pagamento de uma proposta
+		return t.consultarPagamento(stub, args)
+	} else if function == "consultarAtestadosPendentes" {
+		// Consultar os atestados que ainda não atingiram o quorum
+		return t.consultarAtestadosPendentes(stub, args)
+	} else if function == "versao" {
+		// Consultar a versão e o estado do chaincode implantado
+		return t.versao(stub, args)
+	}
+	fmt.Println("invoke não encontrou a func: " + function) //error
+
+	return nil, errors.New("Invocação de função desconhecida: " + function)
+}
@@ -0,0 +1,348 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"golang.org/x/crypto/sha3"
+)
+
+// identidade do administrador utilizada nos testes
+const adminTeste = "admin"
+
+// stubTeste - stub em memória com os métodos de shim.ChaincodeStubInterface utilizados pelo chaincode.
+// Os demais métodos não são implementados e causam panic se chamados.
+type stubTeste struct {
+	shim.ChaincodeStubInterface
+	estado  map[string][]byte
+	args    []string
+	caller  string
+	txID    string
+	seqTx   int
+	eventos map[string][]byte // eventos emitidos, por transação
+}
+
+func novoStubTeste() *stubTeste {
+	return &stubTeste{estado: map[string][]byte{}, eventos: map[string][]byte{}, caller: adminTeste}
+}
+
+// executar: executa uma chamada como transação, descartando as alterações em caso de erro
+func (s *stubTeste) executar(f func(shim.ChaincodeStubInterface) *pb.Response, args ...string) *pb.Response {
+	s.seqTx++
+	s.txID = fmt.Sprintf("tx%d", s.seqTx)
+	s.args = args
+
+	anterior := map[string][]byte{}
+	for k, v := range s.estado {
+		anterior[k] = v
+	}
+	res := f(s)
+	if res.Status != shim.OK {
+		s.estado = anterior
+		delete(s.eventos, s.txID)
+	}
+	return res
+}
+
+func (s *stubTeste) GetFunctionAndParameters() (string, []string) {
+	if len(s.args) == 0 {
+		return "", []string{}
+	}
+	return s.args[0], s.args[1:]
+}
+
+func (s *stubTeste) GetTxID() string { return s.txID }
+
+func (s *stubTeste) GetState(key string) ([]byte, error) { return s.estado[key], nil }
+
+func (s *stubTeste) PutState(key string, value []byte) error {
+	s.estado[key] = value
+	return nil
+}
+
+func (s *stubTeste) DelState(key string) error {
+	delete(s.estado, key)
+	return nil
+}
+
+func (s *stubTeste) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	chave := "\x00" + objectType + "\x00"
+	for _, a := range attributes {
+		chave += a + "\x00"
+	}
+	return chave, nil
+}
+
+func (s *stubTeste) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	prefixo, _ := s.CreateCompositeKey(objectType, keys)
+	it := &iteradorTeste{}
+	for k, v := range s.estado {
+		if strings.HasPrefix(k, prefixo) {
+			it.kvs = append(it.kvs, &queryresult.KV{Key: k, Value: v})
+		}
+	}
+	sort.Slice(it.kvs, func(i, j int) bool { return it.kvs[i].Key < it.kvs[j].Key })
+	return it, nil
+}
+
+func (s *stubTeste) SetEvent(name string, payload []byte) error {
+	if name != nomeEventoProposta {
+		return errors.New("evento inesperado: " + name)
+	}
+	s.eventos[s.txID] = payload
+	return nil
+}
+
+// iteradorTeste - resultado de GetStateByPartialCompositeKey
+type iteradorTeste struct {
+	kvs []*queryresult.KV
+}
+
+func (it *iteradorTeste) HasNext() bool { return len(it.kvs) > 0 }
+func (it *iteradorTeste) Close() error  { return nil }
+func (it *iteradorTeste) Next() (*queryresult.KV, error) {
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+	return kv, nil
+}
+
+func init() {
+	identidadeCaller = func(stub shim.ChaincodeStubInterface) (string, error) {
+		return stub.(*stubTeste).caller, nil
+	}
+}
+
+// novoChaincode: cria o stub e executa o Init como administrador, com todos os módulos habilitados
+func novoChaincode(t *testing.T) (*stubTeste, *BoletoPropostaChaincode) {
+	stub := novoStubTeste()
+	cc := new(BoletoPropostaChaincode)
+	if res := stub.executar(cc.Init, "init", moduloAutenticacao, moduloNotificacao); res.Status != shim.OK {
+		t.Fatalf("Init: %s", res.Message)
+	}
+	return stub, cc
+}
+
+// invocar: executa a função informada e falha o teste em caso de erro
+func invocar(t *testing.T, stub *stubTeste, cc *BoletoPropostaChaincode, args ...string) []byte {
+	res := stub.executar(cc.Invoke, args...)
+	if res.Status != shim.OK {
+		t.Fatalf("%s: %s", args[0], res.Message)
+	}
+	return res.Payload
+}
+
+// verificarErro: executa a função informada e verifica se o erro contém o trecho esperado
+func verificarErro(t *testing.T, stub *stubTeste, cc *BoletoPropostaChaincode, trecho string, args ...string) {
+	res := stub.executar(cc.Invoke, args...)
+	if res.Status == shim.OK {
+		t.Errorf("%v: esperado erro contendo %q", args, trecho)
+		return
+	}
+	if !strings.Contains(res.Message, trecho) {
+		t.Errorf("%v: erro %q não contém %q", args, res.Message, trecho)
+	}
+}
+
+func TestInit(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	if string(stub.estado["admin"]) != adminTeste {
+		t.Errorf("admin = %q; esperado %q", stub.estado["admin"], adminTeste)
+	}
+	if res := stub.executar(cc.Init, "init", "x"); !strings.Contains(res.Message, "Módulo desconhecido: x") {
+		t.Errorf("Init com módulo desconhecido: %+v", res)
+	}
+
+	// O reset exclui as propostas, mas mantém os eventos
+	invocar(t, stub, cc, "registrarProposta", "p1", "111", "false", "false", "false")
+	stub.caller = "outro"
+	verificarErro(t, stub, cc, "Failed checking admin identity", "init")
+	stub.caller = adminTeste
+	invocar(t, stub, cc, "init", moduloAutenticacao, moduloNotificacao)
+	verificarErro(t, stub, cc, "Proposta [p1] não existente.", "consultarProposta", "p1")
+	if seq, _ := cc.ultimaSequenciaEvento(stub); seq != 1 {
+		t.Errorf("sequência de eventos após reset = %d; esperado 1", seq)
+	}
+
+	verificarErro(t, stub, cc, "Invocação de função desconhecida: naoExiste", "naoExiste")
+}
+
+func TestRegistrarProposta(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	res := invocar(t, stub, cc, "registrarProposta", "p1", "373.745.808-20", "true", "false", "false", "15000")
+	if string(res) != `{"registrado":"true"}` {
+		t.Errorf("resposta da criação = %s", res)
+	}
+	res = invocar(t, stub, cc, "registrarProposta", "p1", "373.745.808-20", "true", "true", "false", "15000")
+	if string(res) != `{"atualizado":"true"}` {
+		t.Errorf("resposta da atualização = %s", res)
+	}
+	var eventos []Evento
+	json.Unmarshal(stub.eventos[stub.txID], &eventos)
+	if len(eventos) != 2 || eventos[0].Tipo != eventoPropostaAtualizada || eventos[1].Tipo != eventoPropostaAceita {
+		t.Errorf("eventos da atualização = %+v", eventos)
+	}
+
+	res = invocar(t, stub, cc, "consultarProposta", "p1")
+	esperado := `{"id_proposta":"p1","cpf_pagador":"373.745.808-20","pagador_aceitou":true,"beneficiario_aceitou":true,"boleto_pago":false,"valor":15000}`
+	if string(res) != esperado {
+		t.Errorf("consultarProposta = %s; esperado %s", res, esperado)
+	}
+
+	verificarErro(t, stub, cc, "Expecting 5 or 6", "registrarProposta", "p1")
+	stub.caller = "outro"
+	verificarErro(t, stub, cc, "Failed checking admin identity", "registrarProposta", "p1", "111", "true", "false", "false")
+}
+
+func TestEntregas(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	for i := 0; i < 10; i++ {
+		invocar(t, stub, cc, "registrarProposta", "p1", "111", "false", "false", "false")
+	}
+
+	for _, id := range []string{"10", "2"} {
+		invocar(t, stub, cc, "confirmarEntrega", id, "bc-desafio", statusEntregue)
+	}
+	invocar(t, stub, cc, "confirmarEntrega", "2", "bc-desafio", statusRejeitado)
+
+	var entregas []Entrega
+	json.Unmarshal(invocar(t, stub, cc, "consultarEntregas", "p1"), &entregas)
+	if len(entregas) != 2 || entregas[0].IDEvento != "2" || entregas[0].Status != statusRejeitado || entregas[1].IDEvento != "10" {
+		t.Errorf("entregas = %+v", entregas)
+	}
+
+	verificarErro(t, stub, cc, "Evento [99] não existente.", "confirmarEntrega", "99", "bc-desafio", statusEntregue)
+}
+
+func TestConfirmarPagamentoOracle(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&chave.PublicKey)
+	chavePEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	invocar(t, stub, cc, "registrarOraculo", "banco-1", chavePEM)
+	invocar(t, stub, cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+
+	// A assinatura cobre o JSON canônico do atestado, com as chaves ordenadas
+	atestado := `{"id_oraculo":"banco-1","id_proposta":"p1","valor_pago":15000,"data_pagamento":"2016-11-30","codigo_autenticacao":"A1"}`
+	canonico := `{"codigo_autenticacao":"A1","data_pagamento":"2016-11-30","id_oraculo":"banco-1","id_proposta":"p1","valor_pago":15000}`
+	hash := sha3.Sum256([]byte(canonico))
+	assinatura, err := ecdsa.SignASN1(rand.Reader, chave, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verificarErro(t, stub, cc, "Assinatura do atestado inválida", "confirmarPagamentoOracle", atestado, base64.StdEncoding.EncodeToString([]byte("x")))
+	verificarErro(t, stub, cc, "Failed decoding atestado", "confirmarPagamentoOracle", strings.Replace(atestado, `{`, `{"observacao":"x",`, 1), base64.StdEncoding.EncodeToString(assinatura))
+	verificarErro(t, stub, cc, "não correspondem ao JSON assinado", "confirmarPagamentoOracle", strings.Replace(atestado, `"id_proposta"`, `"ID_PROPOSTA"`, 1), base64.StdEncoding.EncodeToString(assinatura))
+
+	res := invocar(t, stub, cc, "confirmarPagamentoOracle", atestado, base64.StdEncoding.EncodeToString(assinatura))
+	if string(res) != `{"pago":"true","atestados":"1","quorum":"1"}` {
+		t.Errorf("confirmarPagamentoOracle = %s", res)
+	}
+
+	var p Proposta
+	json.Unmarshal(invocar(t, stub, cc, "consultarProposta", "p1"), &p)
+	if !p.BoletoPago {
+		t.Error("boleto não marcado como pago")
+	}
+
+	// A remoção do último oráculo reinicia o quorum
+	invocar(t, stub, cc, "removerOraculo", "banco-1")
+	res = invocar(t, stub, cc, "consultarOraculos")
+	if string(res) != `{"quorum":1,"oraculos":[]}` {
+		t.Errorf("consultarOraculos após remover o último oráculo = %s", res)
+	}
+}
+
+func TestResolverDisputaPagamento(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	chaves := map[string]*ecdsa.PrivateKey{}
+	for _, id := range []string{"banco-1", "banco-2", "banco-3"} {
+		chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, _ := x509.MarshalPKIXPublicKey(&chave.PublicKey)
+		invocar(t, stub, cc, "registrarOraculo", id, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+		chaves[id] = chave
+	}
+	invocar(t, stub, cc, "configurarQuorumOraculos", "2")
+	invocar(t, stub, cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+
+	// atestar: argumentos de confirmarPagamentoOracle com o atestado do oráculo para a data informada
+	atestar := func(idOraculo, data string) []string {
+		atestado := fmt.Sprintf(`{"codigo_autenticacao":"A1","data_pagamento":"%s","id_oraculo":"%s","id_proposta":"p1","valor_pago":15000}`, data, idOraculo)
+		hash := sha3.Sum256([]byte(atestado))
+		assinatura, err := ecdsa.SignASN1(rand.Reader, chaves[idOraculo], hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []string{"confirmarPagamentoOracle", atestado, base64.StdEncoding.EncodeToString(assinatura)}
+	}
+
+	verificarErro(t, stub, cc, "Proposta [p1] sem atestados pendentes.", "resolverDisputaPagamento", "p1")
+	invocar(t, stub, cc, atestar("banco-1", "2016-11-30")...)
+	invocar(t, stub, cc, atestar("banco-2", "2016-12-01")...)
+
+	verificarErro(t, stub, cc, "Oráculo [banco-3] não atestou", "resolverDisputaPagamento", "p1", "banco-3")
+	stub.caller = "outro"
+	verificarErro(t, stub, cc, "Failed checking admin identity", "resolverDisputaPagamento", "p1", "banco-2")
+	stub.caller = adminTeste
+
+	// Descarta o atestado divergente: o atestado restante continua valendo para o quorum
+	res := invocar(t, stub, cc, "resolverDisputaPagamento", "p1", "banco-2")
+	if string(res) != `{"descartados":"1","restantes":"1"}` {
+		t.Errorf("resolverDisputaPagamento = %s", res)
+	}
+	var eventos []Evento
+	json.Unmarshal(stub.eventos[stub.txID], &eventos)
+	if len(eventos) != 1 || eventos[0].Tipo != eventoDisputaPagamentoResolvida {
+		t.Errorf("eventos da resolução = %+v", eventos)
+	}
+
+	// O oráculo descartado pode enviar um novo atestado
+	res = invocar(t, stub, cc, atestar("banco-2", "2016-11-30")...)
+	if string(res) != `{"pago":"true","atestados":"2","quorum":"2"}` {
+		t.Errorf("confirmarPagamentoOracle após a resolução = %s", res)
+	}
+}
+
+func TestVersao(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	invocar(t, stub, cc, "registrarProposta", "p1", "111", "false", "false", "false")
+	invocar(t, stub, cc, "registrarProposta", "p2", "222", "false", "false", "false")
+	invocar(t, stub, cc, "confirmarEntrega", "1", "bc-desafio", statusEntregue)
+
+	var versao VersaoChaincode
+	json.Unmarshal(invocar(t, stub, cc, "versao"), &versao)
+	if versao.Versao != versaoChaincode || versao.Variante != "fabric-2.x" {
+		t.Errorf("versão = %s %s", versao.Variante, versao.Versao)
+	}
+	if !versao.Modulos.Autenticacao || !versao.Modulos.Notificacao || !versao.AdministradorRegistrado {
+		t.Errorf("módulos = %+v, administrador registrado = %t", versao.Modulos, versao.AdministradorRegistrado)
+	}
+	if versao.Propostas != 2 || versao.Entregas != 1 {
+		t.Errorf("propostas = %d, entregas = %d", versao.Propostas, versao.Entregas)
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/CaueP/BlockchainDesafio/chaincode-v2
+
+go 1.22
+
+require (
+	github.com/CaueP/BlockchainDesafio/chaincode/canonico v0.0.0
+	github.com/hyperledger/fabric-chaincode-go/v2 v2.0.0
+	github.com/hyperledger/fabric-protos-go-apiv2 v0.3.3
+	golang.org/x/crypto v0.26.0
+)
+
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.23.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
+	google.golang.org/grpc v1.66.1 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+// pacote compartilhado com o chaincode Fabric 0.6 (ver chaincode/canonico)
+replace github.com/CaueP/BlockchainDesafio/chaincode/canonico => ../chaincode/canonico
@@ -0,0 +1,360 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// Definição da Struct Evento, emitida a cada alteração de uma proposta.
+// Os campos da Proposta são exportados no mesmo nível do JSON, mantendo o formato esperado pela API externa.
+type Evento struct {
+	ID   string `json:"id_evento"`
+	Tipo string `json:"tipo_evento"`
+	TxID string `json:"tx_id"`
+	Proposta
+}
+
+// tipos de evento do ciclo de vida de uma proposta
+const (
+	eventoPropostaCriada            = "PropostaCriada"
+	eventoPropostaAtualizada        = "PropostaAtualizada"
+	eventoPropostaAceita            = "PropostaAceita"
+	eventoBoletoPago                = "BoletoPago"
+	eventoPropostaCancelada         = "PropostaCancelada"
+	eventoPagamentoEmDisputa        = "PagamentoEmDisputa"        // oráculos enviaram atestados divergentes
+	eventoDisputaPagamentoResolvida = "DisputaPagamentoResolvida" // o administrador descartou atestados pendentes
+)
+
+// consts associadas ao armazenamento dos eventos
+const (
+	nomeEventoProposta    = "eventosProposta" // nome do evento do chaincode, com a lista de eventos da transação
+	chaveSequenciaEvento  = "seqEvento"       // chave do último id_evento emitido
+	prefixoChaveEvento    = "evento_"
+	maxEventosPorConsulta = 100
+)
+
+// Definição da Struct Entrega, confirmação de entrega de um evento a um assinante
+type Entrega struct {
+	IDProposta string `json:"id_proposta"`
+	IDEvento   string `json:"id_evento"`
+	Assinante  string `json:"assinante"`
+	TipoEvento string `json:"tipo_evento"`
+	Status     string `json:"status"`
+	TxID       string `json:"tx_id"`
+}
+
+// tipo da chave composta das Entregas: Entrega~idProposta~idEvento~assinante
+// (substitui a tabela 'Entrega' da versão 0.6)
+const tipoChaveEntrega = "Entrega"
+
+// status de entrega aceitos por confirmarEntrega
+const (
+	statusEntregue  = "entregue"  // evento recebido pelo assinante
+	statusRejeitado = "rejeitado" // evento recusado definitivamente pelo assinante
+)
+
+// ============================================================================================================================
+// Módulo notificacao
+// ============================================================================================================================
+
+// verificarNotificacao: retorna erro caso o módulo notificacao não esteja habilitado
+func (t *BoletoPropostaChaincode) verificarNotificacao(stub shim.ChaincodeStubInterface) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Notificacao {
+		return errors.New("Módulo " + moduloNotificacao + " não habilitado")
+	}
+	return nil
+}
+
+// ============================================================================================================================
+// Eventos
+// ============================================================================================================================
+
+// eventosTransicao: identifica os eventos gerados pela alteração de uma proposta.
+// anterior é nil quando a proposta está sendo criada
+func eventosTransicao(anterior *Proposta, nova Proposta) []string {
+	var tipos []string
+
+	if anterior == nil {
+		tipos = append(tipos, eventoPropostaCriada)
+	} else {
+		tipos = append(tipos, eventoPropostaAtualizada)
+	}
+
+	// A proposta é aceita quando pagador e beneficiário aceitaram
+	aceitaAnterior := anterior != nil && anterior.PagadorAceitou && anterior.BeneficiarioAceitou
+	if nova.PagadorAceitou && nova.BeneficiarioAceitou && !aceitaAnterior {
+		tipos = append(tipos, eventoPropostaAceita)
+	}
+
+	if nova.BoletoPago && (anterior == nil || !anterior.BoletoPago) {
+		tipos = append(tipos, eventoBoletoPago)
+	}
+
+	return tipos
+}
+
+// emitirEventos: registra os eventos no ledger, para entrega pelo relay, e os emite como evento do chaincode.
+// Sem o módulo notificacao nenhum evento é emitido
+func (t *BoletoPropostaChaincode) emitirEventos(stub shim.ChaincodeStubInterface, tipos []string, proposta Proposta) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Notificacao {
+		return nil
+	}
+
+	ultimo, err := t.ultimaSequenciaEvento(stub)
+	if err != nil {
+		return err
+	}
+
+	eventos := make([]Evento, 0, len(tipos))
+	for _, tipo := range tipos {
+		ultimo++
+		evento := Evento{
+			ID:       strconv.FormatUint(ultimo, 10),
+			Tipo:     tipo,
+			TxID:     stub.GetTxID(),
+			Proposta: proposta,
+		}
+
+		eventoAsBytes, err := json.Marshal(evento)
+		if err != nil {
+			return fmt.Errorf("Error marshaling Evento: %s", err)
+		}
+		err = stub.PutState(chaveEvento(ultimo), eventoAsBytes)
+		if err != nil {
+			return fmt.Errorf("Falha ao registrar o Evento [%d]: [%s]", ultimo, err)
+		}
+		fmt.Println("Evento " + tipo + " emitido para a Proposta [" + proposta.ID + "]")
+
+		eventos = append(eventos, evento)
+	}
+
+	err = stub.PutState(chaveSequenciaEvento, []byte(strconv.FormatUint(ultimo, 10)))
+	if err != nil {
+		return fmt.Errorf("Falha ao registrar a sequência de eventos: [%s]", err)
+	}
+
+	payload, err := json.Marshal(eventos)
+	if err != nil {
+		return fmt.Errorf("Error marshaling Eventos: %s", err)
+	}
+	return stub.SetEvent(nomeEventoProposta, payload)
+}
+
+// ultimaSequenciaEvento: retorna o id do último evento emitido (0 caso nenhum evento tenha sido emitido)
+func (t *BoletoPropostaChaincode) ultimaSequenciaEvento(stub shim.ChaincodeStubInterface) (uint64, error) {
+	seqAsBytes, err := stub.GetState(chaveSequenciaEvento)
+	if err != nil {
+		return 0, fmt.Errorf("Falha ao obter a sequência de eventos: [%s]", err)
+	}
+	if len(seqAsBytes) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseUint(string(seqAsBytes), 10, 64)
+}
+
+// chaveEvento: chave do estado em que o evento é armazenado.
+// O id é preenchido com zeros para manter a ordenação das chaves
+func chaveEvento(seq uint64) string {
+	return fmt.Sprintf("%s%020d", prefixoChaveEvento, seq)
+}
+
+// consultarEventos: função Query utilizada pelo relay para obter os eventos emitidos, recebendo os seguintes argumentos
+// args[0]: aPartirDe. Retorna os eventos com id_evento maior que o informado ("0" para todos)
+func (t *BoletoPropostaChaincode) consultarEventos(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("consultarEventos...")
+
+	err := t.verificarNotificacao(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	aPartirDe, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return nil, errors.New("Failed decoding aPartirDe")
+	}
+
+	ultimo, err := t.ultimaSequenciaEvento(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limita a quantidade de eventos retornados por consulta
+	eventos := []json.RawMessage{}
+	for seq := aPartirDe + 1; seq <= ultimo && len(eventos) < maxEventosPorConsulta; seq++ {
+		eventoAsBytes, err := stub.GetState(chaveEvento(seq))
+		if err != nil {
+			return nil, fmt.Errorf("Erro ao obter Evento [%d]: [%s]", seq, err)
+		}
+		eventos = append(eventos, json.RawMessage(eventoAsBytes))
+	}
+
+	return json.Marshal(eventos)
+}
+
+// ============================================================================================================================
+// Entregas
+// ============================================================================================================================
+
+// confirmarEntrega: função Invoke utilizada pelo relay para registrar a entrega de um evento, recebendo os seguintes argumentos:
+// args[0]: idEvento. Identificador do evento entregue
+// args[1]: assinante. Nome do sistema que recebeu o evento
+// args[2]: status. "entregue" ou "rejeitado"
+func (t *BoletoPropostaChaincode) confirmarEntrega(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("confirmarEntrega...")
+
+	err := t.verificarNotificacao(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) != 3 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+	}
+	idEvento := args[0]
+	assinante := args[1]
+	status := args[2]
+
+	if assinante == "" {
+		return nil, errors.New("Assinante não informado")
+	}
+	if status != statusEntregue && status != statusRejeitado {
+		return nil, errors.New("Status de entrega inválido: " + status)
+	}
+
+	err = t.verificarAdmin(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// O evento precisa ter sido emitido pelo chaincode
+	seq, err := strconv.ParseUint(idEvento, 10, 64)
+	if err != nil {
+		return nil, errors.New("Failed decoding idEvento")
+	}
+	eventoAsBytes, err := stub.GetState(chaveEvento(seq))
+	if err != nil {
+		return nil, fmt.Errorf("Erro ao obter Evento [%s]: [%s]", idEvento, err)
+	}
+	if len(eventoAsBytes) == 0 {
+		return nil, fmt.Errorf("Evento [%s] não existente.", idEvento)
+	}
+	var evento Evento
+	err = json.Unmarshal(eventoAsBytes, &evento)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling Evento: %s", err)
+	}
+
+	// Uma nova confirmação do mesmo assinante substitui a anterior
+	chave, err := stub.CreateCompositeKey(tipoChaveEntrega, []string{evento.Proposta.ID, idEvento, assinante})
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao registrar a Entrega do Evento [%s]: [%s]", idEvento, err)
+	}
+	entregaAsBytes, err := json.Marshal(Entrega{
+		IDProposta: evento.Proposta.ID,
+		IDEvento:   idEvento,
+		Assinante:  assinante,
+		TipoEvento: evento.Tipo,
+		Status:     status,
+		TxID:       stub.GetTxID(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling Entrega: %s", err)
+	}
+	err = stub.PutState(chave, entregaAsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao registrar a Entrega do Evento [%s]: [%s]", idEvento, err)
+	}
+
+	fmt.Println("Entrega do Evento [" + idEvento + "] para [" + assinante + "]: " + status)
+	return nil, nil
+}
+
+// consultarEntregas: função Query para consultar as entregas dos eventos de uma proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarEntregas(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("consultarEntregas...")
+
+	err := t.verificarNotificacao(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	idProposta := args[0]
+
+	resultados, err := stub.GetStateByPartialCompositeKey(tipoChaveEntrega, []string{idProposta})
+	if err != nil {
+		return nil, fmt.Errorf("Erro ao obter Entregas da Proposta [%s]: [%s]", idProposta, err)
+	}
+	defer resultados.Close()
+
+	entregas := []Entrega{}
+	for resultados.HasNext() {
+		kv, err := resultados.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Erro ao obter Entregas da Proposta [%s]: [%s]", idProposta, err)
+		}
+		var entrega Entrega
+		err = json.Unmarshal(kv.Value, &entrega)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling Entrega: %s", err)
+		}
+		entregas = append(entregas, entrega)
+	}
+
+	// Ordena pela sequência do evento; as chaves compostas são ordenadas como texto
+	sort.Sort(entregasPorEvento(entregas))
+
+	return json.Marshal(entregas)
+}
+
+// entregasPorEvento - ordenação das entregas pelo id do evento e assinante
+type entregasPorEvento []Entrega
+
+func (e entregasPorEvento) Len() int      { return len(e) }
+func (e entregasPorEvento) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e entregasPorEvento) Less(i, j int) bool {
+	if len(e[i].IDEvento) != len(e[j].IDEvento) {
+		return len(e[i].IDEvento) < len(e[j].IDEvento)
+	}
+	if e[i].IDEvento != e[j].IDEvento {
+		return e[i].IDEvento < e[j].IDEvento
+	}
+	return e[i].Assinante < e[j].Assinante
+}
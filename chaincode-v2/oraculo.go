@@ -0,0 +1,665 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/canonico"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"golang.org/x/crypto/sha3"
+)
+
+// Definição da Struct Oraculo, banco/oráculo autorizado a atestar o pagamento dos boletos
+type Oraculo struct {
+	ID           string `json:"id_oraculo"`
+	ChavePublica string `json:"chave_publica"` // chave pública ECDSA em formato PEM
+}
+
+// Definição da Struct AtestadoPagamento, assinada pelo oráculo para confirmar o pagamento de um boleto
+type AtestadoPagamento struct {
+	IDOraculo          string `json:"id_oraculo"`
+	IDProposta         string `json:"id_proposta"`
+	ValorPago          int64  `json:"valor_pago"`          // valor pago em centavos
+	DataPagamento      string `json:"data_pagamento"`      // AAAA-MM-DD
+	CodigoAutenticacao string `json:"codigo_autenticacao"` // autenticação bancária do pagamento
+}
+
+// Definição da Struct AtestadoAssinado, atestado recebido de um oráculo com a respectiva assinatura
+type AtestadoAssinado struct {
+	Atestado   AtestadoPagamento `json:"atestado"`
+	Assinatura string            `json:"assinatura"` // assinatura ECDSA (DER) em base64
+	TxID       string            `json:"tx_id"`
+}
+
+// Definição da Struct ConfirmacaoPagamento, registro dos atestados que confirmaram o pagamento de uma proposta
+type ConfirmacaoPagamento struct {
+	Atestados []AtestadoAssinado `json:"atestados"`
+	Quorum    int                `json:"quorum"`
+	TxID      string             `json:"tx_id"`
+}
+
+// Definição da Struct ConfiguracaoOraculos, retornada por consultarOraculos
+type ConfiguracaoOraculos struct {
+	Quorum   int       `json:"quorum"` // atestados coincidentes necessários para confirmar um pagamento
+	Oraculos []Oraculo `json:"oraculos"`
+}
+
+// consts associadas ao armazenamento dos oráculos e pagamentos
+const (
+	chaveOraculos            = "oraculos"
+	chaveQuorumOraculos      = "quorumOraculos"
+	prefixoChavePagamento    = "pagamento_"
+	prefixoChaveAtestados    = "atestados_"
+	prefixoChaveAutenticacao = "autenticacao_"
+	formatoDataPagamento     = "2006-01-02"
+)
+
+// ============================================================================================================================
+// Oráculos de pagamento
+// ============================================================================================================================
+
+// registrarOraculo: função Invoke para registrar um oráculo de pagamento, recebendo os seguintes argumentos:
+// args[0]: idOraculo. Identificador do banco/oráculo
+// args[1]: chavePublica. Chave pública ECDSA do oráculo em formato PEM
+func (t *BoletoPropostaChaincode) registrarOraculo(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("registrarOraculo...")
+
+	if len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	idOraculo := args[0]
+	if idOraculo == "" {
+		return nil, errors.New("Oráculo não informado")
+	}
+	if _, err := chavePublicaOraculo(args[1]); err != nil {
+		return nil, err
+	}
+
+	err := t.verificarAdmin(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range oraculos {
+		if o.ID == idOraculo {
+			return nil, fmt.Errorf("Oráculo [%s] já registrado.", idOraculo)
+		}
+	}
+	oraculos = append(oraculos, Oraculo{ID: idOraculo, ChavePublica: args[1]})
+
+	err = t.gravarOraculos(stub, oraculos)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Oráculo [" + idOraculo + "] registrado")
+	return nil, nil
+}
+
+// removerOraculo: função Invoke para remover um oráculo de pagamento, recebendo os seguintes argumentos:
+// args[0]: idOraculo. Identificador do banco/oráculo
+func (t *BoletoPropostaChaincode) removerOraculo(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("removerOraculo...")
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	idOraculo := args[0]
+
+	err := t.verificarAdmin(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	restantes := []Oraculo{}
+	for _, o := range oraculos {
+		if o.ID != idOraculo {
+			restantes = append(restantes, o)
+		}
+	}
+	if len(restantes) == len(oraculos) {
+		return nil, fmt.Errorf("Oráculo [%s] não existente.", idOraculo)
+	}
+
+	// O quorum não pode ficar maior que a quantidade de oráculos. A remoção do último oráculo
+	// reinicia o quorum configurado, que volta ao padrão (1) no registro do próximo oráculo
+	quorum, err := t.obterQuorumOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	if len(restantes) == 0 {
+		err = stub.DelState(chaveQuorumOraculos)
+		if err != nil {
+			return nil, fmt.Errorf("Falha ao gravar o quorum de oráculos: [%s]", err)
+		}
+	} else if quorum > len(restantes) {
+		return nil, fmt.Errorf("Quorum [%d] maior que a quantidade de oráculos restantes [%d].", quorum, len(restantes))
+	}
+
+	err = t.gravarOraculos(stub, restantes)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Oráculo [" + idOraculo + "] removido")
+	return nil, nil
+}
+
+// configurarQuorumOraculos: função Invoke para definir quantos oráculos distintos precisam atestar um pagamento, recebendo os seguintes argumentos:
+// args[0]: quorum. Quantidade de atestados coincidentes (M) dentre os oráculos registrados (N)
+func (t *BoletoPropostaChaincode) configurarQuorumOraculos(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("configurarQuorumOraculos...")
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	quorum, err := strconv.Atoi(args[0])
+	if err != nil || quorum < 1 {
+		return nil, errors.New("Failed decoding quorum")
+	}
+
+	err = t.verificarAdmin(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	if quorum > len(oraculos) {
+		return nil, fmt.Errorf("Quorum [%d] maior que a quantidade de oráculos registrados [%d].", quorum, len(oraculos))
+	}
+
+	err = stub.PutState(chaveQuorumOraculos, []byte(strconv.Itoa(quorum)))
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao gravar o quorum de oráculos: [%s]", err)
+	}
+	fmt.Printf("Quorum de oráculos: %d de %d\n", quorum, len(oraculos))
+	return nil, nil
+}
+
+// confirmarPagamentoOracle: função Invoke para registrar o atestado de pagamento de um oráculo, recebendo os seguintes argumentos:
+// args[0]: atestado. JSON do AtestadoPagamento
+// args[1]: assinatura. Assinatura ECDSA (DER, em base64) do JSON canônico do atestado (ver canonico),
+// gerada com a chave do oráculo
+// O boleto é marcado como pago quando o quorum de oráculos distintos envia atestados coincidentes.
+// Atestados divergentes para a mesma proposta emitem o evento PagamentoEmDisputa.
+func (t *BoletoPropostaChaincode) confirmarPagamentoOracle(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("confirmarPagamentoOracle...")
+
+	if len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	assinatura, err := base64.StdEncoding.DecodeString(args[1])
+	if err != nil {
+		return nil, errors.New("Failed decoding assinatura")
+	}
+	atestado, err := decodificarAtestado([]byte(args[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	// A assinatura deve ter sido gerada por um oráculo registrado
+	oraculo, err := t.obterOraculo(stub, atestado.IDOraculo)
+	if err != nil {
+		return nil, err
+	}
+	chave, err := chavePublicaOraculo(oraculo.ChavePublica)
+	if err != nil {
+		return nil, err
+	}
+	conteudo, err := canonico.CodificarJSON([]byte(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Failed encoding atestado: %s", err)
+	}
+	if !verificarAssinatura(chave, conteudo, assinatura) {
+		return nil, errors.New("Assinatura do atestado inválida")
+	}
+
+	// Uma autenticação bancária já utilizada não pode confirmar outro pagamento
+	chaveAutenticacao := prefixoChaveAutenticacao + atestado.CodigoAutenticacao
+	utilizado, err := stub.GetState(chaveAutenticacao)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao verificar o atestado: [%s]", err)
+	}
+	if len(utilizado) != 0 {
+		return nil, fmt.Errorf("Atestado [%s] já utilizado pela Proposta [%s].", atestado.CodigoAutenticacao, string(utilizado))
+	}
+
+	anterior, err := t.obterProposta(stub, atestado.IDProposta)
+	if err != nil {
+		return nil, err
+	}
+	if anterior == nil {
+		return nil, fmt.Errorf("Proposta [%s] não existente.", atestado.IDProposta)
+	}
+	if anterior.BoletoPago {
+		return nil, fmt.Errorf("Proposta [%s] já paga.", atestado.IDProposta)
+	}
+	if atestado.ValorPago != anterior.Valor {
+		return nil, fmt.Errorf("Valor pago [%d] diferente do valor da Proposta [%d].", atestado.ValorPago, anterior.Valor)
+	}
+
+	// Cada oráculo atesta o pagamento de uma proposta apenas uma vez
+	pendentes, err := t.obterAtestadosPendentes(stub, atestado.IDProposta)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pendentes {
+		if p.Atestado.IDOraculo == atestado.IDOraculo {
+			return nil, fmt.Errorf("Oráculo [%s] já atestou o pagamento da Proposta [%s].", atestado.IDOraculo, atestado.IDProposta)
+		}
+	}
+	pendentes = append(pendentes, AtestadoAssinado{
+		Atestado:   atestado,
+		Assinatura: args[1],
+		TxID:       stub.GetTxID(),
+	})
+
+	// Separa os atestados que coincidem com o recebido e identifica divergências.
+	// Atestados de oráculos removidos depois do envio não são considerados no quorum
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	registrados := map[string]bool{}
+	for _, o := range oraculos {
+		registrados[o.ID] = true
+	}
+	var tipos []string
+	var coincidentes []AtestadoAssinado
+	for _, p := range pendentes {
+		if mesmoPagamento(p.Atestado, atestado) && registrados[p.Atestado.IDOraculo] {
+			coincidentes = append(coincidentes, p)
+		}
+	}
+	if !todosCoincidentes(pendentes, atestado) {
+		fmt.Println("Atestados divergentes para a Proposta [" + atestado.IDProposta + "]")
+		tipos = append(tipos, eventoPagamentoEmDisputa)
+	}
+
+	quorum, err := t.obterQuorumOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quorum não atingido: mantém o atestado pendente
+	if len(coincidentes) < quorum {
+		err = t.gravarAtestadosPendentes(stub, atestado.IDProposta, pendentes)
+		if err != nil {
+			return nil, err
+		}
+		if len(tipos) > 0 {
+			err = t.emitirEventos(stub, tipos, *anterior)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fmt.Printf("Atestado registrado: %d de %d para a Proposta [%s]\n", len(coincidentes), quorum, atestado.IDProposta)
+		return []byte(fmt.Sprintf("{\"pago\":\"false\",\"atestados\":\"%d\",\"quorum\":\"%d\"}", len(coincidentes), quorum)), nil
+	}
+
+	// Quorum atingido: marca o boleto como pago
+	nova := *anterior
+	nova.BoletoPago = true
+	err = t.atualizarProposta(stub, nova)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registra a autenticação utilizada e os atestados que confirmaram o pagamento
+	err = stub.PutState(chaveAutenticacao, []byte(atestado.IDProposta))
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao registrar o atestado: [%s]", err)
+	}
+	confirmacaoAsBytes, err := json.Marshal(ConfirmacaoPagamento{
+		Atestados: coincidentes,
+		Quorum:    quorum,
+		TxID:      stub.GetTxID(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling ConfirmacaoPagamento: %s", err)
+	}
+	err = stub.PutState(prefixoChavePagamento+atestado.IDProposta, confirmacaoAsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao registrar o pagamento: [%s]", err)
+	}
+	err = stub.DelState(prefixoChaveAtestados + atestado.IDProposta)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao remover os atestados pendentes: [%s]", err)
+	}
+
+	tipos = append(tipos, eventosTransicao(anterior, nova)...)
+	err = t.emitirEventos(stub, tipos, nova)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Pagamento da Proposta [" + atestado.IDProposta + "] confirmado pelos oráculos")
+	return []byte(fmt.Sprintf("{\"pago\":\"true\",\"atestados\":\"%d\",\"quorum\":\"%d\"}", len(coincidentes), quorum)), nil
+}
+
+// resolverDisputaPagamento: função Invoke para descartar atestados pendentes de uma proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Identificador da proposta
+// args[1..n]: idOraculo (opcional). Oráculos cujos atestados são descartados. Sem oráculos, todos os atestados
+// pendentes da proposta são descartados
+// Permite ao administrador resolver um PagamentoEmDisputa: os atestados restantes continuam valendo para o quorum,
+// e os oráculos descartados podem enviar um novo atestado. Emite o evento DisputaPagamentoResolvida.
+func (t *BoletoPropostaChaincode) resolverDisputaPagamento(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("resolverDisputaPagamento...")
+
+	if len(args) < 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting at least 1")
+	}
+	idProposta := args[0]
+	descartar := map[string]bool{}
+	for _, idOraculo := range args[1:] {
+		descartar[idOraculo] = true
+	}
+
+	err := t.verificarAdmin(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	proposta, err := t.obterProposta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+	if proposta == nil {
+		return nil, fmt.Errorf("Proposta [%s] não existente.", idProposta)
+	}
+	pendentes, err := t.obterAtestadosPendentes(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+	if len(pendentes) == 0 {
+		return nil, fmt.Errorf("Proposta [%s] sem atestados pendentes.", idProposta)
+	}
+
+	restantes := []AtestadoAssinado{}
+	atestaram := map[string]bool{}
+	for _, p := range pendentes {
+		atestaram[p.Atestado.IDOraculo] = true
+		if len(descartar) > 0 && !descartar[p.Atestado.IDOraculo] {
+			restantes = append(restantes, p)
+		}
+	}
+	for _, idOraculo := range args[1:] {
+		if !atestaram[idOraculo] {
+			return nil, fmt.Errorf("Oráculo [%s] não atestou o pagamento da Proposta [%s].", idOraculo, idProposta)
+		}
+	}
+
+	if len(restantes) == 0 {
+		err = stub.DelState(prefixoChaveAtestados + idProposta)
+		if err != nil {
+			return nil, fmt.Errorf("Falha ao remover os atestados pendentes: [%s]", err)
+		}
+	} else {
+		err = t.gravarAtestadosPendentes(stub, idProposta, restantes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = t.emitirEventos(stub, []string{eventoDisputaPagamentoResolvida}, *proposta)
+	if err != nil {
+		return nil, err
+	}
+
+	descartados := len(pendentes) - len(restantes)
+	fmt.Printf("Atestados pendentes descartados: %d, restantes: %d para a Proposta [%s]\n", descartados, len(restantes), idProposta)
+	return []byte(fmt.Sprintf("{\"descartados\":\"%d\",\"restantes\":\"%d\"}", descartados, len(restantes))), nil
+}
+
+// consultarOraculos: função Query para consultar os oráculos de pagamento registrados
+func (t *BoletoPropostaChaincode) consultarOraculos(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("consultarOraculos...")
+
+	if len(args) != 0 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 0")
+	}
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	quorum, err := t.obterQuorumOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ConfiguracaoOraculos{Quorum: quorum, Oraculos: oraculos})
+}
+
+// consultarPagamento: função Query para consultar os atestados que confirmaram o pagamento de uma proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarPagamento(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("consultarPagamento...")
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	idProposta := args[0]
+
+	confirmacaoAsBytes, err := stub.GetState(prefixoChavePagamento + idProposta)
+	if err != nil {
+		return nil, fmt.Errorf("Erro ao obter o pagamento da Proposta [%s]: [%s]", idProposta, err)
+	}
+	if len(confirmacaoAsBytes) == 0 {
+		return nil, fmt.Errorf("Pagamento da Proposta [%s] não confirmado.", idProposta)
+	}
+	return confirmacaoAsBytes, nil
+}
+
+// consultarAtestadosPendentes: função Query para consultar os atestados de uma proposta que ainda não atingiram o quorum, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarAtestadosPendentes(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("consultarAtestadosPendentes...")
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	pendentes, err := t.obterAtestadosPendentes(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(pendentes)
+}
+
+// mesmoPagamento: verifica se dois atestados descrevem o mesmo pagamento
+func mesmoPagamento(a, b AtestadoPagamento) bool {
+	return a.IDProposta == b.IDProposta &&
+		a.ValorPago == b.ValorPago &&
+		a.DataPagamento == b.DataPagamento &&
+		a.CodigoAutenticacao == b.CodigoAutenticacao
+}
+
+// todosCoincidentes: verifica se todos os atestados descrevem o mesmo pagamento que o atestado informado
+func todosCoincidentes(atestados []AtestadoAssinado, atestado AtestadoPagamento) bool {
+	for _, a := range atestados {
+		if !mesmoPagamento(a.Atestado, atestado) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodificarAtestado: converte e valida o JSON do atestado de pagamento. Campos desconhecidos são
+// rejeitados, e o atestado decodificado deve ter o mesmo JSON canônico que o documento assinado, para que
+// os campos aceitos pelo decoder do Go (ex.: chaves com outra capitalização) não divirjam da assinatura
+func decodificarAtestado(atestadoAsBytes []byte) (AtestadoPagamento, error) {
+	var atestado AtestadoPagamento
+
+	decoder := json.NewDecoder(bytes.NewReader(atestadoAsBytes))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&atestado)
+	if err != nil {
+		return atestado, errors.New("Failed decoding atestado")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return atestado, errors.New("Failed decoding atestado")
+	}
+	assinado, err := canonico.CodificarJSON(atestadoAsBytes)
+	if err != nil {
+		return atestado, fmt.Errorf("Failed encoding atestado: %s", err)
+	}
+	decodificado, err := canonico.Codificar(atestado)
+	if err != nil || !bytes.Equal(assinado, decodificado) {
+		return atestado, errors.New("Campos do atestado não correspondem ao JSON assinado")
+	}
+	if atestado.IDOraculo == "" || atestado.IDProposta == "" || atestado.CodigoAutenticacao == "" {
+		return atestado, errors.New("Atestado incompleto: id_oraculo, id_proposta e codigo_autenticacao são obrigatórios")
+	}
+	if atestado.ValorPago <= 0 {
+		return atestado, errors.New("Valor pago inválido no atestado")
+	}
+	if _, err := time.Parse(formatoDataPagamento, atestado.DataPagamento); err != nil {
+		return atestado, errors.New("Data de pagamento inválida no atestado: " + atestado.DataPagamento)
+	}
+	return atestado, nil
+}
+
+// chavePublicaOraculo: converte a chave pública PEM do oráculo, aceitando apenas chaves ECDSA
+func chavePublicaOraculo(chavePEM string) (*ecdsa.PublicKey, error) {
+	bloco, _ := pem.Decode([]byte(chavePEM))
+	if bloco == nil {
+		return nil, errors.New("Chave pública do oráculo inválida")
+	}
+	chave, err := x509.ParsePKIXPublicKey(bloco.Bytes)
+	if err != nil {
+		return nil, errors.New("Chave pública do oráculo inválida")
+	}
+	chaveECDSA, ok := chave.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("Chave pública do oráculo não é ECDSA")
+	}
+	return chaveECDSA, nil
+}
+
+// verificarAssinatura: verifica a assinatura ECDSA (DER) do hash SHA3-256 da mensagem,
+// o mesmo formato aceito pela versão 0.6 com primitives.ECDSAVerify
+func verificarAssinatura(chave *ecdsa.PublicKey, mensagem, assinatura []byte) bool {
+	hash := sha3.Sum256(mensagem)
+	return ecdsa.VerifyASN1(chave, hash[:], assinatura)
+}
+
+// obterOraculo: busca um oráculo registrado pelo id
+func (t *BoletoPropostaChaincode) obterOraculo(stub shim.ChaincodeStubInterface, idOraculo string) (*Oraculo, error) {
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range oraculos {
+		if o.ID == idOraculo {
+			return &o, nil
+		}
+	}
+	return nil, fmt.Errorf("Oráculo [%s] não registrado.", idOraculo)
+}
+
+// obterOraculos: retorna a lista de oráculos registrados
+func (t *BoletoPropostaChaincode) obterOraculos(stub shim.ChaincodeStubInterface) ([]Oraculo, error) {
+	oraculos := []Oraculo{}
+
+	oraculosAsBytes, err := stub.GetState(chaveOraculos)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao obter os oráculos: [%s]", err)
+	}
+	if len(oraculosAsBytes) == 0 {
+		return oraculos, nil
+	}
+	err = json.Unmarshal(oraculosAsBytes, &oraculos)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling oráculos: %s", err)
+	}
+	return oraculos, nil
+}
+
+// gravarOraculos: grava a lista de oráculos registrados
+func (t *BoletoPropostaChaincode) gravarOraculos(stub shim.ChaincodeStubInterface, oraculos []Oraculo) error {
+	oraculosAsBytes, err := json.Marshal(oraculos)
+	if err != nil {
+		return fmt.Errorf("Error marshaling oráculos: %s", err)
+	}
+	err = stub.PutState(chaveOraculos, oraculosAsBytes)
+	if err != nil {
+		return fmt.Errorf("Falha ao gravar os oráculos: [%s]", err)
+	}
+	return nil
+}
+
+// obterQuorumOraculos: retorna o quorum configurado (1 caso não configurado)
+func (t *BoletoPropostaChaincode) obterQuorumOraculos(stub shim.ChaincodeStubInterface) (int, error) {
+	quorumAsBytes, err := stub.GetState(chaveQuorumOraculos)
+	if err != nil {
+		return 0, fmt.Errorf("Falha ao obter o quorum de oráculos: [%s]", err)
+	}
+	if len(quorumAsBytes) == 0 {
+		return 1, nil
+	}
+	return strconv.Atoi(string(quorumAsBytes))
+}
+
+// obterAtestadosPendentes: retorna os atestados de uma proposta que ainda não atingiram o quorum
+func (t *BoletoPropostaChaincode) obterAtestadosPendentes(stub shim.ChaincodeStubInterface, idProposta string) ([]AtestadoAssinado, error) {
+	pendentes := []AtestadoAssinado{}
+
+	pendentesAsBytes, err := stub.GetState(prefixoChaveAtestados + idProposta)
+	if err != nil {
+		return nil, fmt.Errorf("Falha ao obter os atestados da Proposta [%s]: [%s]", idProposta, err)
+	}
+	if len(pendentesAsBytes) == 0 {
+		return pendentes, nil
+	}
+	err = json.Unmarshal(pendentesAsBytes, &pendentes)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling atestados: %s", err)
+	}
+	return pendentes, nil
+}
+
+// gravarAtestadosPendentes: grava os atestados de uma proposta que ainda não atingiram o quorum
+func (t *BoletoPropostaChaincode) gravarAtestadosPendentes(stub shim.ChaincodeStubInterface, idProposta string, pendentes []AtestadoAssinado) error {
+	pendentesAsBytes, err := json.Marshal(pendentes)
+	if err != nil {
+		return fmt.Errorf("Error marshaling atestados: %s", err)
+	}
+	err = stub.PutState(prefixoChaveAtestados+idProposta, pendentesAsBytes)
+	if err != nil {
+		return fmt.Errorf("Falha ao gravar os atestados da Proposta [%s]: [%s]", idProposta, err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// Definição da Struct Proposta e parametros para exportação para JSON
+type Proposta struct {
+	ID                  string `json:"id_proposta"`
+	CpfPagador          string `json:"cpf_pagador"`
+	PagadorAceitou      bool   `json:"pagador_aceitou"`
+	BeneficiarioAceitou bool   `json:"beneficiario_aceitou"`
+	BoletoPago          bool   `json:"boleto_pago"`
+	Valor               int64  `json:"valor"` // valor do boleto em centavos
+}
+
+// tipo da chave composta das Propostas (substitui a tabela 'Proposta' da versão 0.6)
+const tipoChaveProposta = "Proposta"
+
+// ============================================================================================================================
+// Propostas
+// ============================================================================================================================
+
+// registrarProposta: função Invoke para registrar uma nova proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash que identificará a proposta
+// args[1]: cpfPagador. CPF do Pagador
+// args[2]: pagadorAceitou. Status de aceite do Pagador da proposta
+// args[3]: beneficiarioAceitou. Status de aceite do Beneficiario da proposta
+// args[4]: boletoPago. Status do Pagamento do Boleto
+// args[5]: valor (opcional). Valor do boleto em centavos, conferido na confirmação de pagamento pelos oráculos
+// Cada alteração emite os eventos do ciclo de vida da proposta (ver eventosTransicao)
+func (t *BoletoPropostaChaincode) registrarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	//myLogger.Debug("registrarProposta...")
+	fmt.Println("registrarProposta...")
+
+	var jsonResp string
+
+	// Verifica se a quantidade de argumentos recebidas corresponde a esperada
+	if len(args) != 5 && len(args) != 6 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 5 or 6")
+	}
+
+	// Obtem os valores da array de arguments (args) e
+	// os converte no tipo necessário para salvar a 'Proposta'
+	idProposta := args[0]
+	cpfPagador := args[1]
+	pagadorAceitou, err := strconv.ParseBool(args[2])
+	if err != nil {
+		return nil, errors.New("Failed decodinf pagadorAceitou")
+	}
+	beneficiarioAceitou, err := strconv.ParseBool(args[3])
+	if err != nil {
+		return nil, errors.New("Failed decodinf beneficiarioAceitou")
+	}
+	boletoPago, err := strconv.ParseBool(args[4])
+	if err != nil {
+		return nil, errors.New("Failed decodinf boletoPago")
+	}
+	var valor int64
+	if len(args) == 6 {
+		valor, err = strconv.ParseInt(args[5], 10, 64)
+		if err != nil || valor < 0 {
+			return nil, errors.New("Failed decoding valor")
+		}
+	}
+
+	// Verify the identity of the caller
+	// With the autenticacao module, only an administrator can invoker assign
+	err = t.verificarAdmin(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consulta o estado anterior da proposta, utilizado para identificar os eventos da transição
+	anterior, err := t.obterProposta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Com oráculos registrados, o pagamento só pode ser confirmado por confirmarPagamentoOracle
+	if boletoPago && (anterior == nil || !anterior.BoletoPago) {
+		oraculos, err := t.obterOraculos(stub)
+		if err != nil {
+			return nil, err
+		}
+		if len(oraculos) > 0 {
+			return nil, errors.New("O pagamento do boleto deve ser confirmado por um oráculo")
+		}
+	}
+
+	// Registra a proposta no estado
+	fmt.Println("Criando Proposta Id [" + idProposta + "] para CPF nº [" + cpfPagador + "]")
+	fmt.Print("pagadorAceitou: " + strconv.FormatBool(pagadorAceitou))
+	fmt.Print(" | beneficiarioAceitou: " + strconv.FormatBool(beneficiarioAceitou))
+	fmt.Print(" | boletoPago: " + strconv.FormatBool(boletoPago) + "\n")
+
+	nova := Proposta{
+		ID:                  idProposta,
+		CpfPagador:          cpfPagador,
+		PagadorAceitou:      pagadorAceitou,
+		BeneficiarioAceitou: beneficiarioAceitou,
+		BoletoPago:          boletoPago,
+		Valor:               valor,
+	}
+
+	if anterior == nil {
+		err = t.gravarProposta(stub, nova)
+		if err != nil {
+			return nil, fmt.Errorf("Falha ao registrar a Proposta nº %s: %v", idProposta, err)
+		}
+	} else {
+		// Trecho para atualizar uma proposta existente
+		err = t.atualizarProposta(stub, nova)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Notifica os sistemas externos através dos eventos da proposta.
+	// A entrega para a API externa é feita fora do chaincode, pelo relay.
+	err = t.emitirEventos(stub, eventosTransicao(anterior, nova), nova)
+	if err != nil {
+		return nil, err
+	}
+
+	if anterior != nil {
+		fmt.Println("Proposta atualizada!")
+		jsonResp = "{\"atualizado\":\"" + "true" + "\"}"
+		return []byte(jsonResp), nil
+	}
+
+	//myLogger.Debug("Proposta criada!")
+	fmt.Println("Proposta criada!")
+
+	jsonResp = "{\"registrado\":\"" + "true" + "\"}"
+	return []byte(jsonResp), nil
+}
+
+// atualizarProposta: substitui o registro da proposta no estado
+func (t *BoletoPropostaChaincode) atualizarProposta(stub shim.ChaincodeStubInterface, proposta Proposta) error {
+	err := t.gravarProposta(stub, proposta)
+	if err != nil {
+		return fmt.Errorf("Falha ao atualizar a Proposta nº %s: %v", proposta.ID, err)
+	}
+	return nil
+}
+
+// gravarProposta: grava a proposta em JSON na chave composta Proposta~id
+func (t *BoletoPropostaChaincode) gravarProposta(stub shim.ChaincodeStubInterface, proposta Proposta) error {
+	chave, err := stub.CreateCompositeKey(tipoChaveProposta, []string{proposta.ID})
+	if err != nil {
+		return err
+	}
+	propostaAsBytes, err := json.Marshal(proposta)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(chave, propostaAsBytes)
+}
+
+// consultarProposta: função Query para consultar uma proposta existente, recebendo os seguintes argumentos
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	//myLogger.Debug("consultarProposta...")
+	fmt.Println("consultarProposta...")
+	var propostaAsBytes []byte // retorno do json em bytes
+
+	// Verifica se a quantidade de argumentos recebidas corresponde a esperada
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+
+	// Obtem os valores dos argumentos
+	idProposta := args[0]
+
+	// [To do] verificar identidade
+
+	// Consultar a proposta no estado
+	resProposta, err := t.obterProposta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tratamento para o caso de não encontrar nenhuma proposta correspondente
+	if resProposta == nil {
+		return nil, fmt.Errorf("Proposta [%s] não existente.", string(idProposta)) // retorno do erro para o json
+	}
+
+	fmt.Printf("Proposta: [%s], [%s], [%t], [%t], [%t]\n", resProposta.ID, resProposta.CpfPagador, resProposta.PagadorAceitou, resProposta.BeneficiarioAceitou, resProposta.BoletoPago)
+
+	// Converter o objeto da Proposta para Bytes, para retorná-lo em formato JSON
+	propostaAsBytes, err = json.Marshal(resProposta)
+	if err != nil {
+		return nil, fmt.Errorf("Query operation failed. Error marshaling JSON: %s", err)
+	}
+	// retorna o objeto em bytes
+	return propostaAsBytes, nil
+}
+
+// obterProposta: busca a proposta no estado. Retorna nil caso a proposta não exista
+func (t *BoletoPropostaChaincode) obterProposta(stub shim.ChaincodeStubInterface, idProposta string) (*Proposta, error) {
+	chave, err := stub.CreateCompositeKey(tipoChaveProposta, []string{idProposta})
+	if err != nil {
+		return nil, fmt.Errorf("Erro ao obter Proposta [%s]: [%s]", idProposta, err)
+	}
+
+	propostaAsBytes, err := stub.GetState(chave)
+	if err != nil {
+		fmt.Printf("Erro ao obter Proposta [%s]: [%s]\n", idProposta, err)
+		return nil, fmt.Errorf("Erro ao obter Proposta [%s]: [%s]", idProposta, err)
+	}
+	if len(propostaAsBytes) == 0 {
+		return nil, nil
+	}
+
+	var proposta Proposta
+	err = json.Unmarshal(propostaAsBytes, &proposta)
+	if err != nil {
+		return nil, fmt.Errorf("Erro ao decodificar Proposta [%s]: [%s]", idProposta, err)
+	}
+	return &proposta, nil
+}
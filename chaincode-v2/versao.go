@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// versaoChaincode: versão do build do chaincode, atualizada a cada publicação. Nos builds locais
+// pode ser substituída com -ldflags "-X main.versaoChaincode=<versão>"
+var versaoChaincode = "1.0.0"
+
+// varianteChaincode: plataforma do chaincode. O chaincode do diretório chaincode informa "fabric-0.6"
+const varianteChaincode = "fabric-2.x"
+
+// Definição da Struct VersaoChaincode, retornada por versao
+type VersaoChaincode struct {
+	Versao                  string  `json:"versao"`
+	Variante                string  `json:"variante"`
+	Modulos                 Modulos `json:"modulos"`
+	AdministradorRegistrado bool    `json:"administrador_registrado"` // o Init registrou o administrador (módulo autenticacao)
+	Propostas               int     `json:"propostas"`                // quantidade de propostas
+	Entregas                int     `json:"entregas"`                 // quantidade de entregas registradas (módulo notificacao)
+}
+
+// versao: função Query para verificar a versão e o estado do chaincode implantado no peer.
+// Não recebe argumentos. Ao contrário da versão 0.6, não há versão do esquema nem tabelas: os registros
+// são JSON em chaves compostas
+func (t *BoletoPropostaChaincode) versao(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	fmt.Println("versao...")
+
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return nil, err
+	}
+	resultado := VersaoChaincode{
+		Versao:   versaoChaincode,
+		Variante: varianteChaincode,
+		Modulos:  modulos,
+	}
+
+	admin, err := stub.GetState("admin")
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching admin identity: [%s]", err)
+	}
+	resultado.AdministradorRegistrado = len(admin) > 0
+
+	resultado.Propostas, err = contarRegistros(stub, tipoChaveProposta)
+	if err != nil {
+		return nil, err
+	}
+	resultado.Entregas, err = contarRegistros(stub, tipoChaveEntrega)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resultado)
+}
+
+// contarRegistros: quantidade de registros com o tipo de chave composta informado
+func contarRegistros(stub shim.ChaincodeStubInterface, tipo string) (int, error) {
+	registros, err := stub.GetStateByPartialCompositeKey(tipo, []string{})
+	if err != nil {
+		return 0, fmt.Errorf("Falha ao contar os registros %s: [%s]", tipo, err)
+	}
+	defer registros.Close()
+
+	quantidade := 0
+	for registros.HasNext() {
+		_, err := registros.Next()
+		if err != nil {
+			return 0, fmt.Errorf("Falha ao contar os registros %s: [%s]", tipo, err)
+		}
+		quantidade++
+	}
+	return quantidade, nil
+}
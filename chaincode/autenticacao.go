@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"reflect"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Módulo autenticacao
+// ============================================================================================================================
+
+// atributos do certificado do caller que identificam as partes da proposta (ver verificarParte)
+const (
+	atributoCPF   = "cpf"
+	atributoPapel = "papel"
+)
+
+// verificarAdmin: verifica se o caller da chamada é o administrador registrado no Init.
+// Sem o módulo autenticacao qualquer caller é aceito
+func (t *BoletoPropostaChaincode) verificarAdmin(stub shim.ChaincodeStubInterface) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Autenticacao {
+		return nil
+	}
+
+	adminCertificate, err := stub.GetState("admin")
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaObterAdmin)
+	}
+
+	ok, err := t.isCaller(stub, adminCertificate)
+	if err != nil {
+		return novoErro(codigoAcessoNegado, msgFalhaVerificarAdmin)
+	}
+	if !ok {
+		return novoErro(codigoAcessoNegado, msgCallerNaoAdmin)
+	}
+	return nil
+}
+
+// isCaller: função utilizada para verificar quem é o caller da chamada
+func (t *BoletoPropostaChaincode) isCaller(stub shim.ChaincodeStubInterface, certificate []byte) (bool, error) {
+	log := logChamada(stub)
+	log.Debug("Check caller...")
+
+	// In order to enforce access control, we require that the
+	// metadata contains the signature under the signing key corresponding
+	// to the verification key inside certificate of
+	// the payload of the transaction (namely, function name and args) and
+	// the transaction binding (to avoid copying attacks)
+
+	// Verify \sigma=Sign(certificate.sk, tx.Payload||tx.Binding) against certificate.vk
+	// \sigma is in the metadata
+
+	sigma, err := stub.GetCallerMetadata()
+	if err != nil {
+		return false, novoErro(codigoErroInterno, msgFalhaObterMetadata)
+	} /*
+		payload, err := stub.GetPayload()
+		if err != nil {
+			return false, errors.New("Failed getting payload")
+		}
+		binding, err := stub.GetBinding()
+		if err != nil {
+			return false, errors.New("Failed getting binding")
+		}*/
+
+	log.Debug("Verificando o caller", "certificado", certificate, "sigma", sigma)
+
+	// valida se os slices são iguais
+	if !reflect.DeepEqual(certificate, sigma) {
+		log.Aviso("Invalid signature")
+		return false, novoErro(codigoAcessoNegado, msgCertificadoInvalido)
+	}
+
+	/*
+		ok, err := stub.VerifySignature(
+			certificate,
+			sigma,
+			append(payload, binding...),
+		)
+		if err != nil {
+			log.Erro("Failed checking signature", "erro", err)
+			return ok, err
+		}
+		if !ok {
+			log.Aviso("Invalid signature")
+		}*/
+
+	log.Debug("Check caller...Verified!")
+	// Certificado válido
+	return true, nil
+	//return ok, err
+}
+
+// verificarParte: verifica, pelos atributos do certificado do caller, se ele é a parte informada da proposta.
+// O pagador tem o atributo "cpf" igual ao CPF do pagador da proposta; o beneficiário tem o atributo
+// "papel" igual a "beneficiario", pois a proposta não registra a identidade do beneficiário.
+// Sem o módulo autenticacao qualquer caller é aceito
+func (t *BoletoPropostaChaincode) verificarParte(stub shim.ChaincodeStubInterface, proposta Proposta, parte string) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Autenticacao {
+		return nil
+	}
+
+	atributo, esperado := atributoPapel, parteBeneficiario
+	if parte == partePagador {
+		atributo, esperado = atributoCPF, proposta.CpfPagador
+	}
+	valor, err := stub.ReadCertAttribute(atributo)
+	if err != nil || string(valor) != esperado {
+		logChamada(stub).Aviso("Caller não é parte da proposta", "id_proposta", proposta.ID, "parte", parte)
+		return novoErro(codigoAcessoNegado, msgCallerNaoParte, parte, proposta.ID)
+	}
+	return nil
+}
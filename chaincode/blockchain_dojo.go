@@ -0,0 +1,316 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Implementação iniciada por Caue Garcia Polimanti e Vitor Diego dos Santos de Sousa
+
+Chaincode de propostas de boleto. Os comportamentos opcionais são módulos
+habilitados pelos argumentos do Init:
+	autenticacao: apenas o administrador (caller do Init) pode alterar propostas e oráculos
+	notificacao:  as alterações das propostas emitem eventos para entrega pelo relay
+A forma de armazenamento das propostas é escolhida com o argumento "armazenamento=tabela|json",
+o nível de log com o argumento "log=debug|info|aviso|erro" (ver Log) e os parâmetros do
+chaincode com o argumento "configuracao=<JSON>" (ver Configuracao).
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// BoletoPropostaChaincode - implementacao do chaincode
+type BoletoPropostaChaincode struct {
+}
+
+// Definição da Struct Modulos, módulos opcionais habilitados no Init
+type Modulos struct {
+	Autenticacao  bool   `json:"autenticacao"`
+	Notificacao   bool   `json:"notificacao"`
+	Armazenamento string `json:"armazenamento"`       // forma de armazenamento das propostas (ver RepositorioProposta)
+	NivelLog      string `json:"nivel_log,omitempty"` // nível mínimo das linhas de log (ver nomesNiveisLog)
+}
+
+// nomes dos módulos aceitos como argumento do Init
+const (
+	moduloAutenticacao = "autenticacao"
+	moduloNotificacao  = "notificacao"
+	chaveModulos       = "modulos" // chave do estado com os módulos habilitados
+	argArmazenamento   = "armazenamento="
+)
+
+// ============================================================================================================================
+// Main
+// ============================================================================================================================
+func main() {
+	// O nível de segurança é substituído pelo da configuração antes de cada hash (ver carregarNivelSeguranca)
+	err := aplicarNivelSeguranca(configuracaoPadrao())
+	if err != nil {
+		fmt.Printf("Error setting the security level: %s", err)
+	}
+	err = shim.Start(new(BoletoPropostaChaincode))
+	if err != nil {
+		fmt.Printf("Error starting BoletoPropostaChaincode chaincode: %s", err)
+	}
+}
+
+// ============================================================================================================================
+// Init
+// 		Inicia/Reinicia a tabela de propostas
+// ============================================================================================================================
+
+// Init recebe como argumentos os nomes dos módulos a habilitar ("autenticacao", "notificacao")
+// e, opcionalmente, a forma de armazenamento ("armazenamento=json"), o nível de log ("log=debug")
+// e os campos da configuração a alterar ("configuracao={...}", ver iniciarConfiguracao).
+// Sem argumentos, nenhum módulo é habilitado e as propostas são gravadas na tabela 'Proposta'.
+// Por padrão as propostas existentes são excluídas. Com "migrar" como primeiro argumento (atualização
+// do chaincode), elas são mantidas e migradas para a versão atual do esquema e para a forma de
+// armazenamento informada (ver migracoes).
+// Como as demais funções, retorna o envelope Resposta.
+func (t *BoletoPropostaChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	idioma, args, err := lerIdioma(stub, args)
+	if err != nil {
+		return responder(idioma, nil, err)
+	}
+	dados, err := t.iniciar(stub, args)
+	if err != nil {
+		registrarErro(logChamada(stub), err)
+	}
+	return responder(idioma, dados, err)
+}
+
+// iniciar: inicializa o estado do chaincode com os módulos informados (ver Init)
+func (t *BoletoPropostaChaincode) iniciar(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	log := logChamada(stub)
+	log.Info("Init Chaincode...")
+
+	// Com "migrar" como primeiro argumento, os dados existentes são mantidos e migrados
+	migrar := len(args) > 0 && args[0] == argMigrar
+	if migrar {
+		args = args[1:]
+	}
+	configuracao, args := lerArgConfiguracao(args)
+	modulos, err := lerModulos(args)
+	if err != nil {
+		return nil, err
+	}
+	anteriores, err := t.obterModulos(stub)
+	if err != nil {
+		return nil, err
+	}
+	if migrar {
+		// Com o módulo autenticacao já habilitado, somente o administrador pode migrar os dados
+		err = t.verificarAdmin(stub)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	modulosAsBytes, err := json.Marshal(modulos)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarModulos, err)
+	}
+	err = stub.PutState(chaveModulos, modulosAsBytes)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaRegistrarModulos, err)
+	}
+	log.Info("Módulos habilitados", "autenticacao", modulos.Autenticacao, "notificacao", modulos.Notificacao,
+		"armazenamento", modulos.Armazenamento)
+
+	if migrar {
+		err = migrarEsquema(stub, anteriores.Armazenamento, modulos.Armazenamento)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err = t.excluirPropostas(stub, modulos)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = iniciarConfiguracao(stub, migrar, configuracao)
+	if err != nil {
+		return nil, err
+	}
+
+	if modulos.Notificacao {
+		err = t.criarTabelaEntrega(stub)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	adminAtual, err := stub.GetState("admin")
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterAdmin)
+	}
+	if modulos.Autenticacao && (!migrar || len(adminAtual) == 0) {
+		// Set the admin
+		// The metadata will contain the certificate of the administrator
+		adminMeta, err := stub.GetCallerMetadata()
+		if err != nil {
+			log.Erro("Falha ao obter o metadata do caller", "erro", err)
+		}
+		if len(adminMeta) == 0 {
+			log.Aviso("Certificado do administrador (metadata) vazio")
+		}
+
+		log.Info("Administrador registrado", "metadata", adminMeta)
+		stub.PutState("admin", adminMeta)
+	}
+
+	// O nível de log vale a partir desta chamada
+	nivel, _ := lerNivelLog(modulos.NivelLog)
+	definirNivelLog(nivel)
+	log.Info("Init Chaincode... Finalizado!")
+
+	return nil, nil
+}
+
+// excluirPropostas: exclui as propostas existentes, em qualquer forma de armazenamento,
+// e cria o repositório escolhido na versão atual do esquema
+func (t *BoletoPropostaChaincode) excluirPropostas(stub shim.ChaincodeStubInterface, modulos Modulos) error {
+	for _, repositorio := range repositorios {
+		err := repositorio.Excluir(stub)
+		if err != nil {
+			return err
+		}
+	}
+	repositorio, err := obterRepositorio(modulos.Armazenamento)
+	if err != nil {
+		return err
+	}
+	err = repositorio.Criar(stub)
+	if err != nil {
+		return err
+	}
+	return gravarVersaoEsquema(stub, versaoEsquemaAtual)
+}
+
+// lerModulos: converte os argumentos do Init nos módulos habilitados
+func lerModulos(args []string) (Modulos, error) {
+	modulos := Modulos{Armazenamento: armazenamentoTabela}
+	for _, nome := range args {
+		switch {
+		case nome == moduloAutenticacao:
+			modulos.Autenticacao = true
+		case nome == moduloNotificacao:
+			modulos.Notificacao = true
+		case strings.HasPrefix(nome, argArmazenamento):
+			modulos.Armazenamento = strings.TrimPrefix(nome, argArmazenamento)
+			if _, err := obterRepositorio(modulos.Armazenamento); err != nil {
+				return modulos, err
+			}
+		case strings.HasPrefix(nome, argNivelLog):
+			modulos.NivelLog = strings.TrimPrefix(nome, argNivelLog)
+			if _, err := lerNivelLog(modulos.NivelLog); err != nil {
+				return modulos, err
+			}
+		default:
+			return modulos, novoErro(codigoArgumentosInvalidos, msgModuloDesconhecido, nome)
+		}
+	}
+	return modulos, nil
+}
+
+// obterModulos: retorna os módulos habilitados no Init
+func (t *BoletoPropostaChaincode) obterModulos(stub shim.ChaincodeStubInterface) (Modulos, error) {
+	var modulos Modulos
+	modulosAsBytes, err := stub.GetState(chaveModulos)
+	if err != nil {
+		return modulos, novoErro(codigoErroInterno, msgFalhaObterModulos, err)
+	}
+	if len(modulosAsBytes) == 0 {
+		return modulos, nil
+	}
+	err = json.Unmarshal(modulosAsBytes, &modulos)
+	if err != nil {
+		return modulos, novoErro(codigoErroInterno, msgFalhaDecodificarModulos, err)
+	}
+	return modulos, nil
+}
+
+// ============================================================================================================================
+// Invoke Functions
+// ============================================================================================================================
+
+// Invoke - Ponto de entrada para chamadas do tipo Invoke.
+// As funções suportadas, com os argumentos e o papel exigido do caller, estão registradas em
+// funcoesRegistradas e podem ser consultadas com a query "listarFuncoes()".
+// Todas as funções aceitam "idempotencia=<chave>" como último argumento (ver invocarIdempotente).
+// O resultado ou o erro é retornado no envelope Resposta (ver responder), com as mensagens no idioma
+// do argumento opcional "idioma=" ou do atributo "idioma" do caller (ver lerIdioma).
+func (t *BoletoPropostaChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	idioma, args, err := lerIdioma(stub, args)
+	if err != nil {
+		return responder(idioma, nil, err)
+	}
+	dados, err := t.invocar(stub, function, args)
+	return responder(idioma, dados, err)
+}
+
+// invocar: executa a função Invoke chamada, tratando a chave de idempotência
+func (t *BoletoPropostaChaincode) invocar(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	logChamada(stub).Debug("Invoke Chaincode...")
+
+	// Chamadas repetidas com a mesma chave de idempotência retornam o resultado original
+	chave, args, err := lerChaveIdempotencia(args)
+	if err != nil {
+		return nil, err
+	}
+	f := obterFuncao(tipoInvoke, function)
+	if f == nil {
+		logChamada(stub).Aviso("Função Invoke desconhecida")
+		return nil, novoErro(codigoFuncaoDesconhecida, msgInvokeDesconhecida, function)
+	}
+	return t.executarFuncao(stub, f, args, chave)
+}
+
+// ============================================================================================================================
+// Query
+// ============================================================================================================================
+
+// Query - Ponto de entrada para chamadas do tipo Query.
+// As funções suportadas estão registradas em funcoesRegistradas (ver listarFuncoes).
+// O resultado ou o erro é retornado no envelope Resposta (ver responder), no idioma escolhido como no Invoke.
+func (t *BoletoPropostaChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	idioma, args, err := lerIdioma(stub, args)
+	if err != nil {
+		return responder(idioma, nil, err)
+	}
+	dados, err := t.consultar(stub, function, args)
+	return responder(idioma, dados, err)
+}
+
+// consultar: executa a função Query chamada
+func (t *BoletoPropostaChaincode) consultar(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	log := logChamada(stub)
+	log.Debug("Query Chaincode...")
+
+	f := obterFuncao(tipoQuery, function)
+	if f == nil {
+		log.Aviso("Função Query desconhecida")
+		return nil, novoErro(codigoFuncaoDesconhecida, msgQueryDesconhecida, function)
+	}
+	return t.executarFuncao(stub, f, args, "")
+}
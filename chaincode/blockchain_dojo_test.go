@@ -0,0 +1,192 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/shimtest"
+)
+
+// metadata do administrador utilizada nos testes
+const adminTeste = "admin"
+
+// novoChaincode: cria o stub e executa o Init como administrador, com todos os módulos habilitados
+func novoChaincode(t *testing.T) (*shimtest.Stub, *BoletoPropostaChaincode) {
+	return iniciarChaincode(t, moduloAutenticacao, moduloNotificacao)
+}
+
+// iniciarChaincode: cria o stub e executa o Init como administrador, com os módulos informados
+func iniciarChaincode(t *testing.T, modulos ...string) (*shimtest.Stub, *BoletoPropostaChaincode) {
+	stub := shimtest.NewStub()
+	stub.CallerMetadata = []byte(adminTeste)
+	cc := new(BoletoPropostaChaincode)
+	if _, err := stub.MockInit(cc, "init", modulos...); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return stub, cc
+}
+
+// lerDados: decodifica os dados do envelope Resposta retornado pelo chaincode
+func lerDados(res []byte, v interface{}) error {
+	var resposta Resposta
+	err := json.Unmarshal(res, &resposta)
+	if err != nil {
+		return err
+	}
+	if !resposta.Sucesso {
+		return errors.New("resposta sem sucesso: " + string(res))
+	}
+	return json.Unmarshal(resposta.Dados, v)
+}
+
+// lerErro: decodifica o envelope Resposta do erro retornado pelo chaincode.
+// Erros que não estão no envelope (funções chamadas diretamente) são tratados como codigoErroInterno
+func lerErro(err error) *Erro {
+	var resposta Resposta
+	if json.Unmarshal([]byte(err.Error()), &resposta) != nil || resposta.Erro == nil {
+		return &Erro{Codigo: codigoErroInterno, Mensagem: err.Error()}
+	}
+	return resposta.Erro
+}
+
+// consultar: executa consultarProposta e decodifica o resultado
+func consultar(t *testing.T, stub *shimtest.Stub, cc *BoletoPropostaChaincode, id string) Proposta {
+	res, err := stub.MockQuery(cc, "consultarProposta", id)
+	if err != nil {
+		t.Fatalf("consultarProposta(%s): %v", id, err)
+	}
+	var p Proposta
+	if err := lerDados(res, &p); err != nil {
+		t.Fatalf("consultarProposta(%s) retornou JSON inválido: %s", id, res)
+	}
+	return p
+}
+
+// tiposEventos: tipos dos eventos emitidos pela última transação
+func tiposEventos(t *testing.T, stub *shimtest.Stub) []string {
+	e := stub.LastEvent()
+	if e == nil {
+		t.Fatal("nenhum evento emitido")
+	}
+	if e.Name != nomeEventoProposta {
+		t.Fatalf("evento %s; esperado %s", e.Name, nomeEventoProposta)
+	}
+	var eventos []Evento
+	if err := json.Unmarshal(e.Payload, &eventos); err != nil {
+		t.Fatalf("payload do evento inválido: %s", e.Payload)
+	}
+	var tipos []string
+	for _, ev := range eventos {
+		tipos = append(tipos, ev.Tipo)
+	}
+	return tipos
+}
+
+// verificarErro: verifica se err contém o trecho esperado
+func verificarErro(t *testing.T, nome string, err error, trecho string) {
+	if err == nil {
+		t.Errorf("%s: esperado erro contendo %q", nome, trecho)
+		return
+	}
+	if mensagem := lerErro(err).Mensagem; !strings.Contains(mensagem, trecho) {
+		t.Errorf("%s: erro %q não contém %q", nome, mensagem, trecho)
+	}
+}
+
+// verificarCodigo: verifica o código do erro retornado no envelope
+func verificarCodigo(t *testing.T, nome string, err error, codigo string) {
+	if err == nil {
+		t.Errorf("%s: esperado erro com o código %s", nome, codigo)
+		return
+	}
+	if e := lerErro(err); e.Codigo != codigo {
+		t.Errorf("%s: código %s; esperado %s (%s)", nome, e.Codigo, codigo, e.Mensagem)
+	}
+}
+
+// ============================================================================================================================
+// Init
+// ============================================================================================================================
+
+func TestInit(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	if string(stub.State("admin")) != adminTeste {
+		t.Errorf("admin = %q; esperado %q", stub.State("admin"), adminTeste)
+	}
+	for _, tabela := range []string{nomeTabelaProposta, nomeTabelaEntrega} {
+		if _, err := stub.GetTable(tabela); err != nil {
+			t.Errorf("tabela %s não criada: %v", tabela, err)
+		}
+	}
+
+	_, err := stub.MockInit(cc, "init", "x")
+	verificarErro(t, "módulo desconhecido", err, "Módulo desconhecido: x")
+
+	// O reset recria a tabela de propostas, mas mantém os eventos
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "false", "false")
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "init", moduloNotificacao)
+	verificarErro(t, "init via Invoke por outro caller", err, "identidade do administrador")
+	stub.CallerMetadata = []byte(adminTeste)
+	if _, err := stub.MockInvoke(cc, "init", moduloAutenticacao, moduloNotificacao); err != nil {
+		t.Fatalf("init via Invoke: %v", err)
+	}
+	_, err = stub.MockQuery(cc, "consultarProposta", "p1")
+	verificarErro(t, "proposta após reset", err, "não existente")
+	if seq, _ := cc.ultimaSequenciaEvento(stub); seq != 1 {
+		t.Errorf("sequência de eventos após reset = %d; esperado 1", seq)
+	}
+
+	stub.SimularFalha("CreateTable", errors.New("falha"))
+	_, err = stub.MockInit(cc, "init")
+	verificarErro(t, "falha no CreateTable", err, "Falha ao criar a tabela")
+}
+
+func TestInitSemModulos(t *testing.T) {
+	stub, cc := iniciarChaincode(t)
+
+	if stub.State("admin") != nil {
+		t.Errorf("admin registrado sem o módulo autenticacao: %q", stub.State("admin"))
+	}
+	if _, err := stub.GetTable(nomeTabelaEntrega); err == nil {
+		t.Error("tabela Entrega criada sem o módulo notificacao")
+	}
+
+	// Sem autenticacao qualquer caller pode registrar propostas, e sem notificacao nenhum evento é emitido
+	stub.CallerMetadata = []byte("outro")
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false"); err != nil {
+		t.Fatalf("registrarProposta: %v", err)
+	}
+	if e := stub.LastEvent(); e != nil {
+		t.Errorf("evento emitido sem o módulo notificacao: %s", e.Payload)
+	}
+	if seq, _ := cc.ultimaSequenciaEvento(stub); seq != 0 {
+		t.Errorf("sequência de eventos = %d; esperado 0", seq)
+	}
+
+	_, err := stub.MockQuery(cc, "consultarEventos", "0")
+	verificarErro(t, "consultarEventos", err, "Módulo notificacao não habilitado")
+	_, err = stub.MockInvoke(cc, "confirmarEntrega", "1", "bc-desafio", statusEntregue)
+	verificarErro(t, "confirmarEntrega", err, "Módulo notificacao não habilitado")
+	_, err = stub.MockQuery(cc, "consultarEntregas", "p1")
+	verificarErro(t, "consultarEntregas", err, "Módulo notificacao não habilitado")
+}
+
+// ============================================================================================================================
+// Dispatch
+// ============================================================================================================================
+
+func TestFuncaoDesconhecida(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	_, err := stub.MockInvoke(cc, "naoExiste")
+	verificarErro(t, "Invoke", err, "Invocação de função desconhecida: naoExiste")
+
+	_, err = stub.MockQuery(cc, "naoExiste")
+	verificarErro(t, "Query", err, "Query de função desconhecida: naoExiste")
+}
@@ -0,0 +1,195 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Cancelamento de propostas
+// ============================================================================================================================
+
+// Definição da Struct Cancelamento, solicitações de cancelamento de uma proposta. O registro é mantido
+// após o cancelamento, como histórico
+type Cancelamento struct {
+	Solicitacoes []SolicitacaoCancelamento `json:"solicitacoes"`
+	TxID         string                    `json:"tx_id,omitempty"` // transação que cancelou a proposta; vazio enquanto pendente
+}
+
+// Definição da Struct SolicitacaoCancelamento, solicitação de cancelamento de uma das partes
+type SolicitacaoCancelamento struct {
+	Parte  string `json:"parte"` // pagador ou beneficiario
+	Motivo string `json:"motivo,omitempty"`
+	TxID   string `json:"tx_id"`
+}
+
+// consts associadas ao cancelamento das propostas
+const (
+	partePagador             = "pagador"
+	parteBeneficiario        = "beneficiario"
+	prefixoChaveCancelamento = "cancelamento_"
+)
+
+// cancelarProposta: função Invoke para solicitar o cancelamento de uma proposta ainda não paga, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+// args[1]: parte. pagador ou beneficiario, verificada pelos atributos do caller (ver verificarParte)
+// args[2]: motivo (opcional)
+// Antes do aceite do pagador, o cancelamento do beneficiário é suficiente; depois, a proposta é cancelada
+// quando as duas partes solicitaram. A proposta cancelada é mantida, com cancelada true, e não aceita alterações
+func (t *BoletoPropostaChaincode) cancelarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("cancelarProposta...")
+	idProposta, parte := args[0], args[1]
+
+	if parte != partePagador && parte != parteBeneficiario {
+		return nil, novoErro(codigoArgumentosInvalidos, msgArgumentoInvalido, "parte")
+	}
+	anterior, err := t.obterProposta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+	if anterior == nil {
+		return nil, novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, idProposta)
+	}
+	if anterior.Cancelada {
+		return nil, novoErro(codigoPropostaCancelada, msgPropostaCancelada, idProposta)
+	}
+	if anterior.BoletoPago {
+		return nil, novoErro(codigoCancelamentoRejeitado, msgCancelamentoPropostaPaga, idProposta)
+	}
+	err = t.verificarParte(stub, *anterior, parte)
+	if err != nil {
+		return nil, err
+	}
+
+	// Partes que precisam solicitar o cancelamento, de acordo com o aceite do pagador
+	necessarias := []string{parteBeneficiario}
+	if anterior.PagadorAceitou {
+		necessarias = append(necessarias, partePagador)
+	} else if parte == partePagador {
+		return nil, novoErro(codigoCancelamentoRejeitado, msgCancelamentoSomenteBenef, idProposta)
+	}
+
+	cancelamento, err := obterCancelamento(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range cancelamento.Solicitacoes {
+		if s.Parte == parte {
+			return nil, novoErro(codigoCancelamentoRejeitado, msgCancelamentoJaSolicitado, idProposta, parte)
+		}
+	}
+	motivo := ""
+	if len(args) > 2 {
+		motivo = args[2]
+	}
+	cancelamento.Solicitacoes = append(cancelamento.Solicitacoes, SolicitacaoCancelamento{
+		Parte:  parte,
+		Motivo: motivo,
+		TxID:   stub.GetTxID(),
+	})
+
+	solicitadas := 0
+	for _, s := range cancelamento.Solicitacoes {
+		for _, n := range necessarias {
+			if s.Parte == n {
+				solicitadas++
+			}
+		}
+	}
+	resposta := fmt.Sprintf(`{"cancelada":%t,"solicitacoes":%d,"necessarias":%d}`, solicitadas == len(necessarias), solicitadas, len(necessarias))
+
+	// Falta a solicitação da outra parte: mantém o cancelamento pendente
+	if solicitadas < len(necessarias) {
+		err = gravarCancelamento(stub, idProposta, cancelamento)
+		if err != nil {
+			return nil, err
+		}
+		err = t.emitirEventos(stub, []string{eventoCancelamentoSolicitado}, *anterior)
+		if err != nil {
+			return nil, err
+		}
+		logChamada(stub).Info("Cancelamento solicitado", "id_proposta", idProposta, "parte", parte)
+		return []byte(resposta), nil
+	}
+
+	nova := *anterior
+	nova.Cancelada = true
+	nova, err = t.atualizarProposta(stub, nova, anterior.Versao)
+	if err != nil {
+		return nil, err
+	}
+	cancelamento.TxID = stub.GetTxID()
+	err = gravarCancelamento(stub, idProposta, cancelamento)
+	if err != nil {
+		return nil, err
+	}
+	err = t.emitirEventos(stub, eventosTransicao(anterior, nova), nova)
+	if err != nil {
+		return nil, err
+	}
+
+	logChamada(stub).Info("Proposta cancelada", "id_proposta", idProposta, "versao", nova.Versao)
+	return []byte(resposta), nil
+}
+
+// consultarCancelamento: função Query para consultar as solicitações de cancelamento de uma proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarCancelamento(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarCancelamento...")
+	cancelamento, err := obterCancelamento(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cancelamento)
+}
+
+// obterCancelamento: retorna as solicitações de cancelamento da proposta (nenhuma se não houver registro)
+func obterCancelamento(stub shim.ChaincodeStubInterface, idProposta string) (Cancelamento, error) {
+	cancelamento := Cancelamento{Solicitacoes: []SolicitacaoCancelamento{}}
+
+	cancelamentoAsBytes, err := stub.GetState(prefixoChaveCancelamento + idProposta)
+	if err != nil {
+		return cancelamento, novoErro(codigoErroInterno, msgFalhaObterCancelamento, idProposta, err)
+	}
+	if len(cancelamentoAsBytes) == 0 {
+		return cancelamento, nil
+	}
+	err = json.Unmarshal(cancelamentoAsBytes, &cancelamento)
+	if err != nil {
+		return cancelamento, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Cancelamento", err)
+	}
+	return cancelamento, nil
+}
+
+// gravarCancelamento: grava as solicitações de cancelamento da proposta
+func gravarCancelamento(stub shim.ChaincodeStubInterface, idProposta string, cancelamento Cancelamento) error {
+	cancelamentoAsBytes, err := json.Marshal(cancelamento)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Cancelamento", err)
+	}
+	err = stub.PutState(prefixoChaveCancelamento+idProposta, cancelamentoAsBytes)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaGravarCancelamento, idProposta, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"strings"
+	"testing"
+)
+
+func TestCancelarPropostaBeneficiario(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "true", "false", "15000")
+
+	// Antes do aceite do pagador, somente o beneficiário cancela
+	_, err := stub.MockInvoke(cc, "cancelarProposta", "p1", "pagador")
+	verificarErro(t, "caller sem atributo", err, "O caller não é o pagador da Proposta [p1].")
+	stub.Attributes = map[string][]byte{atributoCPF: []byte("111")}
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "pagador")
+	if err == nil || lerErro(err).Codigo != codigoCancelamentoRejeitado {
+		t.Errorf("pagador antes do aceite = %v; esperado %s", err, codigoCancelamentoRejeitado)
+	}
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "banco")
+	verificarErro(t, "parte inválida", err, "parte")
+
+	stub.Attributes = map[string][]byte{atributoPapel: []byte(parteBeneficiario)}
+	res, err := stub.MockInvoke(cc, "cancelarProposta", "p1", "beneficiario", "cliente desistiu")
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"cancelada":true,"solicitacoes":1,"necessarias":1}}` {
+		t.Fatalf("cancelarProposta = %s, %v", res, err)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != "PropostaAtualizada,PropostaCancelada" {
+		t.Errorf("eventos = %v", tipos)
+	}
+	if p := consultar(t, stub, cc, "p1"); !p.Cancelada || p.Versao != 2 {
+		t.Errorf("proposta cancelada = %+v", p)
+	}
+
+	// A proposta cancelada é mantida, mas não aceita alterações
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000", "2")
+	if err == nil || lerErro(err).Codigo != codigoPropostaCancelada {
+		t.Errorf("atualização da proposta cancelada = %v; esperado %s", err, codigoPropostaCancelada)
+	}
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "beneficiario")
+	verificarErro(t, "cancelamento repetido", err, "Proposta [p1] cancelada.")
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "beneficiario", "idioma=en")
+	verificarErro(t, "cancelamento repetido em inglês", err, "Proposal [p1] cancelled.")
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p9", "beneficiario")
+	verificarErro(t, "proposta inexistente", err, "Proposta [p9] não existente.")
+}
+
+func TestCancelarPropostaAceita(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+	stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "true", "true", "15000")
+
+	// Depois do aceite, a proposta é cancelada quando as duas partes solicitam
+	stub.Attributes = map[string][]byte{atributoPapel: []byte(parteBeneficiario)}
+	res, err := stub.MockInvoke(cc, "cancelarProposta", "p1", "beneficiario")
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"cancelada":false,"solicitacoes":1,"necessarias":2}}` {
+		t.Fatalf("primeira solicitação = %s, %v", res, err)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != eventoCancelamentoSolicitado {
+		t.Errorf("eventos = %v", tipos)
+	}
+	if p := consultar(t, stub, cc, "p1"); p.Cancelada || p.Versao != 1 {
+		t.Errorf("proposta com cancelamento pendente = %+v", p)
+	}
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "beneficiario")
+	verificarErro(t, "solicitação repetida", err, "já solicitado pelo beneficiario")
+
+	stub.Attributes = map[string][]byte{atributoCPF: []byte("222")}
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "pagador")
+	verificarErro(t, "outro pagador", err, "O caller não é o pagador")
+	stub.Attributes = map[string][]byte{atributoCPF: []byte("111")}
+	res, err = stub.MockInvoke(cc, "cancelarProposta", "p1", "pagador", "acordo entre as partes")
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"cancelada":true,"solicitacoes":2,"necessarias":2}}` {
+		t.Fatalf("segunda solicitação = %s, %v", res, err)
+	}
+	if p := consultar(t, stub, cc, "p1"); !p.Cancelada {
+		t.Errorf("proposta = %+v", p)
+	}
+
+	// As solicitações são mantidas como histórico
+	res, err = stub.MockQuery(cc, "consultarCancelamento", "p1")
+	var cancelamento Cancelamento
+	if err != nil || lerDados(res, &cancelamento) != nil || len(cancelamento.Solicitacoes) != 2 || cancelamento.TxID == "" ||
+		cancelamento.Solicitacoes[1].Motivo != "acordo entre as partes" {
+		t.Errorf("consultarCancelamento = %s, %v", res, err)
+	}
+
+	// Somente antes do pagamento
+	_, err = stub.MockInvoke(cc, "cancelarProposta", "p2", "pagador")
+	if err == nil || lerErro(err).Codigo != codigoCancelamentoRejeitado {
+		t.Errorf("proposta paga = %v; esperado %s", err, codigoCancelamentoRejeitado)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Codificação JSON canônica, utilizada sempre que o chaincode calcula o hash ou verifica
+a assinatura de dados do ledger. A mesma informação gera sempre os mesmos bytes,
+independente da ordem dos campos da struct ou do JSON recebido:
+
+	- objetos com as chaves ordenadas pelos bytes UTF-8, sem chaves duplicadas
+	- nenhum espaço entre os elementos
+	- números inteiros em decimal, sem expoente, sinal + ou zeros à esquerda
+	  (1.0 e 1e2 viram 1 e 100); números não inteiros ou fora do intervalo de
+	  int64/uint64 não são aceitos
+	- strings em UTF-8 na forma de normalização NFC ("e" seguido de U+0301 vira "é", U+00E9),
+	  com sequências inválidas substituídas por U+FFFD. São escapados
+	  apenas aspas, barra invertida e caracteres de controle (\b, \f, \n, \r, \t ou \u00xx)
+
+Uso típico:
+
+	conteudo, err := canonico.Codificar(proposta)
+	hash := primitives.Hash(conteudo)
+*/
+
+// nome do package
+package canonico
+
+// lista de imports
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// limites dos números aceitos: int64 e uint64
+var (
+	minimo = new(big.Int).Lsh(big.NewInt(-1), 63)
+	maximo = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+)
+
+// Codificar retorna a codificação canônica do JSON de v (ver json.Marshal)
+func Codificar(v interface{}) ([]byte, error) {
+	dados, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return CodificarJSON(dados)
+}
+
+// CodificarJSON retorna a codificação canônica de um documento JSON
+func CodificarJSON(dados []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(dados))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	err := codificarValor(dec, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("JSON inválido: conteúdo após o fim do documento")
+	}
+	return buf.Bytes(), nil
+}
+
+// codificarValor: lê o próximo valor do decoder e escreve a sua forma canônica
+func codificarValor(dec *json.Decoder, buf *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("JSON inválido: %v", err)
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '[' {
+			return codificarLista(dec, buf)
+		}
+		return codificarObjeto(dec, buf)
+	case string:
+		codificarString(v, buf)
+	case json.Number:
+		return codificarNumero(v, buf)
+	case bool:
+		if v {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case nil:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+// codificarLista: elementos da lista, na ordem recebida
+func codificarLista(dec *json.Decoder, buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		err := codificarValor(dec, buf)
+		if err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return fechar(dec)
+}
+
+// codificarObjeto: membros do objeto, ordenados pela chave
+func codificarObjeto(dec *json.Decoder, buf *bytes.Buffer) error {
+	membros := map[string][]byte{}
+	var chaves []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("JSON inválido: %v", err)
+		}
+		// chaves equivalentes após a normalização NFC são duplicadas
+		chave := norm.NFC.String(tok.(string))
+		if _, ok := membros[chave]; ok {
+			return fmt.Errorf("Chave duplicada no JSON: %s", chave)
+		}
+
+		var valor bytes.Buffer
+		err = codificarValor(dec, &valor)
+		if err != nil {
+			return err
+		}
+		membros[chave] = valor.Bytes()
+		chaves = append(chaves, chave)
+	}
+	err := fechar(dec)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(chaves)
+	buf.WriteByte('{')
+	for i, chave := range chaves {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		codificarString(chave, buf)
+		buf.WriteByte(':')
+		buf.Write(membros[chave])
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// fechar: lê o delimitador que fecha a lista ou o objeto
+func fechar(dec *json.Decoder) error {
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("JSON inválido: %v", err)
+	}
+	return nil
+}
+
+// codificarNumero: número inteiro em decimal
+func codificarNumero(n json.Number, buf *bytes.Buffer) error {
+	f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+	if err != nil || !f.IsInt() {
+		return fmt.Errorf("Número não suportado na codificação canônica: %s", n)
+	}
+	i, _ := f.Int(nil)
+	if i.Cmp(minimo) < 0 || i.Cmp(maximo) > 0 {
+		return fmt.Errorf("Número fora do intervalo suportado na codificação canônica: %s", n)
+	}
+	buf.WriteString(i.String())
+	return nil
+}
+
+// codificarString: string na forma NFC entre aspas, escapando apenas os caracteres obrigatórios
+func codificarString(s string, buf *bytes.Buffer) {
+	s = norm.NFC.String(s)
+	buf.WriteByte('"')
+	// range converte as sequências UTF-8 inválidas em U+FFFD
+	for _, r := range s {
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\b':
+			buf.WriteString(`\b`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,111 @@
+// nome do package
+package canonico
+
+// lista de imports
+import (
+	"strings"
+	"testing"
+)
+
+// vetores de teste da codificação canônica: JSON recebido e bytes esperados
+var vetores = []struct {
+	nome     string
+	entrada  string
+	esperado string
+}{
+	{"ordem das chaves", `{"b":1,"a":2,"c":{"z":true,"y":null}}`, `{"a":2,"b":1,"c":{"y":null,"z":true}}`},
+	{"espaços", " {\n\t\"a\" : [ 1 , 2 ] } ", `{"a":[1,2]}`},
+	{"ordem das listas mantida", `[3,1,2]`, `[3,1,2]`},
+	{"chaves por bytes UTF-8", `{"é":1,"z":2,"A":3}`, `{"A":3,"z":2,"é":1}`},
+	{"inteiro com expoente", `{"v":1e2}`, `{"v":100}`},
+	{"inteiro com fração zero", `{"v":15000.00}`, `{"v":15000}`},
+	{"zero negativo", `-0`, `0`},
+	{"limite de int64", `-9223372036854775808`, `-9223372036854775808`},
+	{"limite de uint64", `18446744073709551615`, `18446744073709551615`},
+	{"escapes obrigatórios", `"aspas \" barra \\ \n\t\u0001"`, `"aspas \" barra \\ \n\t\u0001"`},
+	{"escapes desnecessários", `"é\/<"`, `"é/<"`},
+	{"HTML sem escape", `"<a&b>"`, `"<a&b>"`},
+	{"UTF-8 inválido", "\"a\xffb\"", "\"a�b\""},
+	{"NFC já composto", "\"Jos\u00e9\"", "\"Jos\u00e9\""},
+	{"NFC decomposto", "\"Jose\u0301\"", "\"Jos\u00e9\""},
+	{"NFC com escape JSON", `"Jose\u0301"`, "\"Jos\u00e9\""},
+	{"NFC nas chaves", "{\"e\u0301\":1}", "{\"\u00e9\":1}"},
+	{"objeto e lista vazios", `{"a":{},"b":[]}`, `{"a":{},"b":[]}`},
+	{"proposta", `{"id_proposta":"p1","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false,"valor":15000,"versao":1}`,
+		`{"beneficiario_aceitou":false,"boleto_pago":false,"cpf_pagador":"111","id_proposta":"p1","pagador_aceitou":true,"valor":15000,"versao":1}`},
+}
+
+func TestCodificarJSON(t *testing.T) {
+	for _, v := range vetores {
+		res, err := CodificarJSON([]byte(v.entrada))
+		if err != nil {
+			t.Errorf("%s: %v", v.nome, err)
+			continue
+		}
+		if string(res) != v.esperado {
+			t.Errorf("%s: %s; esperado %s", v.nome, res, v.esperado)
+		}
+
+		// A codificação canônica não se altera ao ser codificada novamente
+		if novamente, _ := CodificarJSON(res); string(novamente) != string(res) {
+			t.Errorf("%s: recodificação = %s", v.nome, novamente)
+		}
+	}
+}
+
+func TestCodificarJSONInvalido(t *testing.T) {
+	casos := []struct {
+		nome    string
+		entrada string
+		trecho  string
+	}{
+		{"vazio", ``, "JSON inválido"},
+		{"incompleto", `{"a":1`, "JSON inválido"},
+		{"vírgula final", `[1,]`, "JSON inválido"},
+		{"conteúdo após o documento", `{} {}`, "após o fim do documento"},
+		{"chave duplicada", `{"a":1,"a":2}`, "Chave duplicada no JSON: a"},
+		{"chave duplicada após NFC", "{\"\u00e9\":1,\"e\u0301\":2}", "Chave duplicada no JSON: \u00e9"},
+		{"número não inteiro", `{"v":1.5}`, "Número não suportado"},
+		{"número fora do intervalo", `18446744073709551616`, "fora do intervalo"},
+		{"número muito pequeno", `-9223372036854775809`, "fora do intervalo"},
+	}
+
+	for _, c := range casos {
+		_, err := CodificarJSON([]byte(c.entrada))
+		if err == nil || !strings.Contains(err.Error(), c.trecho) {
+			t.Errorf("%s: erro %v; esperado erro contendo %q", c.nome, err, c.trecho)
+		}
+	}
+}
+
+func TestCodificar(t *testing.T) {
+	// A ordem dos campos da struct não altera a codificação
+	a := struct {
+		B string `json:"b"`
+		A int64  `json:"a"`
+	}{"x", 1}
+	b := struct {
+		A int64  `json:"a"`
+		B string `json:"b"`
+	}{1, "x"}
+
+	resA, err := Codificar(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resB, _ := Codificar(b)
+	if string(resA) != `{"a":1,"b":"x"}` || string(resA) != string(resB) {
+		t.Errorf("Codificar = %s e %s", resA, resB)
+	}
+
+	// Formas composta e decomposta do mesmo texto têm a mesma codificação
+	composto, _ := Codificar(map[string]string{"nome": "Jos\u00e9"})
+	decomposto, _ := Codificar(map[string]string{"nome": "Jose\u0301"})
+	if string(composto) != string(decomposto) {
+		t.Errorf("Codificar = %q e %q", composto, decomposto)
+	}
+
+	if _, err := Codificar(func() {}); err == nil {
+		t.Error("Codificar de tipo não suportado: esperado erro")
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/CaueP/BlockchainDesafio/chaincode/canonico
+
+go 1.22
+
+require golang.org/x/text v0.17.0
@@ -0,0 +1,351 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+)
+
+// ============================================================================================================================
+// Configuração
+// ============================================================================================================================
+
+// Definição da Struct Configuracao, parâmetros do chaincode gravados no estado. É iniciada no Init
+// (argumento "configuracao=<JSON>") e alterada pelo administrador com atualizarConfiguracao
+type Configuracao struct {
+	URLExterna             string `json:"url_externa"`              // API externa em que o relay entrega os eventos
+	AlgoritmoSeguranca     string `json:"algoritmo_seguranca"`      // algoritmo de hash do primitives.SetSecurityLevel: SHA2 ou SHA3
+	NivelSeguranca         int    `json:"nivel_seguranca"`          // tamanho do hash em bits: 256 ou 384
+	MaxConsultaPropostas   int    `json:"max_consulta_propostas"`   // Ids aceitos por consultarPropostas
+	MaxLotePropostas       int    `json:"max_lote_propostas"`       // propostas aceitas por registrarPropostasEmLote
+	PrazoIdempotenciaHoras int    `json:"prazo_idempotencia_horas"` // prazo em que a chave de idempotência não é reutilizada; 0 sem prazo
+	Versao                 uint64 `json:"versao"`                   // incrementada a cada alteração
+	TxID                   string `json:"tx_id"`                    // transação da alteração
+}
+
+// consts associadas à configuração
+const (
+	chaveConfiguracao                 = "configuracao"
+	prefixoChaveHistoricoConfiguracao = "configuracao_historico_" // seguido da versão com 20 dígitos
+	argConfiguracao                   = "configuracao="           // argumento do Init com o JSON da configuração
+
+	padraoAlgoritmoSeguranca = "SHA3"
+	padraoNivelSeguranca     = 256
+	padraoMaxLotePropostas   = 100
+)
+
+// configuracaoPadrao: configuração utilizada antes do Init e como base do Init sem "migrar"
+func configuracaoPadrao() Configuracao {
+	return Configuracao{
+		AlgoritmoSeguranca:   padraoAlgoritmoSeguranca,
+		NivelSeguranca:       padraoNivelSeguranca,
+		MaxConsultaPropostas: padraoMaxConsultaPropostas,
+		MaxLotePropostas:     padraoMaxLotePropostas,
+	}
+}
+
+// validar: verifica os valores da configuração
+func (c Configuracao) validar() error {
+	if c.URLExterna != "" {
+		u, err := url.Parse(c.URLExterna)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return novoErro(codigoArgumentosInvalidos, msgURLExternaInvalida, c.URLExterna)
+		}
+	}
+	if c.AlgoritmoSeguranca != "SHA2" && c.AlgoritmoSeguranca != "SHA3" {
+		return novoErro(codigoArgumentosInvalidos, msgAlgoritmoSegurancaInvalido, c.AlgoritmoSeguranca)
+	}
+	if c.NivelSeguranca != 256 && c.NivelSeguranca != 384 {
+		return novoErro(codigoArgumentosInvalidos, msgNivelSegurancaInvalido, c.NivelSeguranca)
+	}
+	if c.MaxConsultaPropostas < 1 || c.MaxLotePropostas < 1 {
+		return novoErro(codigoArgumentosInvalidos, msgMaximosPropostasInvalidos)
+	}
+	if c.PrazoIdempotenciaHoras < 0 {
+		return novoErro(codigoArgumentosInvalidos, msgPrazoIdempotenciaNegativo, c.PrazoIdempotenciaHoras)
+	}
+	return nil
+}
+
+// lerAlteracaoConfiguracao: aplica sobre a configuração base os campos informados no JSON.
+// Campos desconhecidos são rejeitados, e a versão e a transação não podem ser alteradas
+func lerAlteracaoConfiguracao(base Configuracao, texto string) (Configuracao, error) {
+	nova := base
+	decoder := json.NewDecoder(strings.NewReader(texto))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&nova)
+	if err != nil {
+		return base, novoErro(codigoArgumentosInvalidos, msgConfiguracaoInvalida, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return base, novoErro(codigoArgumentosInvalidos, msgConfiguracaoConteudoAposFim)
+	}
+	nova.Versao, nova.TxID = base.Versao, base.TxID
+	return nova, nova.validar()
+}
+
+// lerArgConfiguracao: separa o argumento "configuracao=<JSON>" dos demais argumentos do Init
+func lerArgConfiguracao(args []string) (string, []string) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, argConfiguracao) {
+			restantes := append(append([]string{}, args[:i]...), args[i+1:]...)
+			return strings.TrimPrefix(arg, argConfiguracao), restantes
+		}
+	}
+	return "", args
+}
+
+// obterConfiguracao: retorna a configuração gravada, ou a padrão (versão 0) antes do primeiro Init
+func obterConfiguracao(stub shim.ChaincodeStubInterface) (Configuracao, error) {
+	configuracao := configuracaoPadrao()
+	configuracaoAsBytes, err := stub.GetState(chaveConfiguracao)
+	if err != nil {
+		return configuracao, novoErro(codigoErroInterno, msgFalhaObterConfiguracao, err)
+	}
+	if len(configuracaoAsBytes) == 0 {
+		return configuracao, nil
+	}
+	err = json.Unmarshal(configuracaoAsBytes, &configuracao)
+	if err != nil {
+		return configuracao, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Configuracao", err)
+	}
+	return configuracao, nil
+}
+
+// gravarConfiguracao: grava a nova versão da configuração e o seu registro no histórico,
+// e aplica o nível de segurança
+func gravarConfiguracao(stub shim.ChaincodeStubInterface, anterior, nova Configuracao) (Configuracao, error) {
+	nova.Versao = anterior.Versao + 1
+	nova.TxID = stub.GetTxID()
+	configuracaoAsBytes, err := json.Marshal(nova)
+	if err != nil {
+		return nova, novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Configuracao", err)
+	}
+	err = stub.PutState(chaveConfiguracao, configuracaoAsBytes)
+	if err != nil {
+		return nova, novoErro(codigoErroInterno, msgFalhaGravarConfiguracao, err)
+	}
+	err = stub.PutState(fmt.Sprintf("%s%020d", prefixoChaveHistoricoConfiguracao, nova.Versao), configuracaoAsBytes)
+	if err != nil {
+		return nova, novoErro(codigoErroInterno, msgFalhaGravarHistoricoConfiguracao, err)
+	}
+
+	err = aplicarNivelSeguranca(nova)
+	if err != nil {
+		return nova, err
+	}
+	logChamada(stub).Info("Configuração gravada", "versao", nova.Versao, "campos", camposAlterados(anterior, nova))
+	return nova, nil
+}
+
+// iniciarConfiguracao: grava a configuração do Init. Com "migrar", a configuração existente é mantida
+// (ou criada a partir do máximo de consultarPropostas gravado pelas versões anteriores); caso contrário
+// ela volta à padrão. Os campos do argumento "configuracao=" são aplicados sobre ela
+func iniciarConfiguracao(stub shim.ChaincodeStubInterface, migrar bool, texto string) error {
+	anterior, err := obterConfiguracao(stub)
+	if err != nil {
+		return err
+	}
+	base := configuracaoPadrao()
+	if migrar {
+		base = anterior
+	}
+
+	maximoAsBytes, err := stub.GetState(chaveMaxConsultaPropostas)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaObterMaxConsulta, err)
+	}
+	if len(maximoAsBytes) > 0 {
+		if migrar && anterior.Versao == 0 {
+			base.MaxConsultaPropostas, err = strconv.Atoi(string(maximoAsBytes))
+			if err != nil {
+				return novoErro(codigoErroInterno, msgMaxConsultaGravadoInvalido, maximoAsBytes)
+			}
+		}
+		err = stub.DelState(chaveMaxConsultaPropostas)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaExcluirMaxConsulta, err)
+		}
+	}
+
+	nova := base
+	if texto != "" {
+		nova, err = lerAlteracaoConfiguracao(base, texto)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = gravarConfiguracao(stub, anterior, nova)
+	return err
+}
+
+// atualizarConfiguracao: função Invoke para alterar a configuração do chaincode, recebendo os seguintes argumentos:
+// args[0]: JSON com os campos da Configuracao a alterar; os demais são mantidos
+// args[1]: versaoEsperada (opcional). Versão retornada por consultarConfiguracao. A alteração falha com
+// conflito de versão se a configuração foi alterada desde a consulta
+// Cada alteração gera uma nova versão, registrada no histórico (ver consultarHistoricoConfiguracao)
+func (t *BoletoPropostaChaincode) atualizarConfiguracao(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("atualizarConfiguracao...")
+
+	anterior, err := obterConfiguracao(stub)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 2 {
+		versaoEsperada, err := lerNatural("versaoEsperada", args[1])
+		if err != nil {
+			return nil, err
+		}
+		if versaoEsperada != anterior.Versao {
+			return nil, novoErro(codigoConflitoVersao, msgConflitoVersaoConfiguracao, versaoEsperada, anterior.Versao)
+		}
+	}
+
+	nova, err := lerAlteracaoConfiguracao(anterior, args[0])
+	if err != nil {
+		return nil, err
+	}
+	nova, err = gravarConfiguracao(stub, anterior, nova)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(nova)
+}
+
+// alterarConfiguracao: altera um campo da configuração, como atualizarConfiguracao
+func alterarConfiguracao(stub shim.ChaincodeStubInterface, alterar func(*Configuracao)) error {
+	anterior, err := obterConfiguracao(stub)
+	if err != nil {
+		return err
+	}
+	nova := anterior
+	alterar(&nova)
+	err = nova.validar()
+	if err != nil {
+		return err
+	}
+	_, err = gravarConfiguracao(stub, anterior, nova)
+	return err
+}
+
+// consultarConfiguracao: função Query para consultar a configuração atual. Não recebe argumentos
+func (t *BoletoPropostaChaincode) consultarConfiguracao(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarConfiguracao...")
+
+	configuracao, err := obterConfiguracao(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(configuracao)
+}
+
+// consultarHistoricoConfiguracao: função Query para consultar todas as versões da configuração,
+// da mais antiga à atual. Não recebe argumentos
+func (t *BoletoPropostaChaincode) consultarHistoricoConfiguracao(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarHistoricoConfiguracao...")
+
+	chaves, err := chavesComPrefixo(stub, prefixoChaveHistoricoConfiguracao)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaConsultarHistoricoConfiguracao, err)
+	}
+	historico := []Configuracao{}
+	for _, chave := range chaves {
+		configuracaoAsBytes, err := stub.GetState(chave)
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaObterVersaoConfiguracao, chave, err)
+		}
+		var configuracao Configuracao
+		err = json.Unmarshal(configuracaoAsBytes, &configuracao)
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Configuracao", err)
+		}
+		historico = append(historico, configuracao)
+	}
+	return json.Marshal(historico)
+}
+
+// camposAlterados: nomes JSON dos campos com valores diferentes nas duas configurações
+func camposAlterados(anterior, nova Configuracao) []string {
+	alterados := []string{}
+	if anterior.URLExterna != nova.URLExterna {
+		alterados = append(alterados, "url_externa")
+	}
+	if anterior.AlgoritmoSeguranca != nova.AlgoritmoSeguranca {
+		alterados = append(alterados, "algoritmo_seguranca")
+	}
+	if anterior.NivelSeguranca != nova.NivelSeguranca {
+		alterados = append(alterados, "nivel_seguranca")
+	}
+	if anterior.MaxConsultaPropostas != nova.MaxConsultaPropostas {
+		alterados = append(alterados, "max_consulta_propostas")
+	}
+	if anterior.MaxLotePropostas != nova.MaxLotePropostas {
+		alterados = append(alterados, "max_lote_propostas")
+	}
+	if anterior.PrazoIdempotenciaHoras != nova.PrazoIdempotenciaHoras {
+		alterados = append(alterados, "prazo_idempotencia_horas")
+	}
+	return alterados
+}
+
+// ============================================================================================================================
+// Nível de segurança
+// ============================================================================================================================
+
+var (
+	// nível de segurança aplicado no processo do chaincode, definido em main com o padrão
+	muNivelSeguranca       sync.Mutex
+	nivelSegurancaAplicado string
+)
+
+// aplicarNivelSeguranca: aplica o nível de segurança da configuração (primitives.SetSecurityLevel),
+// utilizado apenas no hash dos Ids gerados (as assinaturas dos oráculos usam sempre SHA3-256)
+func aplicarNivelSeguranca(c Configuracao) error {
+	muNivelSeguranca.Lock()
+	defer muNivelSeguranca.Unlock()
+	nivel := c.AlgoritmoSeguranca + "-" + strconv.Itoa(c.NivelSeguranca)
+	if nivel == nivelSegurancaAplicado {
+		return nil
+	}
+	err := primitives.SetSecurityLevel(c.AlgoritmoSeguranca, c.NivelSeguranca)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaAplicarNivelSeguranca, nivel, err)
+	}
+	nivelSegurancaAplicado = nivel
+	return nil
+}
+
+// carregarNivelSeguranca: aplica o nível de segurança da configuração gravada. Chamada antes de cada
+// hash, para que o chaincode reiniciado no peer volte ao nível configurado
+func carregarNivelSeguranca(stub shim.ChaincodeStubInterface) error {
+	configuracao, err := obterConfiguracao(stub)
+	if err != nil {
+		return err
+	}
+	return aplicarNivelSeguranca(configuracao)
+}
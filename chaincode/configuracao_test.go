@@ -0,0 +1,138 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"testing"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/shimtest"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// lerConfiguracaoAtual: configuração retornada por consultarConfiguracao
+func lerConfiguracaoAtual(t *testing.T, stub *shimtest.Stub, cc *BoletoPropostaChaincode) Configuracao {
+	res, err := stub.MockQuery(cc, "consultarConfiguracao")
+	var configuracao Configuracao
+	if err != nil || lerDados(res, &configuracao) != nil {
+		t.Fatalf("consultarConfiguracao = %s, %v", res, err)
+	}
+	return configuracao
+}
+
+func TestConfiguracao(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	configuracao := lerConfiguracaoAtual(t, stub, cc)
+	padrao := configuracaoPadrao()
+	padrao.Versao, padrao.TxID = 1, configuracao.TxID
+	if configuracao != padrao {
+		t.Errorf("configuração inicial = %+v", configuracao)
+	}
+
+	res, err := stub.MockInvoke(cc, "atualizarConfiguracao", `{"url_externa":"https://api.exemplo.com/atualizar","max_lote_propostas":2}`, "1")
+	if err != nil || lerDados(res, &configuracao) != nil {
+		t.Fatalf("atualizarConfiguracao = %s, %v", res, err)
+	}
+	if configuracao.Versao != 2 || configuracao.MaxLotePropostas != 2 || configuracao.MaxConsultaPropostas != padraoMaxConsultaPropostas {
+		t.Errorf("configuração alterada = %+v", configuracao)
+	}
+
+	// O máximo por lote passa a valer para registrarPropostasEmLote
+	lote := "[" + registroLote("p1", "111", "") + "," + registroLote("p2", "222", "") + "," + registroLote("p3", "333", "") + "]"
+	_, err = stub.MockInvoke(cc, "registrarPropostasEmLote", lote)
+	verificarErro(t, "lote acima do máximo", err, "Quantidade de propostas [3] maior que o máximo por lote [2].")
+
+	casos := []struct {
+		nome   string
+		args   []string
+		codigo string
+		trecho string
+	}{
+		{"campo desconhecido", []string{`{"url":"x"}`}, codigoArgumentosInvalidos, `unknown field "url"`},
+		{"URL inválida", []string{`{"url_externa":"ftp://api"}`}, codigoArgumentosInvalidos, "URL externa inválida"},
+		{"algoritmo inválido", []string{`{"algoritmo_seguranca":"MD5"}`}, codigoArgumentosInvalidos, "Algoritmo de segurança [MD5] inválido"},
+		{"nível inválido", []string{`{"nivel_seguranca":512}`}, codigoArgumentosInvalidos, "Nível de segurança [512] inválido"},
+		{"máximo zero", []string{`{"max_consulta_propostas":0}`}, codigoArgumentosInvalidos, "maiores que zero"},
+		{"conteúdo após o JSON", []string{`{"max_lote_propostas":5}]`}, codigoArgumentosInvalidos, "conteúdo após o fim do JSON"},
+		{"JSON repetido", []string{`{"max_lote_propostas":5} {}`}, codigoArgumentosInvalidos, "conteúdo após o fim do JSON"},
+		{"prazo negativo", []string{`{"prazo_idempotencia_horas":-1}`}, codigoArgumentosInvalidos, "Prazo de idempotência [-1] negativo"},
+		{"versão desatualizada", []string{`{"max_lote_propostas":5}`, "1"}, codigoConflitoVersao, "versão esperada [1], atual [2]"},
+	}
+	for _, c := range casos {
+		_, err := stub.MockInvoke(cc, "atualizarConfiguracao", c.args...)
+		verificarCodigo(t, c.nome, err, c.codigo)
+		verificarErro(t, c.nome, err, c.trecho)
+	}
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "atualizarConfiguracao", `{"max_lote_propostas":5}`)
+	verificarCodigo(t, "caller não administrador", err, codigoAcessoNegado)
+	stub.CallerMetadata = []byte(adminTeste)
+
+	// configurarMaxConsultaPropostas altera a configuração e gera uma nova versão
+	if _, err := stub.MockInvoke(cc, "configurarMaxConsultaPropostas", "5"); err != nil {
+		t.Fatalf("configurarMaxConsultaPropostas: %v", err)
+	}
+	var historico []Configuracao
+	res, _ = stub.MockQuery(cc, "consultarHistoricoConfiguracao")
+	lerDados(res, &historico)
+	if len(historico) != 3 || historico[0].Versao != 1 || historico[1].URLExterna == "" || historico[2].MaxConsultaPropostas != 5 {
+		t.Errorf("histórico = %s", res)
+	}
+	if historico[2].URLExterna != historico[1].URLExterna || historico[2].TxID == historico[1].TxID {
+		t.Errorf("versão 3 = %+v", historico[2])
+	}
+}
+
+func TestIniciarConfiguracao(t *testing.T) {
+	stub, cc := iniciarChaincode(t, moduloAutenticacao, `configuracao={"max_consulta_propostas":2}`)
+	if c := lerConfiguracaoAtual(t, stub, cc); c.MaxConsultaPropostas != 2 || c.Versao != 1 {
+		t.Errorf("configuração do Init = %+v", c)
+	}
+	_, err := stub.MockQuery(cc, "consultarPropostas", "p1", "p2", "p3")
+	verificarErro(t, "consulta acima do máximo", err, "maior que o máximo por consulta [2]")
+
+	// O Init com "migrar" mantém a configuração; sem "migrar" ela volta à padrão
+	stub.MockInit(cc, "init", argMigrar, moduloAutenticacao, `configuracao={"prazo_idempotencia_horas":24}`)
+	if c := lerConfiguracaoAtual(t, stub, cc); c.MaxConsultaPropostas != 2 || c.PrazoIdempotenciaHoras != 24 || c.Versao != 2 {
+		t.Errorf("configuração migrada = %+v", c)
+	}
+	stub.MockInit(cc, "init", moduloAutenticacao)
+	if c := lerConfiguracaoAtual(t, stub, cc); c.MaxConsultaPropostas != padraoMaxConsultaPropostas || c.Versao != 3 {
+		t.Errorf("configuração reiniciada = %+v", c)
+	}
+
+	// O máximo gravado pelas versões anteriores é levado para a configuração na migração
+	stub, cc = iniciarChaincode(t)
+	stub.PutState(chaveMaxConsultaPropostas, []byte("7"))
+	stub.DelState(chaveConfiguracao)
+	stub.MockInit(cc, "init", argMigrar)
+	if c := lerConfiguracaoAtual(t, stub, cc); c.MaxConsultaPropostas != 7 {
+		t.Errorf("configuração migrada da chave anterior = %+v", c)
+	}
+	if stub.State(chaveMaxConsultaPropostas) != nil {
+		t.Error("chave anterior não excluída")
+	}
+
+	_, err = stub.MockInit(cc, "init", `configuracao={"nivel_seguranca":1}`)
+	verificarErro(t, "configuração inválida no Init", err, "Nível de segurança [1] inválido")
+}
+
+func TestPrazoIdempotencia(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "atualizarConfiguracao", `{"prazo_idempotencia_horas":1}`)
+
+	stub.Timestamp = &timestamp.Timestamp{Seconds: 1000}
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false", "idempotencia=req-1")
+
+	// Dentro do prazo a chave não pode ser reutilizada com outros argumentos
+	stub.Timestamp = &timestamp.Timestamp{Seconds: 1000 + 3599}
+	_, err := stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "false", "false", "idempotencia=req-1")
+	verificarCodigo(t, "chave dentro do prazo", err, codigoIdempotenciaConflitante)
+
+	// Após o prazo a chave é aceita como nova
+	stub.Timestamp = &timestamp.Timestamp{Seconds: 1000 + 3600}
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "false", "false", "idempotencia=req-1"); err != nil {
+		t.Fatalf("chave após o prazo: %v", err)
+	}
+	consultar(t, stub, cc, "p2")
+}
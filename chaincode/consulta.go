@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Definição da Struct ResultadoConsulta, resultado de cada Id consultado por consultarPropostas
+type ResultadoConsulta struct {
+	Proposta *Proposta `json:"proposta,omitempty"`
+	Codigo   string    `json:"codigo,omitempty"` // código do erro (ver codigosErro)
+	Erro     string    `json:"erro,omitempty"`   // motivo da proposta não ter sido retornada
+}
+
+// consts associadas à consulta de várias propostas
+const (
+	chaveMaxConsultaPropostas  = "maxConsultaPropostas" // chave do máximo gravado pelas versões anteriores (ver iniciarConfiguracao)
+	padraoMaxConsultaPropostas = 100                    // máximo de Ids por consulta da configuração padrão
+)
+
+// consultarPropostas: função Query para consultar várias propostas de uma vez, recebendo os seguintes argumentos:
+// args[0..n]: Ids das propostas, no máximo o max_consulta_propostas da Configuracao
+// Retorna um JSON com o Id de cada proposta e o seu resultado ({"proposta":{...}} ou {"codigo":"...","erro":"..."}).
+// Cada proposta segue as mesmas regras de consultarProposta, e a falha em uma não impede o retorno das demais.
+// As mensagens de erro seguem o idioma da chamada.
+func (t *BoletoPropostaChaincode) consultarPropostas(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarPropostas...")
+
+	configuracao, err := obterConfiguracao(stub)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > configuracao.MaxConsultaPropostas {
+		return nil, novoErro(codigoArgumentosInvalidos, msgMaximoConsultaExcedido, len(args), configuracao.MaxConsultaPropostas)
+	}
+
+	idioma := idiomaChamada(stub)
+	resultados := make(map[string]ResultadoConsulta, len(args))
+	for _, idProposta := range args {
+		if _, repetido := resultados[idProposta]; repetido {
+			continue
+		}
+		proposta, err := t.lerPropostaConsulta(stub, idProposta)
+		if err != nil {
+			resultados[idProposta] = ResultadoConsulta{Codigo: converterErro(err).Codigo, Erro: converterErro(err).traduzido(idioma).Mensagem}
+			continue
+		}
+		resultados[idProposta] = ResultadoConsulta{Proposta: proposta}
+	}
+	return json.Marshal(resultados)
+}
+
+// configurarMaxConsultaPropostas: função Invoke para definir quantos Ids consultarPropostas aceita por chamada,
+// recebendo os seguintes argumentos:
+// args[0]: maximo. Quantidade máxima de Ids (padrão 100)
+// Equivale a atualizarConfiguracao com o campo max_consulta_propostas
+func (t *BoletoPropostaChaincode) configurarMaxConsultaPropostas(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("configurarMaxConsultaPropostas...")
+
+	maximo, err := lerPositivo("maximo", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nil, alterarConfiguracao(stub, func(c *Configuracao) {
+		c.MaxConsultaPropostas = maximo
+	})
+}
@@ -0,0 +1,88 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ============================================================================================================================
+// consultarPropostas
+// ============================================================================================================================
+
+func TestConsultarPropostas(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	stub.MockInvoke(cc, "registrarProposta", "p2", "222", "false", "false", "false", "500")
+
+	res, err := stub.MockQuery(cc, "consultarPropostas", "p1", "inexistente", "p2", "p1")
+	if err != nil {
+		t.Fatalf("consultarPropostas: %v", err)
+	}
+	var resultados map[string]ResultadoConsulta
+	if err := lerDados(res, &resultados); err != nil {
+		t.Fatalf("consultarPropostas retornou JSON inválido: %s", res)
+	}
+	if len(resultados) != 3 {
+		t.Errorf("resultados = %s", res)
+	}
+	if r := resultados["p1"]; r.Proposta == nil || r.Proposta.CpfPagador != "111" || r.Erro != "" {
+		t.Errorf("p1 = %+v", r)
+	}
+	if r := resultados["p2"]; r.Proposta == nil || r.Proposta.Valor != 500 || r.Proposta.Versao != 1 {
+		t.Errorf("p2 = %+v", r)
+	}
+	if r := resultados["inexistente"]; r.Proposta != nil || r.Codigo != codigoPropostaNaoEncontrada || r.Erro != "Proposta [inexistente] não existente." {
+		t.Errorf("inexistente = %+v", r)
+	}
+
+	// O resultado de cada Id é o mesmo de consultarProposta
+	if p := consultar(t, stub, cc, "p2"); p != *resultados["p2"].Proposta {
+		t.Errorf("consultarProposta = %+v; consultarPropostas = %+v", p, *resultados["p2"].Proposta)
+	}
+
+	stub.SimularFalha("GetRow", errors.New("falha simulada"))
+	res, err = stub.MockQuery(cc, "consultarPropostas", "p1")
+	if err != nil {
+		t.Fatalf("consultarPropostas com falha no GetRow: %v", err)
+	}
+	if !strings.Contains(string(res), "Erro ao obter Proposta [p1]") {
+		t.Errorf("resultado com falha no GetRow = %s", res)
+	}
+}
+
+func TestConsultarPropostasMaximo(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	_, err := stub.MockQuery(cc, "consultarPropostas")
+	verificarErro(t, "sem argumentos", err, "Esperado pelo menos 1")
+
+	ids := make([]string, padraoMaxConsultaPropostas+1)
+	for i := range ids {
+		ids[i] = "p"
+	}
+	_, err = stub.MockQuery(cc, "consultarPropostas", ids...)
+	verificarErro(t, "acima do padrão", err, "Quantidade de propostas [101] maior que o máximo por consulta [100].")
+	if _, err := stub.MockQuery(cc, "consultarPropostas", ids[1:]...); err != nil {
+		t.Errorf("consultarPropostas com o máximo padrão: %v", err)
+	}
+
+	if _, err := stub.MockInvoke(cc, "configurarMaxConsultaPropostas", "2"); err != nil {
+		t.Fatalf("configurarMaxConsultaPropostas: %v", err)
+	}
+	_, err = stub.MockQuery(cc, "consultarPropostas", "p1", "p2", "p3")
+	verificarErro(t, "acima do configurado", err, "máximo por consulta [2]")
+	if _, err := stub.MockQuery(cc, "consultarPropostas", "p1", "p2"); err != nil {
+		t.Errorf("consultarPropostas com o máximo configurado: %v", err)
+	}
+
+	_, err = stub.MockInvoke(cc, "configurarMaxConsultaPropostas", "0")
+	verificarErro(t, "máximo inválido", err, "Falha ao decodificar maximo")
+	_, err = stub.MockInvoke(cc, "configurarMaxConsultaPropostas")
+	verificarErro(t, "sem argumentos", err, "Esperado 1")
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "configurarMaxConsultaPropostas", "10")
+	verificarErro(t, "caller não administrador", err, "Falha ao verificar a identidade do administrador")
+}
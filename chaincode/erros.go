@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Definição da Struct Erro, erro do chaincode com um código estável do catálogo (ver codigosErro)
+type Erro struct {
+	Codigo   string          `json:"codigo"`
+	Mensagem string          `json:"mensagem"`
+	Detalhes json.RawMessage `json:"detalhes,omitempty"` // dados adicionais do erro (ex.: RelatorioLote)
+
+	chave string        // chave da mensagem no catálogo de mensagens, traduzida por responder
+	args  []interface{} // argumentos da mensagem
+}
+
+// Error: mensagem do erro
+func (e *Erro) Error() string {
+	return e.Mensagem
+}
+
+// Definição da Struct Resposta, envelope JSON retornado por todas as funções do chaincode
+type Resposta struct {
+	Sucesso bool            `json:"sucesso"`
+	Dados   json.RawMessage `json:"dados,omitempty"` // resultado da função, quando houver
+	Erro    *Erro           `json:"erro,omitempty"`
+}
+
+// códigos do catálogo de erros. Os códigos são estáveis; as mensagens podem mudar
+const (
+	codigoArgumentosInvalidos     = "ARGUMENTOS_INVALIDOS"
+	codigoFuncaoDesconhecida      = "FUNCAO_DESCONHECIDA"
+	codigoAcessoNegado            = "ACESSO_NEGADO"
+	codigoModuloNaoHabilitado     = "MODULO_NAO_HABILITADO"
+	codigoPropostaNaoEncontrada   = "PROPOSTA_NAO_ENCONTRADA"
+	codigoPropostaJaExistente     = "PROPOSTA_JA_EXISTENTE"
+	codigoConflitoVersao          = "CONFLITO_VERSAO"
+	codigoPropostaCancelada       = "PROPOSTA_CANCELADA"
+	codigoCancelamentoRejeitado   = "CANCELAMENTO_REJEITADO"
+	codigoLoteRejeitado           = "LOTE_REJEITADO"
+	codigoEventoNaoEncontrado     = "EVENTO_NAO_ENCONTRADO"
+	codigoOraculoNaoEncontrado    = "ORACULO_NAO_ENCONTRADO"
+	codigoOraculoJaRegistrado     = "ORACULO_JA_REGISTRADO"
+	codigoQuorumInvalido          = "QUORUM_INVALIDO"
+	codigoAssinaturaInvalida      = "ASSINATURA_INVALIDA"
+	codigoPagamentoRejeitado      = "PAGAMENTO_REJEITADO"
+	codigoPagamentoNaoConfirmado  = "PAGAMENTO_NAO_CONFIRMADO"
+	codigoIdempotenciaConflitante = "IDEMPOTENCIA_CONFLITANTE"
+	codigoEsquemaIncompativel     = "ESQUEMA_INCOMPATIVEL"
+	codigoChaincodePausado        = "CHAINCODE_PAUSADO"
+	codigoErroInterno             = "ERRO_INTERNO" // falhas do estado/ledger e erros não catalogados
+)
+
+// codigosErro: catálogo de erros, com a descrição de cada código
+var codigosErro = map[string]string{
+	codigoArgumentosInvalidos:     "Quantidade, tipo ou conteúdo dos argumentos inválido",
+	codigoFuncaoDesconhecida:      "Função não registrada para o tipo de chamada (invoke ou query)",
+	codigoAcessoNegado:            "O caller não tem o papel exigido pela função",
+	codigoModuloNaoHabilitado:     "A função depende de um módulo não habilitado no init",
+	codigoPropostaNaoEncontrada:   "Proposta não existente",
+	codigoPropostaJaExistente:     "Proposta já existente",
+	codigoConflitoVersao:          "A proposta ou a configuração foi alterada desde a versão informada",
+	codigoPropostaCancelada:       "A proposta foi cancelada e não aceita alterações",
+	codigoCancelamentoRejeitado:   "Cancelamento não aceito: proposta paga, parte não autorizada ou solicitação repetida",
+	codigoLoteRejeitado:           "Alguma proposta do lote foi rejeitada; os detalhes contêm o RelatorioLote",
+	codigoEventoNaoEncontrado:     "Evento não existente",
+	codigoOraculoNaoEncontrado:    "Oráculo não registrado",
+	codigoOraculoJaRegistrado:     "Oráculo já registrado",
+	codigoQuorumInvalido:          "Quorum maior que a quantidade de oráculos",
+	codigoAssinaturaInvalida:      "Assinatura do oráculo inválida",
+	codigoPagamentoRejeitado:      "Pagamento não aceito para a proposta",
+	codigoPagamentoNaoConfirmado:  "Pagamento da proposta não confirmado",
+	codigoIdempotenciaConflitante: "Chave de idempotência já utilizada com outros argumentos",
+	codigoEsquemaIncompativel:     "Esquema gravado posterior ao suportado pelo chaincode",
+	codigoChaincodePausado:        "Chaincode pausado pelo administrador: somente as consultas estão disponíveis",
+	codigoErroInterno:             "Falha interna do chaincode",
+}
+
+// novoErro: cria o erro com o código do catálogo e a mensagem, informada pela chave do catálogo de
+// mensagens (ver mensagens) ou por um formato sem tradução
+func novoErro(codigo string, chave string, args ...interface{}) error {
+	return &Erro{Codigo: codigo, Mensagem: traduzir(idiomaPadrao, chave, args...), chave: chave, args: args}
+}
+
+// traduzido: retorna o erro com a mensagem no idioma informado
+func (e *Erro) traduzido(idioma string) *Erro {
+	if e.chave == "" {
+		return e
+	}
+	traduzido := *e
+	traduzido.Mensagem = traduzir(idioma, e.chave, e.args...)
+	return &traduzido
+}
+
+// converterErro: retorna o Erro do catálogo. Erros sem código são tratados como codigoErroInterno
+func converterErro(err error) *Erro {
+	if e, ok := err.(*Erro); ok {
+		return e
+	}
+	return &Erro{Codigo: codigoErroInterno, Mensagem: err.Error()}
+}
+
+// responder: envolve o resultado da função no envelope Resposta, com a mensagem de erro no idioma informado.
+// Em caso de erro, o envelope é a mensagem do erro retornado, para que o peer continue descartando a transação
+func responder(idioma string, dados []byte, err error) ([]byte, error) {
+	resposta := Resposta{Sucesso: true, Dados: dados}
+	if err != nil {
+		resposta = Resposta{Erro: converterErro(err).traduzido(idioma)}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	errJSON := enc.Encode(resposta)
+	if errJSON != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarResposta, errJSON)
+	}
+	envelope := bytes.TrimRight(buf.Bytes(), "\n")
+
+	if err != nil {
+		return nil, errors.New(string(envelope))
+	}
+	return envelope, nil
+}
+
+// listarErros: função Query para consultar o catálogo de códigos de erro
+func (t *BoletoPropostaChaincode) listarErros(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("listarErros...")
+
+	codigosAsBytes, err := json.Marshal(codigosErro)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarCatalogoErros, err)
+	}
+	return codigosAsBytes, nil
+}
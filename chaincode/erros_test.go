@@ -0,0 +1,94 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"errors"
+	"testing"
+)
+
+// ============================================================================================================================
+// Envelope e códigos de erro
+// ============================================================================================================================
+
+func TestEnvelopeResposta(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	// Init e funções sem resultado retornam somente o sucesso
+	res, err := stub.MockInit(cc, "init", moduloAutenticacao, moduloNotificacao)
+	if err != nil || string(res) != `{"sucesso":true}` {
+		t.Errorf("init = %s, %v", res, err)
+	}
+	res, err = stub.MockInvoke(cc, "configurarMaxConsultaPropostas", "10")
+	if err != nil || string(res) != `{"sucesso":true}` {
+		t.Errorf("configurarMaxConsultaPropostas = %s, %v", res, err)
+	}
+
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	var p Proposta
+	res, err = stub.MockQuery(cc, "consultarProposta", "p1")
+	if err != nil || lerDados(res, &p) != nil || p.ID != "p1" {
+		t.Errorf("consultarProposta = %s, %v", res, err)
+	}
+
+	// O erro é retornado no envelope, sem escapar os caracteres HTML
+	_, err = stub.MockQuery(cc, "consultarProposta", "<p9>")
+	if err == nil || err.Error() != `{"sucesso":false,"erro":{"codigo":"PROPOSTA_NAO_ENCONTRADA","mensagem":"Proposta [<p9>] não existente."}}` {
+		t.Errorf("erro = %v", err)
+	}
+}
+
+func TestCodigosErro(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+
+	_, err := stub.MockInvoke(cc, "naoExiste")
+	verificarCodigo(t, "função desconhecida", err, codigoFuncaoDesconhecida)
+	_, err = stub.MockQuery(cc, "consultarProposta")
+	verificarCodigo(t, "sem argumentos", err, codigoArgumentosInvalidos)
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "x", "false", "false")
+	verificarCodigo(t, "booleano inválido", err, codigoArgumentosInvalidos)
+	_, err = stub.MockInit(cc, "init", "x")
+	verificarCodigo(t, "módulo desconhecido", err, codigoArgumentosInvalidos)
+	_, err = stub.MockQuery(cc, "consultarProposta", "p9")
+	verificarCodigo(t, "proposta inexistente", err, codigoPropostaNaoEncontrada)
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	verificarCodigo(t, "proposta existente", err, codigoPropostaJaExistente)
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "0", "7")
+	verificarCodigo(t, "conflito de versão", err, codigoConflitoVersao)
+	_, err = stub.MockInvoke(cc, "confirmarEntrega", "99", "bc-desafio", statusEntregue)
+	verificarCodigo(t, "evento inexistente", err, codigoEventoNaoEncontrado)
+	_, err = stub.MockQuery(cc, "consultarPagamento", "p1")
+	verificarCodigo(t, "pagamento não confirmado", err, codigoPagamentoNaoConfirmado)
+	_, err = stub.MockInvoke(cc, "removerOraculo", "banco-9")
+	verificarCodigo(t, "oráculo inexistente", err, codigoOraculoNaoEncontrado)
+
+	stub.SimularFalha("GetRow", errors.New("falha simulada"))
+	_, err = stub.MockQuery(cc, "consultarProposta", "p1")
+	verificarCodigo(t, "falha no GetRow", err, codigoErroInterno)
+	stub.SimularFalha("GetRow", nil)
+
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false")
+	verificarCodigo(t, "caller não administrador", err, codigoAcessoNegado)
+
+	stub, cc = iniciarChaincode(t)
+	_, err = stub.MockQuery(cc, "consultarEventos", "0")
+	verificarCodigo(t, "módulo não habilitado", err, codigoModuloNaoHabilitado)
+}
+
+func TestListarErros(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	res, err := stub.MockQuery(cc, "listarErros")
+	if err != nil {
+		t.Fatalf("listarErros: %v", err)
+	}
+	var codigos map[string]string
+	if err := lerDados(res, &codigos); err != nil {
+		t.Fatalf("listarErros retornou JSON inválido: %s", res)
+	}
+	if len(codigos) != len(codigosErro) || codigos[codigoPropostaNaoEncontrada] == "" || codigos[codigoErroInterno] == "" {
+		t.Errorf("catálogo = %s", res)
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Versão do esquema e migrações
+// ============================================================================================================================
+
+// Definição da Struct VersaoEsquema, retornada por consultarVersaoEsquema
+type VersaoEsquema struct {
+	Versao        int    `json:"versao"`        // versão gravada no estado
+	VersaoAtual   int    `json:"versao_atual"`  // versão esperada por este chaincode
+	Armazenamento string `json:"armazenamento"` // forma de armazenamento das propostas
+}
+
+// Migracao - atualização dos dados existentes para a Versao do esquema
+type Migracao struct {
+	Versao    int
+	Descricao string
+	Executar  func(stub shim.ChaincodeStubInterface) error
+}
+
+// consts associadas à versão do esquema
+const (
+	chaveVersaoEsquema = "versaoEsquema" // chave do estado com a versão do esquema
+	argMigrar          = "migrar"        // primeiro argumento do Init para migrar os dados em vez de excluí-los
+)
+
+// migracoes: migrações registradas, em ordem de versão. A versão 1 é a tabela 'Proposta'
+// criada pelas primeiras versões do chaincode, antes do registro da versão no estado
+var migracoes = []Migracao{
+	{2, "Renomeia a coluna chave da tabela Proposta de \"id\" para \"Id\"", migrarChaveProposta},
+	{3, "Inclui a coluna valor na tabela Proposta", migrarColunaValor},
+	{4, "Cria os índices da tabela Proposta", migrarIndicesProposta},
+	{5, "Inclui a versão das propostas, iniciada em 1 nas propostas existentes", migrarVersaoProposta},
+	{6, "Inclui a coluna cancelada na tabela Proposta", migrarColunaCancelada},
+}
+
+// versaoEsquemaAtual: versão do esquema após todas as migrações
+var versaoEsquemaAtual = migracoes[len(migracoes)-1].Versao
+
+// obterVersaoEsquema: versão gravada no estado. Sem versão gravada, retorna 1 se a tabela
+// 'Proposta' existir (dados das primeiras versões) ou 0 se não houver dados
+func obterVersaoEsquema(stub shim.ChaincodeStubInterface) (int, error) {
+	versaoAsBytes, err := stub.GetState(chaveVersaoEsquema)
+	if err != nil {
+		return 0, novoErro(codigoErroInterno, msgFalhaObterVersaoEsquema, err)
+	}
+	if len(versaoAsBytes) == 0 {
+		// GetTable retorna erro quando a tabela não existe
+		if tb, err := stub.GetTable(nomeTabelaProposta); err == nil && tb != nil {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	versao, err := strconv.Atoi(string(versaoAsBytes))
+	if err != nil {
+		return 0, novoErro(codigoErroInterno, msgVersaoEsquemaInvalida, versaoAsBytes)
+	}
+	return versao, nil
+}
+
+// gravarVersaoEsquema: registra a versão do esquema no estado
+func gravarVersaoEsquema(stub shim.ChaincodeStubInterface, versao int) error {
+	err := stub.PutState(chaveVersaoEsquema, []byte(strconv.Itoa(versao)))
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaRegistrarVersaoEsquema, err)
+	}
+	return nil
+}
+
+// migrarEsquema: executa as migrações pendentes sobre os dados existentes e, se a forma de
+// armazenamento foi alterada, move as propostas do armazenamento anterior para o novo
+func migrarEsquema(stub shim.ChaincodeStubInterface, de, para string) error {
+	versao, err := obterVersaoEsquema(stub)
+	if err != nil {
+		return err
+	}
+	if versao > versaoEsquemaAtual {
+		return novoErro(codigoEsquemaIncompativel, msgEsquemaPosterior, versao, versaoEsquemaAtual)
+	}
+
+	if versao == 0 {
+		// Não há dados a migrar
+		logChamada(stub).Info("Nenhum dado existente. Criando o armazenamento...")
+		repositorio, err := obterRepositorio(para)
+		if err != nil {
+			return err
+		}
+		err = repositorio.Criar(stub)
+		if err != nil {
+			return err
+		}
+		return gravarVersaoEsquema(stub, versaoEsquemaAtual)
+	}
+
+	for _, m := range migracoes {
+		if m.Versao <= versao {
+			continue
+		}
+		logChamada(stub).Info("Migrando o esquema", "versao", m.Versao, "descricao", m.Descricao)
+		err = m.Executar(stub)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaMigracao, m.Versao, err)
+		}
+		err = gravarVersaoEsquema(stub, m.Versao)
+		if err != nil {
+			return err
+		}
+	}
+
+	return migrarArmazenamento(stub, de, para)
+}
+
+// migrarArmazenamento: move todas as propostas entre as formas de armazenamento
+func migrarArmazenamento(stub shim.ChaincodeStubInterface, de, para string) error {
+	origem, err := obterRepositorio(de)
+	if err != nil {
+		return err
+	}
+	destino, err := obterRepositorio(para)
+	if err != nil {
+		return err
+	}
+	if normalizarArmazenamento(de) == normalizarArmazenamento(para) {
+		return nil
+	}
+
+	logChamada(stub).Info("Movendo as propostas...", "de", de, "para", para)
+	propostas, err := origem.ListarTodas(stub)
+	if err != nil {
+		return err
+	}
+	err = destino.Excluir(stub)
+	if err != nil {
+		return err
+	}
+	err = destino.Criar(stub)
+	if err != nil {
+		return err
+	}
+	for _, proposta := range propostas {
+		_, err = destino.Gravar(stub, proposta)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaMoverProposta, proposta.ID, err)
+		}
+	}
+	logChamada(stub).Info("Propostas movidas", "quantidade", len(propostas))
+	return origem.Excluir(stub)
+}
+
+// ============================================================================================================================
+// Migrações
+// ============================================================================================================================
+
+// migrarChaveProposta: as primeiras versões criavam a tabela com a coluna chave "id"
+func migrarChaveProposta(stub shim.ChaincodeStubInterface) error {
+	tb, err := stub.GetTable(nomeTabelaProposta)
+	if err != nil || tb == nil || tb.ColumnDefinitions[0].Name == mapeamentoProposta.chaves()[0].coluna {
+		return nil
+	}
+
+	definicoes := copiarDefinicoes(tb.ColumnDefinitions)
+	definicoes[0].Name = mapeamentoProposta.chaves()[0].coluna
+	return recriarTabela(stub, nomeTabelaProposta, definicoes, func(row shim.Row) shim.Row {
+		return row
+	})
+}
+
+// migrarColunaValor: inclui a coluna valor, com valor zero nas propostas existentes
+func migrarColunaValor(stub shim.ChaincodeStubInterface) error {
+	return incluirColuna(stub, nomeTabelaProposta, &shim.ColumnDefinition{Name: "valor", Type: shim.ColumnDefinition_INT64},
+		&shim.Column{Value: &shim.Column_Int64{Int64: 0}})
+}
+
+// migrarIndicesProposta: cria as tabelas dos índices e registra as propostas existentes
+func migrarIndicesProposta(stub shim.ChaincodeStubInterface) error {
+	if tb, err := stub.GetTable(nomeTabelaProposta); err != nil || tb == nil {
+		return nil
+	}
+	r := repositorioTabela{mapeamentoProposta}
+	propostas, err := r.ListarTodas(stub)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range r.m.indices() {
+		if tb, err := stub.GetTable(r.tabelaIndice(c)); err == nil && tb != nil {
+			continue
+		}
+		err = r.criarIndice(stub, c)
+		if err != nil {
+			return err
+		}
+		for _, proposta := range propostas {
+			err = r.gravarIndice(stub, c, r.m.texto(proposta, c), proposta.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrarVersaoProposta: inclui a coluna versao na tabela e a versão nas propostas gravadas em JSON
+func migrarVersaoProposta(stub shim.ChaincodeStubInterface) error {
+	err := incluirColuna(stub, nomeTabelaProposta, &shim.ColumnDefinition{Name: "versao", Type: shim.ColumnDefinition_UINT64},
+		&shim.Column{Value: &shim.Column_Uint64{Uint64: 1}})
+	if err != nil {
+		return err
+	}
+
+	r := repositorioJSON{mapeamentoProposta}
+	propostas, err := r.ListarTodas(stub)
+	if err != nil {
+		return err
+	}
+	for _, proposta := range propostas {
+		if proposta.Versao != 0 {
+			continue
+		}
+		proposta.Versao = 1
+		err = r.gravar(stub, proposta)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrarColunaCancelada: inclui a coluna cancelada, com false nas propostas existentes.
+// As propostas gravadas em JSON não precisam ser alteradas (o campo ausente é false)
+func migrarColunaCancelada(stub shim.ChaincodeStubInterface) error {
+	return incluirColuna(stub, nomeTabelaProposta, &shim.ColumnDefinition{Name: "cancelada", Type: shim.ColumnDefinition_BOOL},
+		&shim.Column{Value: &shim.Column_Bool{Bool: false}})
+}
+
+// incluirColuna: inclui a coluna no final da tabela, com o valor informado nas linhas existentes.
+// Não faz nada se a tabela não existir ou já tiver a coluna
+func incluirColuna(stub shim.ChaincodeStubInterface, tabela string, definicao *shim.ColumnDefinition, valor *shim.Column) error {
+	tb, err := stub.GetTable(tabela)
+	if err != nil || tb == nil {
+		return nil
+	}
+	for _, d := range tb.ColumnDefinitions {
+		if d.Name == definicao.Name {
+			return nil
+		}
+	}
+
+	definicoes := append(copiarDefinicoes(tb.ColumnDefinitions), definicao)
+	return recriarTabela(stub, tabela, definicoes, func(row shim.Row) shim.Row {
+		row.Columns = append(row.Columns, valor)
+		return row
+	})
+}
+
+// recriarTabela: recria a tabela com as novas definições de colunas, gravando as linhas
+// existentes após a conversão. A API de tabelas não permite alterar uma tabela existente
+func recriarTabela(stub shim.ChaincodeStubInterface, tabela string, definicoes []*shim.ColumnDefinition, converter func(shim.Row) shim.Row) error {
+	rowChannel, err := stub.GetRows(tabela, []shim.Column{})
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaLerTabela, tabela, err)
+	}
+	var rows []shim.Row
+	for row := range rowChannel {
+		rows = append(rows, converter(row))
+	}
+
+	err = stub.DeleteTable(tabela)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaExcluirTabela, tabela, err)
+	}
+	err = stub.CreateTable(tabela, definicoes)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCriarTabela, tabela, err)
+	}
+	for _, row := range rows {
+		_, err = stub.InsertRow(tabela, row)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaGravarLinha, tabela, err)
+		}
+	}
+	return nil
+}
+
+// copiarDefinicoes: cópia das definições de colunas, para alteração sem modificar a original
+func copiarDefinicoes(definicoes []*shim.ColumnDefinition) []*shim.ColumnDefinition {
+	var copia []*shim.ColumnDefinition
+	for _, d := range definicoes {
+		c := *d
+		copia = append(copia, &c)
+	}
+	return copia
+}
+
+// ============================================================================================================================
+// Query Functions
+// ============================================================================================================================
+
+// consultarVersaoEsquema: consulta a versão do esquema gravada e a versão esperada pelo chaincode.
+// Não recebe argumentos
+func (t *BoletoPropostaChaincode) consultarVersaoEsquema(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	versao, err := obterVersaoEsquema(stub)
+	if err != nil {
+		return nil, err
+	}
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(VersaoEsquema{
+		Versao:        versao,
+		VersaoAtual:   versaoEsquemaAtual,
+		Armazenamento: normalizarArmazenamento(modulos.Armazenamento),
+	})
+}
@@ -0,0 +1,228 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Estorno de pagamentos
+// ============================================================================================================================
+
+// Definição da Struct Estorno, registro de um pagamento estornado. Os estornos de uma proposta são
+// mantidos em ordem, como histórico
+type Estorno struct {
+	CodigoMotivo string                `json:"codigo_motivo"`        // ver motivosEstorno
+	IDOraculo    string                `json:"id_oraculo,omitempty"` // oráculo que solicitou o estorno; vazio quando solicitado pelo administrador
+	Pagamento    *ConfirmacaoPagamento `json:"pagamento,omitempty"`  // confirmação dos oráculos do pagamento estornado
+	Versao       uint64                `json:"versao"`               // versão da proposta após o estorno
+	TxID         string                `json:"tx_id"`
+}
+
+// Definição da Struct SolicitacaoEstorno, assinada pelo oráculo que solicita o estorno. A versão da
+// proposta impede que a mesma assinatura seja reutilizada após um novo pagamento
+type SolicitacaoEstorno struct {
+	IDOraculo    string `json:"id_oraculo"`
+	IDProposta   string `json:"id_proposta"`
+	CodigoMotivo string `json:"codigo_motivo"`
+	Versao       uint64 `json:"versao"` // versão atual da proposta
+}
+
+// consts associadas aos estornos
+const (
+	prefixoChaveEstornos = "estornos_"
+)
+
+// motivosEstorno: códigos de motivo aceitos por estornarPagamento
+var motivosEstorno = []string{
+	"pagamento_duplicado",
+	"pagamento_indevido",
+	"valor_divergente",
+	"fraude",
+	"erro_operacional",
+}
+
+// estornarPagamento: função Invoke para estornar o pagamento de uma proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+// args[1]: codigoMotivo. Um dos motivosEstorno
+// args[2]: idOraculo (opcional). Oráculo que atestou o pagamento; sem ele, somente o administrador estorna
+// args[3]: assinatura (obrigatória com o idOraculo). Assinatura ECDSA (DER, em base64) do JSON canônico da SolicitacaoEstorno
+// O boleto volta a não pago, e a confirmação dos oráculos é movida para o registro do estorno. As autenticações
+// bancárias do pagamento estornado continuam utilizadas e não confirmam outro pagamento
+func (t *BoletoPropostaChaincode) estornarPagamento(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("estornarPagamento...")
+	idProposta, codigoMotivo := args[0], args[1]
+
+	if !motivoEstornoValido(codigoMotivo) {
+		return nil, novoErro(codigoArgumentosInvalidos, msgMotivoEstornoInvalido, codigoMotivo, strings.Join(motivosEstorno, ", "))
+	}
+	if len(args) == 3 {
+		return nil, novoErro(codigoArgumentosInvalidos, msgAssinaturaOraculoAusente)
+	}
+	anterior, err := t.obterProposta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+	if anterior == nil {
+		return nil, novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, idProposta)
+	}
+	if !anterior.BoletoPago {
+		return nil, novoErro(codigoPagamentoNaoConfirmado, msgPagamentoNaoConfirmado, idProposta)
+	}
+	confirmacao, err := obterConfirmacaoPagamento(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+
+	estorno := Estorno{CodigoMotivo: codigoMotivo, Pagamento: confirmacao, TxID: stub.GetTxID()}
+	if len(args) == 4 {
+		// O oráculo deve ter atestado o pagamento estornado
+		estorno.IDOraculo = args[2]
+		solicitacaoAsBytes, err := json.Marshal(SolicitacaoEstorno{
+			IDOraculo:    estorno.IDOraculo,
+			IDProposta:   idProposta,
+			CodigoMotivo: codigoMotivo,
+			Versao:       anterior.Versao,
+		})
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "SolicitacaoEstorno", err)
+		}
+		err = t.verificarAssinaturaOraculo(stub, estorno.IDOraculo, solicitacaoAsBytes, args[3], msgAssinaturaEstornoInvalida)
+		if err != nil {
+			return nil, err
+		}
+		if !confirmacao.atestadoPor(estorno.IDOraculo) {
+			return nil, novoErro(codigoAcessoNegado, msgOraculoNaoAtestou, estorno.IDOraculo, idProposta)
+		}
+	} else {
+		err = t.verificarAdmin(stub)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nova := *anterior
+	nova.BoletoPago = false
+	nova, err = t.atualizarProposta(stub, nova, anterior.Versao)
+	if err != nil {
+		return nil, err
+	}
+	estorno.Versao = nova.Versao
+
+	estornos, err := obterEstornos(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+	estornosAsBytes, err := json.Marshal(append(estornos, estorno))
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "estornos", err)
+	}
+	err = stub.PutState(prefixoChaveEstornos+idProposta, estornosAsBytes)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaRegistrarEstorno, idProposta, err)
+	}
+	if confirmacao != nil {
+		err = stub.DelState(prefixoChavePagamento + idProposta)
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaRemoverPagamento, idProposta, err)
+		}
+	}
+
+	err = t.emitirEventos(stub, eventosTransicao(anterior, nova), nova)
+	if err != nil {
+		return nil, err
+	}
+
+	logChamada(stub).Info("Pagamento estornado", "id_proposta", idProposta, "codigo_motivo", codigoMotivo, "id_oraculo", estorno.IDOraculo)
+	return json.Marshal(estorno)
+}
+
+// consultarEstornos: função Query para consultar os estornos de uma proposta, do mais antigo ao mais recente, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarEstornos(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarEstornos...")
+	estornos, err := obterEstornos(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(estornos)
+}
+
+// obterEstornos: retorna os estornos registrados para a proposta
+func obterEstornos(stub shim.ChaincodeStubInterface, idProposta string) ([]Estorno, error) {
+	estornos := []Estorno{}
+
+	estornosAsBytes, err := stub.GetState(prefixoChaveEstornos + idProposta)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterEstornos, idProposta, err)
+	}
+	if len(estornosAsBytes) == 0 {
+		return estornos, nil
+	}
+	err = json.Unmarshal(estornosAsBytes, &estornos)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "estornos", err)
+	}
+	return estornos, nil
+}
+
+// obterConfirmacaoPagamento: retorna a confirmação dos oráculos do pagamento da proposta, ou nil
+// se o pagamento não foi confirmado por oráculos
+func obterConfirmacaoPagamento(stub shim.ChaincodeStubInterface, idProposta string) (*ConfirmacaoPagamento, error) {
+	confirmacaoAsBytes, err := stub.GetState(prefixoChavePagamento + idProposta)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterPagamento, idProposta, err)
+	}
+	if len(confirmacaoAsBytes) == 0 {
+		return nil, nil
+	}
+	var confirmacao ConfirmacaoPagamento
+	err = json.Unmarshal(confirmacaoAsBytes, &confirmacao)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "ConfirmacaoPagamento", err)
+	}
+	return &confirmacao, nil
+}
+
+// atestadoPor: verifica se o oráculo está entre os que confirmaram o pagamento
+func (c *ConfirmacaoPagamento) atestadoPor(idOraculo string) bool {
+	if c == nil {
+		return false
+	}
+	for _, a := range c.Atestados {
+		if a.Atestado.IDOraculo == idOraculo {
+			return true
+		}
+	}
+	return false
+}
+
+// motivoEstornoValido: verifica se o código de motivo está entre os motivosEstorno
+func motivoEstornoValido(codigoMotivo string) bool {
+	for _, m := range motivosEstorno {
+		if m == codigoMotivo {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"strings"
+	"testing"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/canonico"
+)
+
+// solicitarEstorno: argumentos de estornarPagamento assinados pelo oráculo
+func (o oraculoTeste) solicitarEstorno(t *testing.T, idProposta, codigoMotivo string, versao uint64) []string {
+	conteudo, _ := canonico.Codificar(SolicitacaoEstorno{o.id, idProposta, codigoMotivo, versao})
+	return []string{idProposta, codigoMotivo, o.id, o.assinar(t, conteudo)}
+}
+
+func TestEstornarPagamentoAdministrador(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "true", "15000")
+
+	// O pagamento não é desfeito pela atualização da proposta
+	_, err := stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000", "1")
+	verificarErro(t, "atualização", err, "desfeito somente com estornarPagamento")
+
+	casos := []struct {
+		nome, trecho string
+		args         []string
+	}{
+		{"motivo inválido", "Motivo de estorno [engano] inválido", []string{"p1", "engano"}},
+		{"oráculo sem assinatura", "Assinatura do oráculo não informada", []string{"p1", "fraude", "banco-1"}},
+		{"proposta inexistente", "Proposta [p9] não existente.", []string{"p9", "fraude"}},
+	}
+	for _, c := range casos {
+		_, err := stub.MockInvoke(cc, "estornarPagamento", c.args...)
+		verificarErro(t, c.nome, err, c.trecho)
+	}
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "estornarPagamento", "p1", "pagamento_indevido")
+	verificarErro(t, "caller não administrador", err, "Falha ao verificar a identidade do administrador")
+	stub.CallerMetadata = []byte(adminTeste)
+
+	if _, err := stub.MockInvoke(cc, "estornarPagamento", "p1", "pagamento_indevido"); err != nil {
+		t.Fatalf("estornarPagamento: %v", err)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != "PropostaAtualizada,PagamentoEstornado" {
+		t.Errorf("eventos = %v", tipos)
+	}
+	if p := consultar(t, stub, cc, "p1"); p.BoletoPago || p.Versao != 2 {
+		t.Errorf("proposta estornada = %+v", p)
+	}
+	_, err = stub.MockInvoke(cc, "estornarPagamento", "p1", "pagamento_indevido")
+	if err == nil || lerErro(err).Codigo != codigoPagamentoNaoConfirmado {
+		t.Errorf("estorno repetido = %v; esperado %s", err, codigoPagamentoNaoConfirmado)
+	}
+
+	// O estorno é mantido no histórico, e a proposta volta a aceitar o pagamento
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "true", "15000", "2")
+	stub.MockInvoke(cc, "estornarPagamento", "p1", "pagamento_duplicado")
+	res, err := stub.MockQuery(cc, "consultarEstornos", "p1")
+	var estornos []Estorno
+	if err != nil || lerDados(res, &estornos) != nil || len(estornos) != 2 ||
+		estornos[0].CodigoMotivo != "pagamento_indevido" || estornos[1].Versao != 4 || estornos[1].IDOraculo != "" {
+		t.Errorf("consultarEstornos = %s, %v", res, err)
+	}
+}
+
+func TestEstornarPagamentoOraculo(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	oraculo, outro := novoOraculo(t, "banco-1"), novoOraculo(t, "banco-2")
+	stub.MockInvoke(cc, "registrarOraculo", oraculo.id, oraculo.pem(t))
+	stub.MockInvoke(cc, "registrarOraculo", outro.id, outro.pem(t))
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+	atestado := AtestadoPagamento{IDProposta: "p1", ValorPago: 15000, DataPagamento: "2016-11-30", CodigoAutenticacao: "AUT1"}
+	if _, err := stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, atestado)...); err != nil {
+		t.Fatalf("confirmarPagamentoOracle: %v", err)
+	}
+
+	// Somente um oráculo que atestou o pagamento, assinando a versão atual da proposta
+	stub.CallerMetadata = []byte("outro")
+	_, err := stub.MockInvoke(cc, "estornarPagamento", outro.solicitarEstorno(t, "p1", "fraude", 2)...)
+	verificarErro(t, "oráculo que não atestou", err, "Oráculo [banco-2] não atestou o pagamento da Proposta [p1].")
+	_, err = stub.MockInvoke(cc, "estornarPagamento", append(outro.solicitarEstorno(t, "p1", "fraude", 2), "idioma=en")...)
+	verificarErro(t, "oráculo que não atestou em inglês", err, "Oracle [banco-2] did not attest the payment of Proposal [p1].")
+	_, err = stub.MockInvoke(cc, "estornarPagamento", oraculo.solicitarEstorno(t, "p1", "fraude", 1)...)
+	verificarErro(t, "versão anterior", err, "Assinatura do estorno inválida")
+
+	if _, err := stub.MockInvoke(cc, "estornarPagamento", oraculo.solicitarEstorno(t, "p1", "fraude", 2)...); err != nil {
+		t.Fatalf("estornarPagamento: %v", err)
+	}
+	if p := consultar(t, stub, cc, "p1"); p.BoletoPago || p.Versao != 3 {
+		t.Errorf("proposta estornada = %+v", p)
+	}
+
+	// A confirmação dos oráculos é movida para o estorno
+	_, err = stub.MockQuery(cc, "consultarPagamento", "p1")
+	verificarErro(t, "pagamento estornado", err, "Pagamento da Proposta [p1] não confirmado.")
+	res, _ := stub.MockQuery(cc, "consultarEstornos", "p1")
+	var estornos []Estorno
+	if lerDados(res, &estornos) != nil || len(estornos) != 1 || estornos[0].IDOraculo != "banco-1" ||
+		estornos[0].Pagamento == nil || len(estornos[0].Pagamento.Atestados) != 1 {
+		t.Errorf("consultarEstornos = %s", res)
+	}
+
+	// A autenticação bancária do pagamento estornado não é reutilizada
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, atestado)...)
+	verificarErro(t, "autenticação estornada", err, "já utilizado")
+}
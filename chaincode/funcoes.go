@@ -0,0 +1,617 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Definição da Struct Funcao, função do chaincode registrada em funcoesRegistradas e publicada por listarFuncoes
+type Funcao struct {
+	Nome        string      `json:"nome"`
+	Tipo        string      `json:"tipo"` // invoke ou query
+	Descricao   string      `json:"descricao"`
+	Argumentos  []Argumento `json:"argumentos"`
+	EsquemaJSON string      `json:"esquema_json,omitempty"` // aceita também um único argumento JSON, validado por este esquema
+	Observacao  string      `json:"observacao,omitempty"`   // chave da mensagem acrescentada ao erro de quantidade de argumentos
+	Papel       string      `json:"papel"`                  // papel exigido do caller (ver papelAdministrador)
+	Pausada     bool        `json:"pausada,omitempty"`      // Invoke disponível com o chaincode pausado (ver pausar)
+	executar    func(t *BoletoPropostaChaincode, stub shim.ChaincodeStubInterface, args []string) ([]byte, error)
+}
+
+// Definição da Struct Argumento, argumento posicional de uma Funcao
+type Argumento struct {
+	Nome        string `json:"nome"`
+	Tipo        string `json:"tipo"`
+	Obrigatorio bool   `json:"obrigatorio"`
+	Repetido    bool   `json:"repetido,omitempty"` // último argumento, aceito uma ou mais vezes
+}
+
+// tipos de função
+const (
+	tipoInvoke = "invoke"
+	tipoQuery  = "query"
+)
+
+// papéis exigidos do caller
+const (
+	papelQualquer      = "qualquer"
+	papelAdministrador = "administrador" // com o módulo autenticacao, somente o administrador (ver verificarAdmin)
+	papelOraculo       = "oraculo"       // oráculo registrado, identificado pela assinatura do atestado
+	papelParte         = "parte"         // com o módulo autenticacao, o pagador ou o beneficiário da proposta (ver verificarParte)
+
+	// o administrador ou, com a assinatura informada, um oráculo que atestou o pagamento (ver estornarPagamento)
+	papelAdministradorOuOraculo = "administrador_ou_oraculo"
+)
+
+// tipos de argumento. Os argumentos são validados por validarArgumentos antes da execução da função
+const (
+	tipoArgTexto    = "texto"
+	tipoArgBooleano = "booleano" // true ou false
+	tipoArgInteiro  = "inteiro"  // inteiro de 64 bits não negativo
+	tipoArgNatural  = "natural"  // inteiro sem sinal de 64 bits (sequências e versões)
+	tipoArgPositivo = "positivo" // inteiro maior que zero (quantidades configuráveis)
+	tipoArgJSON     = "json"     // documento JSON, validado pela própria função
+)
+
+// ============================================================================================================================
+// Registro de funções
+// ============================================================================================================================
+
+// funcoesRegistradas: funções do chaincode, na ordem publicada por listarFuncoes
+func funcoesRegistradas() []Funcao {
+	return []Funcao{
+		// Invoke
+		{
+			Nome:      "init",
+			Tipo:      tipoInvoke,
+			Descricao: "Reinicia o estado do chaincode, com os mesmos argumentos do Init. Com \"migrar\" como primeiro argumento os dados são mantidos e migrados",
+			Argumentos: []Argumento{
+				{Nome: "modulos", Tipo: tipoArgTexto, Repetido: true},
+			},
+			Papel: papelAdministrador, // com o módulo autenticacao habilitado, somente o administrador reinicia o estado
+			executar: func(t *BoletoPropostaChaincode, stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+				return t.iniciar(stub, args)
+			},
+		},
+		{
+			Nome:      "registrarProposta",
+			Tipo:      tipoInvoke,
+			Descricao: "Registra uma nova proposta ou atualiza uma já existente. A atualização exige a versão esperada",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "cpfPagador", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "pagadorAceitou", Tipo: tipoArgBooleano, Obrigatorio: true},
+				{Nome: "beneficiarioAceitou", Tipo: tipoArgBooleano, Obrigatorio: true},
+				{Nome: "boletoPago", Tipo: tipoArgBooleano, Obrigatorio: true},
+				{Nome: "valor", Tipo: tipoArgInteiro},
+				{Nome: "versaoEsperada", Tipo: tipoArgNatural},
+			},
+			EsquemaJSON: "registrarProposta.schema.json",
+			Papel:       papelAdministrador,
+			executar:    (*BoletoPropostaChaincode).registrarProposta,
+		},
+		{
+			Nome:      "registrarPropostasEmLote",
+			Tipo:      tipoInvoke,
+			Descricao: "Registra ou atualiza uma lista de propostas em uma única transação, todas ou nenhuma",
+			Argumentos: []Argumento{
+				{Nome: "registrosJSON", Tipo: tipoArgJSON, Obrigatorio: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).registrarPropostasEmLote,
+		},
+		{
+			Nome:      "criarProposta",
+			Tipo:      tipoInvoke,
+			Descricao: "Registra uma nova proposta com o Id gerado pelo chaincode, retornado ao caller",
+			Argumentos: []Argumento{
+				{Nome: "cpfPagador", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "pagadorAceitou", Tipo: tipoArgBooleano, Obrigatorio: true},
+				{Nome: "beneficiarioAceitou", Tipo: tipoArgBooleano, Obrigatorio: true},
+				{Nome: "boletoPago", Tipo: tipoArgBooleano, Obrigatorio: true},
+				{Nome: "valor", Tipo: tipoArgInteiro},
+			},
+			Observacao: msgIdGeradoPeloChaincode,
+			Papel:      papelAdministrador,
+			executar:   (*BoletoPropostaChaincode).criarProposta,
+		},
+		{
+			Nome:      "confirmarEntrega",
+			Tipo:      tipoInvoke,
+			Descricao: "Registra o resultado da entrega de um evento (módulo notificacao)",
+			Argumentos: []Argumento{
+				{Nome: "idEvento", Tipo: tipoArgNatural, Obrigatorio: true},
+				{Nome: "assinante", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "status", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).confirmarEntrega,
+		},
+		{
+			Nome:      "registrarOraculo",
+			Tipo:      tipoInvoke,
+			Descricao: "Registra um oráculo de pagamento com a sua chave pública ECDSA em PEM",
+			Argumentos: []Argumento{
+				{Nome: "idOraculo", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "chavePublica", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).registrarOraculo,
+		},
+		{
+			Nome:      "removerOraculo",
+			Tipo:      tipoInvoke,
+			Descricao: "Remove um oráculo de pagamento",
+			Argumentos: []Argumento{
+				{Nome: "idOraculo", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).removerOraculo,
+		},
+		{
+			Nome:      "configurarQuorumOraculos",
+			Tipo:      tipoInvoke,
+			Descricao: "Define quantos oráculos distintos precisam atestar um pagamento",
+			Argumentos: []Argumento{
+				{Nome: "quorum", Tipo: tipoArgPositivo, Obrigatorio: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).configurarQuorumOraculos,
+		},
+		{
+			Nome:      "confirmarPagamentoOracle",
+			Tipo:      tipoInvoke,
+			Descricao: "Registra o atestado de pagamento de um oráculo. O boleto é marcado como pago quando o quorum de oráculos envia atestados coincidentes",
+			Argumentos: []Argumento{
+				{Nome: "atestado", Tipo: tipoArgJSON, Obrigatorio: true},
+				{Nome: "assinatura", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelOraculo,
+			executar: (*BoletoPropostaChaincode).confirmarPagamentoOracle,
+		},
+		{
+			Nome:      "resolverDisputaPagamento",
+			Tipo:      tipoInvoke,
+			Descricao: "Descarta os atestados pendentes de uma proposta, de todos os oráculos ou apenas dos informados",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "idOraculo", Tipo: tipoArgTexto, Repetido: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).resolverDisputaPagamento,
+		},
+		{
+			Nome:      "cancelarProposta",
+			Tipo:      tipoInvoke,
+			Descricao: "Solicita o cancelamento de uma proposta não paga. Depois do aceite do pagador, a proposta é cancelada quando as duas partes solicitam",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "parte", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "motivo", Tipo: tipoArgTexto},
+			},
+			Papel:    papelParte,
+			executar: (*BoletoPropostaChaincode).cancelarProposta,
+		},
+		{
+			Nome:      "estornarPagamento",
+			Tipo:      tipoInvoke,
+			Descricao: "Estorna o pagamento de uma proposta, registrando o motivo e a confirmação estornada no histórico de estornos",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "codigoMotivo", Tipo: tipoArgTexto, Obrigatorio: true},
+				{Nome: "idOraculo", Tipo: tipoArgTexto},
+				{Nome: "assinatura", Tipo: tipoArgTexto},
+			},
+			Papel:    papelAdministradorOuOraculo,
+			executar: (*BoletoPropostaChaincode).estornarPagamento,
+		},
+		{
+			Nome:      "configurarMaxConsultaPropostas",
+			Tipo:      tipoInvoke,
+			Descricao: "Define quantos Ids consultarPropostas aceita por chamada",
+			Argumentos: []Argumento{
+				{Nome: "maximo", Tipo: tipoArgPositivo, Obrigatorio: true},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).configurarMaxConsultaPropostas,
+		},
+		{
+			Nome:      "atualizarConfiguracao",
+			Tipo:      tipoInvoke,
+			Descricao: "Altera os campos informados da configuração do chaincode, registrando a nova versão no histórico",
+			Argumentos: []Argumento{
+				{Nome: "configuracao", Tipo: tipoArgJSON, Obrigatorio: true},
+				{Nome: "versaoEsperada", Tipo: tipoArgNatural},
+			},
+			Papel:    papelAdministrador,
+			executar: (*BoletoPropostaChaincode).atualizarConfiguracao,
+		},
+		{
+			Nome:      "pausar",
+			Tipo:      tipoInvoke,
+			Descricao: "Coloca o chaincode em modo somente leitura: as demais funções Invoke são rejeitadas até retomar",
+			Argumentos: []Argumento{
+				{Nome: "motivo", Tipo: tipoArgTexto},
+			},
+			Papel:    papelAdministrador,
+			Pausada:  true,
+			executar: (*BoletoPropostaChaincode).pausar,
+		},
+		{
+			Nome:      "retomar",
+			Tipo:      tipoInvoke,
+			Descricao: "Encerra a pausa do chaincode, voltando a aceitar as funções Invoke",
+			Argumentos: []Argumento{
+				{Nome: "motivo", Tipo: tipoArgTexto},
+			},
+			Papel:    papelAdministrador,
+			Pausada:  true,
+			executar: (*BoletoPropostaChaincode).retomar,
+		},
+
+		// Query
+		{
+			Nome:      "consultarProposta",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta uma proposta existente",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarProposta,
+		},
+		{
+			Nome:      "consultarPropostas",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta várias propostas de uma vez, com o resultado ou o erro de cada Id",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true, Repetido: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarPropostas,
+		},
+		{
+			Nome:      "consultarPropostasPorCpf",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta as propostas de um pagador",
+			Argumentos: []Argumento{
+				{Nome: "cpfPagador", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarPropostasPorCpf,
+		},
+		{
+			Nome:      "consultarEventos",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta os eventos emitidos após a sequência informada (módulo notificacao)",
+			Argumentos: []Argumento{
+				{Nome: "aPartirDe", Tipo: tipoArgNatural, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarEventos,
+		},
+		{
+			Nome:      "consultarEntregas",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta quais assinantes confirmaram a entrega dos eventos de uma proposta (módulo notificacao)",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarEntregas,
+		},
+		{
+			Nome:       "consultarOraculos",
+			Tipo:       tipoQuery,
+			Descricao:  "Consulta os oráculos de pagamento registrados e o quorum",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).consultarOraculos,
+		},
+		{
+			Nome:      "consultarPagamento",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta os atestados que confirmaram o pagamento de uma proposta",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarPagamento,
+		},
+		{
+			Nome:      "consultarAtestadosPendentes",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta os atestados de uma proposta que ainda não atingiram o quorum",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarAtestadosPendentes,
+		},
+		{
+			Nome:      "consultarCancelamento",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta as solicitações de cancelamento de uma proposta e a transação que a cancelou",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarCancelamento,
+		},
+		{
+			Nome:      "consultarEstornos",
+			Tipo:      tipoQuery,
+			Descricao: "Consulta os estornos de pagamento de uma proposta, do mais antigo ao mais recente",
+			Argumentos: []Argumento{
+				{Nome: "Id", Tipo: tipoArgTexto, Obrigatorio: true},
+			},
+			Papel:    papelQualquer,
+			executar: (*BoletoPropostaChaincode).consultarEstornos,
+		},
+		{
+			Nome:       "consultarVersaoEsquema",
+			Tipo:       tipoQuery,
+			Descricao:  "Consulta a versão do esquema de armazenamento das propostas",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).consultarVersaoEsquema,
+		},
+		{
+			Nome:       "consultarConfiguracao",
+			Tipo:       tipoQuery,
+			Descricao:  "Consulta a configuração atual do chaincode",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).consultarConfiguracao,
+		},
+		{
+			Nome:       "consultarHistoricoConfiguracao",
+			Tipo:       tipoQuery,
+			Descricao:  "Consulta todas as versões da configuração, da mais antiga à atual",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).consultarHistoricoConfiguracao,
+		},
+		{
+			Nome:       "consultarPausa",
+			Tipo:       tipoQuery,
+			Descricao:  "Consulta se o chaincode está pausado, com o motivo e a transação da última pausa ou retomada",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).consultarPausa,
+		},
+		{
+			Nome:       "versao",
+			Tipo:       tipoQuery,
+			Descricao:  "Consulta a versão do chaincode, a versão do esquema, os módulos habilitados, se há administrador registrado e a quantidade de propostas e de entregas",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).versao,
+		},
+		{
+			Nome:       "listarFuncoes",
+			Tipo:       tipoQuery,
+			Descricao:  "Lista as funções do chaincode, com os argumentos e o papel exigido do caller",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).listarFuncoes,
+		},
+		{
+			Nome:       "listarErros",
+			Tipo:       tipoQuery,
+			Descricao:  "Lista o catálogo de códigos de erro, com a descrição de cada código",
+			Argumentos: []Argumento{},
+			Papel:      papelQualquer,
+			executar:   (*BoletoPropostaChaincode).listarErros,
+		},
+	}
+}
+
+// obterFuncao: busca a função registrada com o tipo e o nome informados. Retorna nil caso ela não exista
+func obterFuncao(tipo string, nome string) *Funcao {
+	for _, f := range funcoesRegistradas() {
+		if f.Tipo == tipo && f.Nome == nome {
+			return &f
+		}
+	}
+	return nil
+}
+
+// executarFuncao: valida os argumentos e o papel do caller e executa a função, registrando no log o erro retornado.
+// Com o chaincode pausado, somente as funções Query e as funções Invoke marcadas como Pausada são executadas.
+// Com a chave de idempotência, a repetição retorna o resultado original somente após as mesmas verificações
+func (t *BoletoPropostaChaincode) executarFuncao(stub shim.ChaincodeStubInterface, f *Funcao, args []string, chaveIdempotencia string) (dados []byte, err error) {
+	defer func() {
+		if err != nil {
+			registrarErro(logChamada(stub), err)
+		}
+	}()
+
+	err = validarArgumentos(f, args)
+	if err != nil {
+		return nil, err
+	}
+	if f.Papel == papelAdministrador {
+		err = t.verificarAdmin(stub)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.Tipo == tipoInvoke && !f.Pausada {
+		err = verificarPausa(stub)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if chaveIdempotencia != "" {
+		return t.invocarIdempotente(stub, chaveIdempotencia, f, args)
+	}
+	return f.executar(t, stub, args)
+}
+
+// ============================================================================================================================
+// Argumentos
+// ============================================================================================================================
+
+// validarArgumentos: verifica a quantidade e o tipo dos argumentos recebidos pela função
+func validarArgumentos(f *Funcao, args []string) error {
+	// Com um único argumento, a função com EsquemaJSON recebe o documento JSON, validado por ela
+	if f.EsquemaJSON != "" && len(args) == 1 {
+		return nil
+	}
+
+	minimo, maximo := 0, len(f.Argumentos)
+	repetido := maximo > 0 && f.Argumentos[maximo-1].Repetido
+	for _, a := range f.Argumentos {
+		if a.Obrigatorio {
+			minimo++
+		}
+	}
+	if len(args) < minimo || (!repetido && len(args) > maximo) {
+		aceitas := quantidadesAceitas(f, minimo, maximo, repetido)
+		if f.Observacao != "" {
+			return novoErro(codigoArgumentosInvalidos, msgQuantidadeArgumentosObservacao, aceitas, textoTraduzido(f.Observacao))
+		}
+		return novoErro(codigoArgumentosInvalidos, msgQuantidadeArgumentos, aceitas)
+	}
+
+	for i, valor := range args {
+		a := f.Argumentos[len(f.Argumentos)-1]
+		if i < len(f.Argumentos) {
+			a = f.Argumentos[i]
+		}
+		err := validarArgumento(a, valor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quantidades - quantidades de argumentos aceitas por uma função, descritas no idioma da mensagem
+// (ex.: "1, 5, 6 or 7", "at least 1")
+type quantidades struct {
+	aceitas []int
+	minimo  int // com repetido, a quantidade mínima
+}
+
+// quantidadesAceitas: quantidades de argumentos aceitas pela função
+func quantidadesAceitas(f *Funcao, minimo int, maximo int, repetido bool) quantidades {
+	if repetido {
+		return quantidades{minimo: minimo}
+	}
+	var q quantidades
+	if f.EsquemaJSON != "" && minimo > 1 {
+		q.aceitas = append(q.aceitas, 1)
+	}
+	for n := minimo; n <= maximo; n++ {
+		q.aceitas = append(q.aceitas, n)
+	}
+	return q
+}
+
+// traduzir: descreve as quantidades no idioma informado
+func (q quantidades) traduzir(idioma string) string {
+	if len(q.aceitas) == 0 {
+		return traduzir(idioma, msgQuantidadeMinima, q.minimo)
+	}
+	var aceitas []string
+	for _, n := range q.aceitas {
+		aceitas = append(aceitas, strconv.Itoa(n))
+	}
+	if len(aceitas) == 1 {
+		return aceitas[0]
+	}
+	return traduzir(idioma, msgQuantidadeOu, strings.Join(aceitas[:len(aceitas)-1], ", "), aceitas[len(aceitas)-1])
+}
+
+// validarArgumento: verifica se o valor corresponde ao tipo do argumento
+func validarArgumento(a Argumento, valor string) error {
+	var err error
+	switch a.Tipo {
+	case tipoArgBooleano:
+		_, err = lerBooleano(a.Nome, valor)
+	case tipoArgInteiro:
+		_, err = lerInteiro(a.Nome, valor)
+	case tipoArgNatural:
+		_, err = lerNatural(a.Nome, valor)
+	case tipoArgPositivo:
+		_, err = lerPositivo(a.Nome, valor)
+	}
+	return err
+}
+
+// lerBooleano: converte o argumento do tipo booleano
+func lerBooleano(nome string, valor string) (bool, error) {
+	b, err := strconv.ParseBool(valor)
+	if err != nil {
+		return false, novoErro(codigoArgumentosInvalidos, msgArgumentoInvalido, nome)
+	}
+	return b, nil
+}
+
+// lerInteiro: converte o argumento do tipo inteiro (não negativo)
+func lerInteiro(nome string, valor string) (int64, error) {
+	n, err := strconv.ParseInt(valor, 10, 64)
+	if err != nil || n < 0 {
+		return 0, novoErro(codigoArgumentosInvalidos, msgArgumentoInvalido, nome)
+	}
+	return n, nil
+}
+
+// lerNatural: converte o argumento do tipo natural
+func lerNatural(nome string, valor string) (uint64, error) {
+	n, err := strconv.ParseUint(valor, 10, 64)
+	if err != nil {
+		return 0, novoErro(codigoArgumentosInvalidos, msgArgumentoInvalido, nome)
+	}
+	return n, nil
+}
+
+// lerPositivo: converte o argumento do tipo positivo
+func lerPositivo(nome string, valor string) (int, error) {
+	n, err := strconv.Atoi(valor)
+	if err != nil || n < 1 {
+		return 0, novoErro(codigoArgumentosInvalidos, msgArgumentoInvalido, nome)
+	}
+	return n, nil
+}
+
+// listarFuncoes: função Query para consultar as funções do chaincode, com os argumentos e o papel exigido do caller
+func (t *BoletoPropostaChaincode) listarFuncoes(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("listarFuncoes...")
+
+	// As observações são publicadas no idioma padrão
+	funcoes := funcoesRegistradas()
+	for i := range funcoes {
+		if funcoes[i].Observacao != "" {
+			funcoes[i].Observacao = traduzir(idiomaPadrao, funcoes[i].Observacao)
+		}
+	}
+	funcoesAsBytes, err := json.Marshal(funcoes)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarFuncoes, err)
+	}
+	return funcoesAsBytes, nil
+}
@@ -0,0 +1,115 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"testing"
+)
+
+// ============================================================================================================================
+// Registro de funções
+// ============================================================================================================================
+
+// argumentosValidos: argumentos que satisfazem os tipos declarados pela função
+func argumentosValidos(f Funcao) []string {
+	exemplos := map[string]string{
+		tipoArgTexto:    "x",
+		tipoArgBooleano: "true",
+		tipoArgInteiro:  "1",
+		tipoArgNatural:  "1",
+		tipoArgPositivo: "1",
+		tipoArgJSON:     "[]",
+	}
+	var args []string
+	for _, a := range f.Argumentos {
+		if a.Obrigatorio {
+			args = append(args, exemplos[a.Tipo])
+		}
+	}
+	return args
+}
+
+func TestListarFuncoes(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	res, err := stub.MockQuery(cc, "listarFuncoes")
+	if err != nil {
+		t.Fatalf("listarFuncoes: %v", err)
+	}
+	var funcoes []Funcao
+	if err := lerDados(res, &funcoes); err != nil {
+		t.Fatalf("listarFuncoes retornou JSON inválido: %s", res)
+	}
+	if len(funcoes) != len(funcoesRegistradas()) {
+		t.Errorf("%d funções listadas; esperado %d", len(funcoes), len(funcoesRegistradas()))
+	}
+
+	nomes := map[string]bool{}
+	for _, f := range funcoes {
+		if nomes[f.Tipo+" "+f.Nome] {
+			t.Errorf("função %s %s registrada mais de uma vez", f.Tipo, f.Nome)
+		}
+		nomes[f.Tipo+" "+f.Nome] = true
+		if f.Tipo != tipoInvoke && f.Tipo != tipoQuery {
+			t.Errorf("%s: tipo %q inválido", f.Nome, f.Tipo)
+		}
+		if f.Descricao == "" || f.Papel == "" {
+			t.Errorf("%s: descrição ou papel não informados", f.Nome)
+		}
+		if obterFuncao(f.Tipo, f.Nome).executar == nil {
+			t.Errorf("%s: função sem executar", f.Nome)
+		}
+	}
+	if !nomes["query listarFuncoes"] || !nomes["invoke registrarProposta"] {
+		t.Errorf("funções listadas = %s", res)
+	}
+
+	// As funções Invoke não são executadas como Query
+	_, err = stub.MockQuery(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	verificarErro(t, "Invoke como Query", err, "Query de função desconhecida: registrarProposta")
+}
+
+func TestValidarArgumentos(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	casos := []struct {
+		nome   string
+		tipo   string
+		funcao string
+		args   []string
+		trecho string
+	}{
+		{"sem argumentos obrigatórios", tipoQuery, "consultarPagamento", nil, "Quantidade de argumentos incorreta. Esperado 1"},
+		{"argumentos em excesso", tipoQuery, "consultarOraculos", []string{"x"}, "Quantidade de argumentos incorreta. Esperado 0"},
+		{"opcionais e JSON", tipoInvoke, "registrarProposta", []string{"p1", "111"}, "Esperado 1, 5, 6 ou 7"},
+		{"observação", tipoInvoke, "criarProposta", []string{"p1", "111", "true", "false", "false", "1"}, "Esperado 4 ou 5 (o Id da proposta é gerado pelo chaincode)"},
+		{"repetido", tipoQuery, "consultarPropostas", nil, "Esperado pelo menos 1"},
+		{"booleano", tipoInvoke, "criarProposta", []string{"111", "sim", "false", "false"}, "Falha ao decodificar pagadorAceitou"},
+		{"inteiro negativo", tipoInvoke, "criarProposta", []string{"111", "true", "false", "false", "-1"}, "Falha ao decodificar valor"},
+		{"natural", tipoQuery, "consultarEventos", []string{"-1"}, "Falha ao decodificar aPartirDe"},
+		{"positivo", tipoInvoke, "configurarQuorumOraculos", []string{"0"}, "Falha ao decodificar quorum"},
+		{"natural opcional", tipoInvoke, "registrarProposta", []string{"p1", "111", "true", "false", "false", "0", "x"}, "Falha ao decodificar versaoEsperada"},
+	}
+	for _, c := range casos {
+		var err error
+		if c.tipo == tipoQuery {
+			_, err = stub.MockQuery(cc, c.funcao, c.args...)
+		} else {
+			_, err = stub.MockInvoke(cc, c.funcao, c.args...)
+		}
+		verificarErro(t, c.nome, err, c.trecho)
+	}
+}
+
+func TestPapelAdministrador(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.CallerMetadata = []byte("outro")
+
+	for _, f := range funcoesRegistradas() {
+		if f.Papel != papelAdministrador {
+			continue
+		}
+		_, err := stub.MockInvoke(cc, f.Nome, argumentosValidos(f)...)
+		verificarErro(t, f.Nome, err, "Falha ao verificar a identidade do administrador")
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Idempotência
+// ============================================================================================================================
+
+// Definição da Struct Idempotencia, resultado registrado para uma chave de idempotência
+type Idempotencia struct {
+	Funcao     string   `json:"funcao"` // informativo: a função já faz parte da chave do estado
+	Argumentos []string `json:"argumentos"`
+	Resultado  []byte   `json:"resultado"`
+	TxID       string   `json:"tx_id"`              // transação que aplicou a chamada
+	Registro   int64    `json:"registro,omitempty"` // timestamp da transação, em segundos (Unix)
+}
+
+// consts associadas às chaves de idempotência
+const (
+	argIdempotencia          = "idempotencia=" // último argumento opcional das funções Invoke
+	prefixoChaveIdempotencia = "idempotencia_"
+)
+
+// lerChaveIdempotencia: separa a chave de idempotência, informada como último argumento
+// ("idempotencia=<chave>"), dos argumentos da função. Retorna "" se a chave não foi informada
+func lerChaveIdempotencia(args []string) (string, []string, error) {
+	if len(args) == 0 || !strings.HasPrefix(args[len(args)-1], argIdempotencia) {
+		return "", args, nil
+	}
+	chave := strings.TrimPrefix(args[len(args)-1], argIdempotencia)
+	if chave == "" {
+		return "", nil, novoErro(codigoArgumentosInvalidos, msgChaveIdempotenciaVazia)
+	}
+	return chave, args[:len(args)-1], nil
+}
+
+// invocarIdempotente: executa a função apenas na primeira chamada com a chave informada.
+// A chave vale por caller e por função (ver chaveEstadoIdempotencia): a mesma chave em outra função é
+// uma chamada nova, e não um conflito. É utilizada após a verificação do papel do caller e da pausa
+// (ver executarFuncao). As chamadas seguintes da mesma função com os mesmos argumentos retornam o
+// resultado original, sem reaplicar a função; com outros argumentos, a chamada é rejeitada. Com o prazo_idempotencia_horas da Configuracao, a chave volta a ser aceita como nova após o prazo
+func (t *BoletoPropostaChaincode) invocarIdempotente(stub shim.ChaincodeStubInterface, chave string, f *Funcao, args []string) ([]byte, error) {
+	chaveEstado, err := chaveEstadoIdempotencia(stub, f.Nome, chave)
+	if err != nil {
+		return nil, err
+	}
+	registroAsBytes, err := stub.GetState(chaveEstado)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterIdempotencia, chave, err)
+	}
+	configuracao, err := obterConfiguracao(stub)
+	if err != nil {
+		return nil, err
+	}
+	agora, err := stub.GetTxTimestamp()
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterTimestamp, err)
+	}
+
+	if len(registroAsBytes) > 0 {
+		var registro Idempotencia
+		err = json.Unmarshal(registroAsBytes, &registro)
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Idempotencia", err)
+		}
+		prazo := int64(configuracao.PrazoIdempotenciaHoras) * 3600
+		if prazo == 0 || agora.Seconds-registro.Registro < prazo {
+			if !reflect.DeepEqual(registro.Argumentos, args) {
+				return nil, novoErro(codigoIdempotenciaConflitante, msgIdempotenciaConflitante, chave)
+			}
+			logChamada(stub).Info("Chave de idempotência já aplicada", "idempotencia", chave, "txid_original", registro.TxID)
+			return registro.Resultado, nil
+		}
+		logChamada(stub).Info("Chave de idempotência expirada", "idempotencia", chave, "txid_original", registro.TxID)
+	}
+
+	resultado, err := f.executar(t, stub, args)
+	if err != nil {
+		// A transação com erro não é aplicada, e pode ser repetida com a mesma chave
+		return nil, err
+	}
+
+	registroAsBytes, err = json.Marshal(Idempotencia{
+		Funcao:     f.Nome,
+		Argumentos: args,
+		Resultado:  resultado,
+		TxID:       stub.GetTxID(),
+		Registro:   agora.Seconds,
+	})
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Idempotencia", err)
+	}
+	err = stub.PutState(chaveEstado, registroAsBytes)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaRegistrarIdempotencia, chave, err)
+	}
+	return resultado, nil
+}
+
+// chaveEstadoIdempotencia: chave do estado da chave de idempotência, formada pela função, pelo hash
+// SHA-256 da metadata do caller e pela chave informada. Outro caller, ou a mesma chave em outra
+// função, não obtém o resultado registrado
+func chaveEstadoIdempotencia(stub shim.ChaincodeStubInterface, funcao, chave string) (string, error) {
+	metadata, err := stub.GetCallerMetadata()
+	if err != nil {
+		return "", novoErro(codigoErroInterno, msgFalhaObterMetadata)
+	}
+	caller := sha256.Sum256(metadata)
+	return prefixoChaveIdempotencia + funcao + "_" + hex.EncodeToString(caller[:]) + "_" + chave, nil
+}
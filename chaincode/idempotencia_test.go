@@ -0,0 +1,67 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"errors"
+	"testing"
+)
+
+func TestInvocarIdempotente(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	args := []string{"p1", "111", "true", "false", "false", "15000", "idempotencia=req-1"}
+
+	res, err := stub.MockInvoke(cc, "registrarProposta", args...)
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"registrado":true}}` {
+		t.Fatalf("primeira chamada = %s, %v", res, err)
+	}
+
+	// A repetição retorna o resultado original sem reaplicar a função nem emitir eventos
+	res, err = stub.MockInvoke(cc, "registrarProposta", args...)
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"registrado":true}}` {
+		t.Errorf("repetição = %s, %v", res, err)
+	}
+	if len(stub.Events) != 1 {
+		t.Errorf("%d eventos emitidos; esperado 1", len(stub.Events))
+	}
+	if seq, _ := cc.ultimaSequenciaEvento(stub); seq != 1 {
+		t.Errorf("sequência de eventos = %d; esperado 1", seq)
+	}
+	if p := consultar(t, stub, cc, "p1"); p.Versao != 1 {
+		t.Errorf("proposta após a repetição = %+v", p)
+	}
+
+	// A mesma chave com outros argumentos é rejeitada
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000", "1", "idempotencia=req-1")
+	verificarErro(t, "outros argumentos", err, "Chave de idempotência [req-1] já utilizada com outros argumentos")
+
+	// A chave vale por função e por caller: a repetição passa antes pela função e pelo papel do caller
+	_, err = stub.MockInvoke(cc, "removerOraculo", "banco-1", "idempotencia=req-1")
+	verificarErro(t, "outra função", err, "Oráculo [banco-1] não existente.")
+	oraculo := novoOraculo(t, "banco-1")
+	if _, err := stub.MockInvoke(cc, "registrarOraculo", oraculo.id, oraculo.pem(t), "idempotencia=req-1"); err != nil {
+		t.Errorf("mesma chave em outra função: %v", err)
+	}
+	_, err = stub.MockInvoke(cc, "excluirProposta", args...)
+	verificarErro(t, "função desconhecida", err, "excluirProposta")
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "registrarProposta", args...)
+	verificarErro(t, "outro caller", err, "Falha ao verificar a identidade do administrador")
+	stub.CallerMetadata = []byte(adminTeste)
+	stub.MockInvoke(cc, "pausar")
+	_, err = stub.MockInvoke(cc, "registrarProposta", args...)
+	verificarErro(t, "chaincode pausado", err, "Chaincode pausado")
+	stub.MockInvoke(cc, "retomar")
+
+	// Uma chamada com erro não registra a chave e pode ser repetida
+	stub.SimularFalha("SetEvent", errors.New("falha simulada"))
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "false", "false", "idempotencia=req-2")
+	verificarErro(t, "chamada com erro", err, "falha simulada")
+	stub.SimularFalha("SetEvent", nil)
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "false", "false", "idempotencia=req-2"); err != nil {
+		t.Errorf("repetição após erro: %v", err)
+	}
+
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p3", "111", "true", "false", "false", "idempotencia=")
+	verificarErro(t, "chave vazia", err, "Chave de idempotência vazia")
+}
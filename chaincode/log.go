@@ -0,0 +1,238 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Log
+// ============================================================================================================================
+
+// nivelLog - níveis de log, em ordem crescente de severidade
+type nivelLog int
+
+const (
+	nivelDebug nivelLog = iota
+	nivelInfo
+	nivelAviso
+	nivelErro
+)
+
+// nomes dos níveis de log, aceitos no argumento "log=" do Init
+var nomesNiveisLog = []string{"debug", "info", "aviso", "erro"}
+
+const (
+	argNivelLog    = "log="
+	nivelLogPadrao = nivelInfo
+)
+
+func (n nivelLog) String() string {
+	return nomesNiveisLog[n]
+}
+
+// lerNivelLog: converte o nome do nível de log; o nome vazio corresponde ao nível padrão
+func lerNivelLog(nome string) (nivelLog, error) {
+	if nome == "" {
+		return nivelLogPadrao, nil
+	}
+	for i, n := range nomesNiveisLog {
+		if n == nome {
+			return nivelLog(i), nil
+		}
+	}
+	return nivelLogPadrao, novoErro(codigoArgumentosInvalidos, msgNivelLogDesconhecido, nome)
+}
+
+var (
+	// saidaLog - destino das linhas de log; os testes a substituem para verificar o conteúdo
+	saidaLog io.Writer = os.Stdout
+
+	// nível configurado no Init, carregado do estado na primeira chamada após o início do chaincode
+	muNivelLog    sync.Mutex
+	nivelLogAtual *nivelLog
+)
+
+// definirNivelLog: altera o nível de log das próximas chamadas (ver iniciar)
+func definirNivelLog(nivel nivelLog) {
+	muNivelLog.Lock()
+	defer muNivelLog.Unlock()
+	nivelLogAtual = &nivel
+}
+
+// obterNivelLog: retorna o nível de log configurado no Init, ou o padrão se o estado não puder ser lido
+func obterNivelLog(stub shim.ChaincodeStubInterface) nivelLog {
+	muNivelLog.Lock()
+	defer muNivelLog.Unlock()
+	if nivelLogAtual == nil {
+		var modulos Modulos
+		modulosAsBytes, err := stub.GetState(chaveModulos)
+		if err != nil || len(modulosAsBytes) > 0 && json.Unmarshal(modulosAsBytes, &modulos) != nil {
+			return nivelLogPadrao
+		}
+		nivel, _ := lerNivelLog(modulos.NivelLog)
+		nivelLogAtual = &nivel
+	}
+	return *nivelLogAtual
+}
+
+// Log - registra linhas no formato chave=valor, com os campos comuns da chamada. Os valores
+// sensíveis são redigidos automaticamente (ver redigir), de forma que CPFs, certificados e
+// segredos nunca aparecem no log do peer
+type Log struct {
+	nivel  nivelLog
+	campos []interface{} // pares chave/valor incluídos em todas as linhas
+}
+
+// logChamada: log da chamada em andamento, com o txid e a função chamada em todas as linhas
+func logChamada(stub shim.ChaincodeStubInterface) *Log {
+	log := &Log{nivel: obterNivelLog(stub)}
+	funcao := ""
+	if args := stub.GetStringArgs(); len(args) > 0 {
+		funcao = args[0]
+	}
+	return log.Com("txid", stub.GetTxID(), "funcao", funcao)
+}
+
+// Com: retorna um log com os pares chave/valor informados acrescentados aos campos comuns
+func (l *Log) Com(campos ...interface{}) *Log {
+	return &Log{nivel: l.nivel, campos: append(append([]interface{}{}, l.campos...), campos...)}
+}
+
+// Debug: registra detalhes úteis apenas para o diagnóstico
+func (l *Log) Debug(msg string, campos ...interface{}) {
+	l.registrar(nivelDebug, msg, campos)
+}
+
+// Info: registra as alterações de estado e os marcos da execução
+func (l *Log) Info(msg string, campos ...interface{}) {
+	l.registrar(nivelInfo, msg, campos)
+}
+
+// Aviso: registra situações inesperadas que não impedem a execução
+func (l *Log) Aviso(msg string, campos ...interface{}) {
+	l.registrar(nivelAviso, msg, campos)
+}
+
+// Erro: registra as falhas da execução
+func (l *Log) Erro(msg string, campos ...interface{}) {
+	l.registrar(nivelErro, msg, campos)
+}
+
+// registrar: escreve a linha se o nível for igual ou superior ao configurado
+func (l *Log) registrar(nivel nivelLog, msg string, campos []interface{}) {
+	if nivel < l.nivel {
+		return
+	}
+	var linha bytes.Buffer
+	linha.WriteString("nivel=" + nivel.String())
+	escreverCampo(&linha, "msg", msg)
+	todos := append(append([]interface{}{}, l.campos...), campos...)
+	for i := 0; i < len(todos); i += 2 {
+		chave := fmt.Sprint(todos[i])
+		var valor interface{} = "(sem valor)"
+		if i+1 < len(todos) {
+			valor = todos[i+1]
+		}
+		escreverCampo(&linha, chave, valor)
+	}
+	linha.WriteByte('\n')
+	saidaLog.Write(linha.Bytes())
+}
+
+// registrarErro: registra o erro retornado pela chamada, no nível erro se for interno e aviso se for do caller
+func registrarErro(log *Log, err error) {
+	e := converterErro(err)
+	if e.Codigo == codigoErroInterno {
+		log.Erro(e.Mensagem, "codigo", e.Codigo)
+		return
+	}
+	log.Aviso(e.Mensagem, "codigo", e.Codigo)
+}
+
+// escreverCampo: escreve " chave=valor" com o valor redigido, entre aspas se necessário
+func escreverCampo(linha *bytes.Buffer, chave string, valor interface{}) {
+	texto := redigir(chave, valor)
+	if texto == "" || strings.ContainsAny(texto, " \"=\n\t") {
+		texto = strconv.Quote(texto)
+	}
+	linha.WriteString(" " + chave + "=" + texto)
+}
+
+// ============================================================================================================================
+// Redação dos dados sensíveis
+// ============================================================================================================================
+
+// camposSensiveis - trechos dos nomes dos campos cujos valores nunca são registrados
+var camposSensiveis = []string{"certificado", "certificate", "metadata", "sigma", "assinatura", "chave_publica", "segredo", "senha", "token"}
+
+var (
+	expressaoCPF = regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}[-.]?\d{2}\b`)
+	expressaoPEM = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]+-----.*?-----END [A-Z ]+-----`)
+)
+
+// redigir: converte o valor em texto, ocultando os dados sensíveis. Dos campos de CPF apenas os dois
+// últimos dígitos são mantidos; os campos sensíveis (ver camposSensiveis) e os valores binários são
+// substituídos pelo tamanho; nos demais textos os CPFs e os blocos PEM (certificados e chaves) são ocultados
+func redigir(chave string, valor interface{}) string {
+	chave = strings.ToLower(chave)
+	if b, ok := valor.([]byte); ok {
+		return fmt.Sprintf("[redigido %d bytes]", len(b))
+	}
+	texto := fmt.Sprint(valor)
+	if strings.Contains(chave, "cpf") {
+		return mascararCPF(texto)
+	}
+	for _, sensivel := range camposSensiveis {
+		if strings.Contains(chave, sensivel) {
+			return fmt.Sprintf("[redigido %d bytes]", len(texto))
+		}
+	}
+	texto = expressaoPEM.ReplaceAllString(texto, "[redigido]")
+	return expressaoCPF.ReplaceAllStringFunc(texto, mascararCPF)
+}
+
+// mascararCPF: mantém apenas os dois últimos dígitos do CPF
+func mascararCPF(cpf string) string {
+	digitos := 0
+	for _, c := range cpf {
+		if c >= '0' && c <= '9' {
+			digitos++
+		}
+	}
+	mascara := []rune(cpf)
+	for i := range mascara {
+		if mascara[i] >= '0' && mascara[i] <= '9' && digitos > 2 {
+			mascara[i] = '*'
+			digitos--
+		}
+	}
+	return string(mascara)
+}
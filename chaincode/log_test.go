@@ -0,0 +1,90 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// capturarLog: redireciona o log para o buffer retornado até o fim do teste
+func capturarLog(t *testing.T) *bytes.Buffer {
+	var saida bytes.Buffer
+	anterior := saidaLog
+	saidaLog = &saida
+	t.Cleanup(func() {
+		saidaLog = anterior
+	})
+	return &saida
+}
+
+func TestLogCamposERedacao(t *testing.T) {
+	stub, cc := iniciarChaincode(t, moduloAutenticacao, "log=debug")
+	saida := capturarLog(t)
+
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p1", "123.456.789-09", "true", "false", "false"); err != nil {
+		t.Fatalf("registrarProposta: %v", err)
+	}
+	txID := stub.TxID
+	stub.MockQuery(cc, "consultarPropostasPorCpf", "12345678909")
+	log := saida.String()
+
+	for _, trecho := range []string{
+		`nivel=info msg="Proposta criada!" txid=` + txID + ` funcao=registrarProposta id_proposta=p1`,
+		`nivel=debug msg="Gravando Proposta"`,
+		`cpf_pagador=***.***.***-09`,
+		`certificado="[redigido 5 bytes]" sigma="[redigido 5 bytes]"`,
+	} {
+		if !strings.Contains(log, trecho) {
+			t.Errorf("log sem %q:\n%s", trecho, log)
+		}
+	}
+	for _, trecho := range []string{"123.456.789", "123456789", adminTeste} {
+		if strings.Contains(log, trecho) {
+			t.Errorf("log contém %q:\n%s", trecho, log)
+		}
+	}
+}
+
+func TestNivelLog(t *testing.T) {
+	stub, cc := iniciarChaincode(t, "log=aviso")
+	saida := capturarLog(t)
+
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	if saida.Len() != 0 {
+		t.Errorf("linhas abaixo do nível aviso registradas:\n%s", saida)
+	}
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "talvez")
+	if log := saida.String(); !strings.Contains(log, "nivel=aviso") || !strings.Contains(log, "codigo="+codigoArgumentosInvalidos) {
+		t.Errorf("erro do caller não registrado:\n%s", log)
+	}
+
+	_, err := stub.MockInit(cc, "init", "log=verboso")
+	verificarCodigo(t, "nível desconhecido", err, codigoArgumentosInvalidos)
+	verificarErro(t, "nível desconhecido", err, "Nível de log desconhecido: verboso")
+}
+
+func TestRedigir(t *testing.T) {
+	casos := []struct {
+		chave    string
+		valor    interface{}
+		esperado string
+	}{
+		{"cpf_pagador", "12345678909", "*********09"},
+		{"cpf", "123.456.789-09", "***.***.***-09"},
+		{"cpf", "373.745.808.20", "***.***.***.20"},
+		{"msg", "Propostas do CPF [123.456.789-09]", "Propostas do CPF [***.***.***-09]"},
+		{"erro", "chave -----BEGIN PUBLIC KEY-----\nMFkw\n-----END PUBLIC KEY----- inválida", "chave [redigido] inválida"},
+		{"chave_publica", "MFkw", "[redigido 4 bytes]"},
+		{"metadata", []byte{1, 2, 3}, "[redigido 3 bytes]"},
+		{"dados", []byte("admin"), "[redigido 5 bytes]"},
+		{"id_proposta", "p1", "p1"},
+		{"quorum", 2, "2"},
+	}
+	for _, c := range casos {
+		if texto := redigir(c.chave, c.valor); texto != c.esperado {
+			t.Errorf("redigir(%s, %v) = %q; esperado %q", c.chave, c.valor, texto, c.esperado)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Definição da Struct RelatorioLote, resposta de registrarPropostasEmLote
+type RelatorioLote struct {
+	Aplicado   bool            `json:"aplicado"`
+	Resultados []ResultadoLote `json:"resultados"`
+}
+
+// Definição da Struct ResultadoLote, resultado de cada proposta do lote, na ordem recebida
+type ResultadoLote struct {
+	Indice     int    `json:"indice"`
+	IDProposta string `json:"id_proposta,omitempty"`
+	Status     string `json:"status"`
+	Codigo     string `json:"codigo,omitempty"` // código do erro da rejeição (ver codigosErro)
+	Motivo     string `json:"motivo,omitempty"` // motivo da rejeição
+}
+
+// itemLote - proposta validada, aguardando a gravação do lote
+type itemLote struct {
+	proposta       Proposta
+	versaoEsperada *uint64
+}
+
+// status das propostas do lote
+const (
+	statusLoteCriado      = "criado"
+	statusLoteAtualizado  = "atualizado"
+	statusLoteRejeitado   = "rejeitado"
+	statusLoteNaoAplicado = "nao_aplicado" // válida, mas o lote foi rejeitado por outra proposta
+)
+
+// registrarPropostasEmLote: função Invoke para registrar ou atualizar várias propostas em uma única transação,
+// recebendo os seguintes argumentos:
+// args[0]: lista JSON de RegistroProposta (ver registrarProposta), no máximo o max_lote_propostas da Configuracao
+// Todas as propostas são validadas antes da gravação. Se alguma for rejeitada nenhuma é gravada, e o erro
+// contém o RelatorioLote com o motivo de cada rejeição; caso contrário todas são gravadas e o RelatorioLote é retornado
+func (t *BoletoPropostaChaincode) registrarPropostasEmLote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("registrarPropostasEmLote...")
+
+	var registros []json.RawMessage
+	err := json.Unmarshal([]byte(args[0]), &registros)
+	if err != nil {
+		return nil, novoErro(codigoArgumentosInvalidos, msgLoteInvalido, err)
+	}
+	if len(registros) == 0 {
+		return nil, novoErro(codigoArgumentosInvalidos, msgLoteVazio)
+	}
+	configuracao, err := obterConfiguracao(stub)
+	if err != nil {
+		return nil, err
+	}
+	if len(registros) > configuracao.MaxLotePropostas {
+		return nil, novoErro(codigoArgumentosInvalidos, msgMaximoLoteExcedido, len(registros), configuracao.MaxLotePropostas)
+	}
+
+	// Valida todas as propostas antes de gravar qualquer uma
+	idioma := idiomaChamada(stub)
+	relatorio := RelatorioLote{Aplicado: true}
+	var itens []itemLote
+	ids := map[string]bool{}
+	for i, registro := range registros {
+		resultado := ResultadoLote{Indice: i}
+		nova, versaoEsperada, err := lerRegistroProposta(string(registro))
+		if err == nil {
+			resultado.IDProposta = nova.ID
+			if ids[nova.ID] {
+				err = novoErro(codigoArgumentosInvalidos, msgPropostaRepetidaLote, nova.ID)
+			}
+			ids[nova.ID] = true
+		}
+		var anterior *Proposta
+		if err == nil {
+			anterior, err = t.validarProposta(stub, nova, versaoEsperada)
+		}
+
+		switch {
+		case err != nil:
+			resultado.Status = statusLoteRejeitado
+			resultado.Codigo = converterErro(err).Codigo
+			resultado.Motivo = converterErro(err).traduzido(idioma).Mensagem
+			relatorio.Aplicado = false
+		case anterior == nil:
+			resultado.Status = statusLoteCriado
+		default:
+			resultado.Status = statusLoteAtualizado
+		}
+		relatorio.Resultados = append(relatorio.Resultados, resultado)
+		itens = append(itens, itemLote{nova, versaoEsperada})
+	}
+
+	if !relatorio.Aplicado {
+		for i := range relatorio.Resultados {
+			if relatorio.Resultados[i].Status != statusLoteRejeitado {
+				relatorio.Resultados[i].Status = statusLoteNaoAplicado
+			}
+		}
+		relatorioAsBytes, err := json.Marshal(relatorio)
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "RelatorioLote", err)
+		}
+		erroLote := novoErro(codigoLoteRejeitado, msgLoteRejeitado).(*Erro)
+		erroLote.Detalhes = relatorioAsBytes
+		return nil, erroLote
+	}
+
+	// Grava as propostas. Uma falha na gravação descarta a transação inteira
+	for i, item := range itens {
+		_, err = t.gravarProposta(stub, item.proposta, item.versaoEsperada)
+		if err != nil {
+			// Mantém o erro da gravação, com o item do lote nos detalhes
+			erroGravacao := *converterErro(err)
+			erroGravacao.Detalhes, _ = json.Marshal(ResultadoLote{Indice: i, IDProposta: item.proposta.ID, Status: statusLoteRejeitado, Codigo: erroGravacao.Codigo})
+			return nil, &erroGravacao
+		}
+	}
+	logChamada(stub).Info("Lote registrado", "quantidade", len(itens))
+	return json.Marshal(relatorio)
+}
@@ -0,0 +1,117 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// registroLote: JSON do RegistroProposta utilizado nos lotes de teste
+func registroLote(id, cpf string, extra string) string {
+	return `{"id_proposta":"` + id + `","cpf_pagador":"` + cpf + `","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false` + extra + `}`
+}
+
+func TestRegistrarPropostasEmLote(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "false", "false")
+
+	lote := "[" + strings.Join([]string{
+		registroLote("p1", "111", `,"versao_esperada":1`),
+		registroLote("p2", "222", `,"valor":100`),
+		registroLote("p3", "333", ""),
+	}, ",") + "]"
+	res, err := stub.MockInvoke(cc, "registrarPropostasEmLote", lote)
+	if err != nil {
+		t.Fatalf("registrarPropostasEmLote: %v", err)
+	}
+	var relatorio RelatorioLote
+	lerDados(res, &relatorio)
+	status := []string{}
+	for _, r := range relatorio.Resultados {
+		status = append(status, r.IDProposta+":"+r.Status)
+	}
+	if !relatorio.Aplicado || strings.Join(status, ",") != "p1:atualizado,p2:criado,p3:criado" {
+		t.Errorf("relatório = %s", res)
+	}
+	if p := consultar(t, stub, cc, "p2"); p.Valor != 100 || p.Versao != 1 {
+		t.Errorf("p2 = %+v", p)
+	}
+	if p := consultar(t, stub, cc, "p1"); !p.PagadorAceitou || p.Versao != 2 {
+		t.Errorf("p1 = %+v", p)
+	}
+
+	// O evento do chaincode contém os eventos de todas as propostas do lote
+	var eventos []Evento
+	json.Unmarshal(stub.LastEvent().Payload, &eventos)
+	if len(eventos) != 3 || eventos[0].Proposta.ID != "p1" || eventos[2].Proposta.ID != "p3" {
+		t.Errorf("eventos do lote = %+v", eventos)
+	}
+}
+
+func TestRegistrarPropostasEmLoteRejeitado(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "false", "false")
+
+	lote := "[" + strings.Join([]string{
+		registroLote("p2", "222", ""),
+		registroLote("p1", "111", ""),
+		registroLote("p3", "333", `,"extra":1`),
+		registroLote("p2", "222", ""),
+	}, ",") + "]"
+	_, err := stub.MockInvoke(cc, "registrarPropostasEmLote", lote)
+	if err == nil || lerErro(err).Codigo != codigoLoteRejeitado {
+		t.Fatalf("erro = %v; esperado o lote rejeitado", err)
+	}
+
+	// O relatório é retornado nos detalhes do erro
+	var relatorio RelatorioLote
+	if e := json.Unmarshal(lerErro(err).Detalhes, &relatorio); e != nil {
+		t.Fatalf("relatório inválido no erro: %v", err)
+	}
+	esperado := []struct{ status, codigo, motivo string }{
+		{statusLoteNaoAplicado, "", ""},
+		{statusLoteRejeitado, codigoPropostaJaExistente, "Informe a versão esperada"},
+		{statusLoteRejeitado, codigoArgumentosInvalidos, `unknown field "extra"`},
+		{statusLoteRejeitado, codigoArgumentosInvalidos, "Proposta [p2] repetida no lote"},
+	}
+	if relatorio.Aplicado || len(relatorio.Resultados) != len(esperado) {
+		t.Fatalf("relatório = %+v", relatorio)
+	}
+	for i, e := range esperado {
+		r := relatorio.Resultados[i]
+		if r.Indice != i || r.Status != e.status || r.Codigo != e.codigo || !strings.Contains(r.Motivo, e.motivo) {
+			t.Errorf("resultado %d = %+v; esperado %s %s (%s)", i, r, e.status, e.codigo, e.motivo)
+		}
+	}
+
+	// Nenhuma proposta do lote é gravada
+	_, err = stub.MockQuery(cc, "consultarProposta", "p2")
+	verificarErro(t, "proposta de lote rejeitado", err, "não existente")
+
+	casos := []struct {
+		nome, args, trecho string
+	}{
+		{"lote inválido", `{"id_proposta":"p1"}`, "Lote inválido"},
+		{"lote vazio", `[]`, "Lote vazio"},
+	}
+	for _, c := range casos {
+		_, err := stub.MockInvoke(cc, "registrarPropostasEmLote", c.args)
+		verificarErro(t, c.nome, err, c.trecho)
+	}
+	// A falha na gravação mantém o erro original, com o item do lote nos detalhes
+	stub.SimularFalha("InsertRow", errors.New("falha simulada"))
+	_, err = stub.MockInvoke(cc, "registrarPropostasEmLote", "["+registroLote("p4", "444", "")+"]")
+	var item ResultadoLote
+	if err == nil || json.Unmarshal(lerErro(err).Detalhes, &item) != nil || item.IDProposta != "p4" || item.Codigo != lerErro(err).Codigo {
+		t.Errorf("erro na gravação do lote = %v", err)
+	}
+	verificarErro(t, "gravação do lote", err, "falha simulada")
+	stub.SimularFalha("InsertRow", nil)
+
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "registrarPropostasEmLote", "["+registroLote("p4", "444", "")+"]")
+	verificarErro(t, "caller não administrador", err, "Falha ao verificar a identidade do administrador")
+}
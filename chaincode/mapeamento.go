@@ -0,0 +1,211 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Mapeamento de structs para tabelas
+// ============================================================================================================================
+
+// mapeamento - colunas de uma struct, definidas pela tag `coluna:"nome[,chave][,indice]"`.
+// As colunas seguem a ordem dos campos da struct; campos sem a tag não são gravados na tabela.
+//
+//	chave:  a coluna faz parte da chave da tabela
+//	indice: a coluna pode ser utilizada para listar os registros (ver RepositorioProposta.Listar)
+type mapeamento struct {
+	tabela string
+	tipo   reflect.Type
+	campos []campoMapeado
+}
+
+// campoMapeado - coluna associada a um campo da struct
+type campoMapeado struct {
+	coluna string
+	campo  int // posição do campo na struct
+	tipo   shim.ColumnDefinition_Type
+	chave  bool
+	indice bool
+}
+
+// tipos de coluna suportados, pelo tipo do campo
+var tiposColuna = map[reflect.Kind]shim.ColumnDefinition_Type{
+	reflect.String: shim.ColumnDefinition_STRING,
+	reflect.Bool:   shim.ColumnDefinition_BOOL,
+	reflect.Int32:  shim.ColumnDefinition_INT32,
+	reflect.Int64:  shim.ColumnDefinition_INT64,
+	reflect.Uint32: shim.ColumnDefinition_UINT32,
+	reflect.Uint64: shim.ColumnDefinition_UINT64,
+}
+
+// mapear: monta o mapeamento da struct modelo para a tabela informada.
+// Tags inválidas são erros de programação, por isso geram panic
+func mapear(tabela string, modelo interface{}) mapeamento {
+	tipo := reflect.TypeOf(modelo)
+	m := mapeamento{tabela: tabela, tipo: tipo}
+
+	for i := 0; i < tipo.NumField(); i++ {
+		tag := tipo.Field(i).Tag.Get("coluna")
+		if tag == "" {
+			continue
+		}
+		opcoes := strings.Split(tag, ",")
+		tipoColuna, ok := tiposColuna[tipo.Field(i).Type.Kind()]
+		if !ok {
+			panic(fmt.Sprintf("Tipo do campo %s.%s não suportado em tabelas", tipo.Name(), tipo.Field(i).Name))
+		}
+
+		campo := campoMapeado{coluna: opcoes[0], campo: i, tipo: tipoColuna}
+		for _, opcao := range opcoes[1:] {
+			switch opcao {
+			case "chave":
+				campo.chave = true
+			case "indice":
+				campo.indice = true
+			default:
+				panic(fmt.Sprintf("Opção [%s] inválida na coluna %s.%s", opcao, tipo.Name(), tipo.Field(i).Name))
+			}
+		}
+		m.campos = append(m.campos, campo)
+	}
+
+	if len(m.chaves()) == 0 {
+		panic("Nenhuma coluna chave definida para a tabela " + tabela)
+	}
+	return m
+}
+
+// definicoes: definição das colunas, utilizada para criar a tabela
+func (m mapeamento) definicoes() []*shim.ColumnDefinition {
+	var definicoes []*shim.ColumnDefinition
+	for _, c := range m.campos {
+		definicoes = append(definicoes, &shim.ColumnDefinition{Name: c.coluna, Type: c.tipo, Key: c.chave})
+	}
+	return definicoes
+}
+
+// chaves: colunas que compõem a chave da tabela
+func (m mapeamento) chaves() []campoMapeado {
+	var chaves []campoMapeado
+	for _, c := range m.campos {
+		if c.chave {
+			chaves = append(chaves, c)
+		}
+	}
+	return chaves
+}
+
+// indices: colunas que podem ser utilizadas para listar os registros
+func (m mapeamento) indices() []campoMapeado {
+	var indices []campoMapeado
+	for _, c := range m.campos {
+		if c.indice {
+			indices = append(indices, c)
+		}
+	}
+	return indices
+}
+
+// indice: busca a coluna indexada pelo nome
+func (m mapeamento) indice(coluna string) (campoMapeado, bool) {
+	for _, c := range m.indices() {
+		if c.coluna == coluna {
+			return c, true
+		}
+	}
+	return campoMapeado{}, false
+}
+
+// linha: converte a struct (ou ponteiro para ela) em uma linha da tabela
+func (m mapeamento) linha(registro interface{}) shim.Row {
+	v := reflect.Indirect(reflect.ValueOf(registro))
+	var row shim.Row
+	for _, c := range m.campos {
+		row.Columns = append(row.Columns, coluna(v.Field(c.campo)))
+	}
+	return row
+}
+
+// texto: valor do campo da coluna em formato texto, utilizado nas chaves dos índices
+func (m mapeamento) texto(registro interface{}, c campoMapeado) string {
+	return fmt.Sprint(reflect.Indirect(reflect.ValueOf(registro)).Field(c.campo).Interface())
+}
+
+// decodificar: preenche a struct apontada por destino com os valores da linha.
+// Colunas ausentes na linha (tabelas criadas antes da inclusão do campo) mantêm o valor zero
+func (m mapeamento) decodificar(row shim.Row, destino interface{}) error {
+	v := reflect.ValueOf(destino)
+	if v.Kind() != reflect.Ptr || v.Elem().Type() != m.tipo {
+		return novoErro(codigoErroInterno, msgDestinoTabelaInvalido, m.tabela, destino)
+	}
+	v = v.Elem()
+
+	for i, c := range m.campos {
+		if i >= len(row.Columns) || row.Columns[i] == nil {
+			continue
+		}
+		col := row.Columns[i]
+		campo := v.Field(c.campo)
+		switch c.tipo {
+		case shim.ColumnDefinition_STRING:
+			campo.SetString(col.GetString_())
+		case shim.ColumnDefinition_BOOL:
+			campo.SetBool(col.GetBool())
+		case shim.ColumnDefinition_INT32:
+			campo.SetInt(int64(col.GetInt32()))
+		case shim.ColumnDefinition_INT64:
+			campo.SetInt(col.GetInt64())
+		case shim.ColumnDefinition_UINT32:
+			campo.SetUint(uint64(col.GetUint32()))
+		case shim.ColumnDefinition_UINT64:
+			campo.SetUint(col.GetUint64())
+		}
+	}
+	return nil
+}
+
+// coluna: converte o valor de um campo em uma coluna da tabela
+func coluna(v reflect.Value) *shim.Column {
+	switch v.Kind() {
+	case reflect.String:
+		return &shim.Column{Value: &shim.Column_String_{String_: v.String()}}
+	case reflect.Bool:
+		return &shim.Column{Value: &shim.Column_Bool{Bool: v.Bool()}}
+	case reflect.Int32:
+		return &shim.Column{Value: &shim.Column_Int32{Int32: int32(v.Int())}}
+	case reflect.Int64:
+		return &shim.Column{Value: &shim.Column_Int64{Int64: v.Int()}}
+	case reflect.Uint32:
+		return &shim.Column{Value: &shim.Column_Uint32{Uint32: uint32(v.Uint())}}
+	case reflect.Uint64:
+		return &shim.Column{Value: &shim.Column_Uint64{Uint64: v.Uint()}}
+	}
+	return nil
+}
+
+// colunaTexto: coluna do tipo texto, utilizada nas chaves informadas como argumento
+func colunaTexto(valor string) shim.Column {
+	return shim.Column{Value: &shim.Column_String_{String_: valor}}
+}
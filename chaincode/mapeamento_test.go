@@ -0,0 +1,92 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func TestMapeamentoProposta(t *testing.T) {
+	m := mapeamentoProposta
+
+	// As colunas mantêm o esquema da tabela 'Proposta' criada pelas versões anteriores
+	esperado := []shim.ColumnDefinition{
+		{Name: "Id", Type: shim.ColumnDefinition_STRING, Key: true},
+		{Name: "cpfPagador", Type: shim.ColumnDefinition_STRING},
+		{Name: "pagadorAceitou", Type: shim.ColumnDefinition_BOOL},
+		{Name: "beneficiarioAceitou", Type: shim.ColumnDefinition_BOOL},
+		{Name: "boletoPago", Type: shim.ColumnDefinition_BOOL},
+		{Name: "valor", Type: shim.ColumnDefinition_INT64},
+		{Name: "versao", Type: shim.ColumnDefinition_UINT64},
+		{Name: "cancelada", Type: shim.ColumnDefinition_BOOL},
+	}
+	definicoes := m.definicoes()
+	if len(definicoes) != len(esperado) {
+		t.Fatalf("%d colunas; esperado %d", len(definicoes), len(esperado))
+	}
+	for i, d := range definicoes {
+		if *d != esperado[i] {
+			t.Errorf("coluna %d = %+v; esperado %+v", i, *d, esperado[i])
+		}
+	}
+	if indices := m.indices(); len(indices) != 1 || indices[0].coluna != colCpfPagador {
+		t.Errorf("índices = %+v", indices)
+	}
+
+	proposta := Proposta{ID: "p1", CpfPagador: "111", BeneficiarioAceitou: true, Valor: 15000}
+	row := m.linha(proposta)
+	if row.Columns[3].GetBool() != true || row.Columns[5].GetInt64() != 15000 {
+		t.Errorf("linha = %+v", row.Columns)
+	}
+	var decodificada Proposta
+	if err := m.decodificar(row, &decodificada); err != nil {
+		t.Fatal(err)
+	}
+	if decodificada != proposta {
+		t.Errorf("decodificada = %+v; esperado %+v", decodificada, proposta)
+	}
+
+	// Linhas gravadas antes da inclusão de uma coluna mantêm o valor zero no campo
+	row.Columns = row.Columns[:5]
+	decodificada = Proposta{}
+	m.decodificar(row, &decodificada)
+	if decodificada.Valor != 0 || decodificada.CpfPagador != "111" {
+		t.Errorf("linha sem a coluna valor = %+v", decodificada)
+	}
+
+	if err := m.decodificar(row, decodificada); err == nil {
+		t.Error("decodificar sem ponteiro: esperado erro")
+	}
+}
+
+func TestMapearTagsInvalidas(t *testing.T) {
+	casos := []struct {
+		nome   string
+		modelo interface{}
+	}{
+		{"sem chave", struct {
+			A string `coluna:"a"`
+		}{}},
+		{"opção inválida", struct {
+			A string `coluna:"a,chave,unica"`
+		}{}},
+		{"tipo não suportado", struct {
+			A string  `coluna:"a,chave"`
+			B float64 `coluna:"b"`
+		}{}},
+	}
+
+	for _, c := range casos {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: esperado panic para %v", c.nome, reflect.TypeOf(c.modelo))
+				}
+			}()
+			mapear("Teste", c.modelo)
+		}()
+	}
+}
@@ -0,0 +1,889 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// idiomas das mensagens
+const (
+	idiomaPtBR     = "pt-BR"
+	idiomaEn       = "en"
+	idiomaPadrao   = idiomaPtBR
+	argIdioma      = "idioma=" // argumento opcional, ao final dos argumentos (ex.: "idioma=en")
+	atributoIdioma = "idioma"  // atributo do certificado do caller, utilizado quando o argumento não é informado
+)
+
+// chaves do catálogo de mensagens
+const (
+	// Chamadas e argumentos
+	msgInvokeDesconhecida             = "invoke_desconhecida"
+	msgQueryDesconhecida              = "query_desconhecida"
+	msgQuantidadeArgumentos           = "quantidade_argumentos"
+	msgQuantidadeArgumentosObservacao = "quantidade_argumentos_observacao"
+	msgIdGeradoPeloChaincode          = "id_gerado_pelo_chaincode"
+	msgArgumentoInvalido              = "argumento_invalido"
+	msgQuantidadeOu                   = "quantidade_ou"
+	msgQuantidadeMinima               = "quantidade_minima"
+	msgIdiomaNaoSuportado             = "idioma_nao_suportado"
+
+	// Init
+	msgModuloDesconhecido        = "modulo_desconhecido"
+	msgArmazenamentoDesconhecido = "armazenamento_desconhecido"
+	msgNivelLogDesconhecido      = "nivel_log_desconhecido"
+	msgFalhaObterModulos         = "falha_obter_modulos"
+	msgFalhaDecodificarModulos   = "falha_decodificar_modulos"
+	msgFalhaCodificarModulos     = "falha_codificar_modulos"
+	msgFalhaRegistrarModulos     = "falha_registrar_modulos"
+	msgEsquemaPosterior          = "esquema_posterior"
+
+	// Autenticação
+	msgFalhaObterAdmin     = "falha_obter_admin"
+	msgFalhaVerificarAdmin = "falha_verificar_admin"
+	msgCallerNaoAdmin      = "caller_nao_admin"
+	msgFalhaObterMetadata  = "falha_obter_metadata"
+	msgCertificadoInvalido = "certificado_invalido"
+
+	// Pausa
+	msgChaincodePausado    = "chaincode_pausado"
+	msgChaincodeJaPausado  = "chaincode_ja_pausado"
+	msgChaincodeNaoPausado = "chaincode_nao_pausado"
+	msgMotivoNaoInformado  = "motivo_nao_informado"
+
+	// Propostas
+	msgPropostaJaExistente         = "proposta_ja_existente"
+	msgPropostaExistenteSemVersao  = "proposta_existente_sem_versao"
+	msgPropostaNaoExistente        = "proposta_nao_existente"
+	msgPropostaCancelada           = "proposta_cancelada"
+	msgConflitoVersao              = "conflito_versao"
+	msgRegistroInvalido            = "registro_invalido"
+	msgRegistroConteudoAposFim     = "registro_conteudo_apos_fim"
+	msgRegistroCampoObrigatorio    = "registro_campo_obrigatorio"
+	msgRegistroValorNegativo       = "registro_valor_negativo"
+	msgPagamentoPorOraculo         = "pagamento_por_oraculo"
+	msgFalhaRegistrarProposta      = "falha_registrar_proposta"
+	msgFalhaRegistrarPropostaDupla = "falha_registrar_proposta_dupla"
+	msgFalhaAtualizarProposta      = "falha_atualizar_proposta"
+	msgFalhaObterProposta          = "falha_obter_proposta"
+	msgFalhaCodificarProposta      = "falha_codificar_proposta"
+
+	// Cancelamento e estorno
+	msgCallerNaoParte             = "caller_nao_parte"
+	msgCancelamentoPropostaPaga   = "cancelamento_proposta_paga"
+	msgCancelamentoSomenteBenef   = "cancelamento_somente_beneficiario"
+	msgCancelamentoJaSolicitado   = "cancelamento_ja_solicitado"
+	msgPagamentoSomenteEstorno    = "pagamento_somente_estorno"
+	msgMotivoEstornoInvalido      = "motivo_estorno_invalido"
+	msgAssinaturaOraculoAusente   = "assinatura_oraculo_ausente"
+	msgPagamentoNaoConfirmado     = "pagamento_nao_confirmado"
+	msgOraculoNaoAtestou          = "oraculo_nao_atestou"
+	msgAssinaturaAtestadoInvalida = "assinatura_atestado_invalida"
+	msgAssinaturaEstornoInvalida  = "assinatura_estorno_invalida"
+
+	// Configuração
+	msgURLExternaInvalida          = "url_externa_invalida"
+	msgAlgoritmoSegurancaInvalido  = "algoritmo_seguranca_invalido"
+	msgNivelSegurancaInvalido      = "nivel_seguranca_invalido"
+	msgMaximosPropostasInvalidos   = "maximos_propostas_invalidos"
+	msgPrazoIdempotenciaNegativo   = "prazo_idempotencia_negativo"
+	msgConfiguracaoInvalida        = "configuracao_invalida"
+	msgConfiguracaoConteudoAposFim = "configuracao_conteudo_apos_fim"
+	msgConflitoVersaoConfiguracao  = "conflito_versao_configuracao"
+
+	// Consultas, lotes e idempotência
+	msgMaximoConsultaExcedido  = "maximo_consulta_excedido"
+	msgLoteInvalido            = "lote_invalido"
+	msgLoteVazio               = "lote_vazio"
+	msgMaximoLoteExcedido      = "maximo_lote_excedido"
+	msgPropostaRepetidaLote    = "proposta_repetida_lote"
+	msgLoteRejeitado           = "lote_rejeitado"
+	msgChaveIdempotenciaVazia  = "chave_idempotencia_vazia"
+	msgIdempotenciaConflitante = "idempotencia_conflitante"
+
+	// Notificação
+	msgModuloNaoHabilitado   = "modulo_nao_habilitado"
+	msgAssinanteNaoInformado = "assinante_nao_informado"
+	msgStatusEntregaInvalido = "status_entrega_invalido"
+	msgEventoNaoExistente    = "evento_nao_existente"
+
+	// Oráculos
+	msgOraculoNaoInformado       = "oraculo_nao_informado"
+	msgOraculoJaRegistrado       = "oraculo_ja_registrado"
+	msgOraculoNaoExistente       = "oraculo_nao_existente"
+	msgOraculoNaoRegistrado      = "oraculo_nao_registrado"
+	msgQuorumMaiorRestantes      = "quorum_maior_restantes"
+	msgQuorumMaiorRegistrados    = "quorum_maior_registrados"
+	msgAtestadoJaUtilizado       = "atestado_ja_utilizado"
+	msgPropostaJaPaga            = "proposta_ja_paga"
+	msgValorPagoDivergente       = "valor_pago_divergente"
+	msgOraculoJaAtestou          = "oraculo_ja_atestou"
+	msgSemAtestadosPendentes     = "sem_atestados_pendentes"
+	msgAtestadoInvalido          = "atestado_invalido"
+	msgAtestadoNaoCanonico       = "atestado_nao_canonico"
+	msgAtestadoIncompleto        = "atestado_incompleto"
+	msgValorPagoInvalido         = "valor_pago_invalido"
+	msgDataPagamentoInvalida     = "data_pagamento_invalida"
+	msgAssinaturaNaoDecodificada = "assinatura_nao_decodificada"
+	msgChavePublicaInvalida      = "chave_publica_invalida"
+	msgChavePublicaNaoECDSA      = "chave_publica_nao_ecdsa"
+
+	// Falhas internas (estado/ledger)
+	msgFalhaObterCancelamento              = "falha_obter_cancelamento"
+	msgFalhaGravarCancelamento             = "falha_gravar_cancelamento"
+	msgFalhaObterConfiguracao              = "falha_obter_configuracao"
+	msgFalhaGravarConfiguracao             = "falha_gravar_configuracao"
+	msgFalhaGravarHistoricoConfiguracao    = "falha_gravar_historico_configuracao"
+	msgFalhaObterMaxConsulta               = "falha_obter_max_consulta"
+	msgMaxConsultaGravadoInvalido          = "max_consulta_gravado_invalido"
+	msgFalhaExcluirMaxConsulta             = "falha_excluir_max_consulta"
+	msgFalhaConsultarHistoricoConfiguracao = "falha_consultar_historico_configuracao"
+	msgFalhaObterVersaoConfiguracao        = "falha_obter_versao_configuracao"
+	msgFalhaAplicarNivelSeguranca          = "falha_aplicar_nivel_seguranca"
+	msgFalhaCodificarResposta              = "falha_codificar_resposta"
+	msgFalhaCodificarCatalogoErros         = "falha_codificar_catalogo_erros"
+	msgFalhaCodificarFuncoes               = "falha_codificar_funcoes"
+	msgFalhaObterVersaoEsquema             = "falha_obter_versao_esquema"
+	msgVersaoEsquemaInvalida               = "versao_esquema_invalida"
+	msgFalhaRegistrarVersaoEsquema         = "falha_registrar_versao_esquema"
+	msgFalhaMigracao                       = "falha_migracao"
+	msgFalhaMoverProposta                  = "falha_mover_proposta"
+	msgFalhaLerTabela                      = "falha_ler_tabela"
+	msgFalhaExcluirTabela                  = "falha_excluir_tabela"
+	msgFalhaCriarTabela                    = "falha_criar_tabela"
+	msgFalhaGravarLinha                    = "falha_gravar_linha"
+	msgFalhaRegistrarEstorno               = "falha_registrar_estorno"
+	msgFalhaRemoverPagamento               = "falha_remover_pagamento"
+	msgFalhaObterEstornos                  = "falha_obter_estornos"
+	msgFalhaObterPagamento                 = "falha_obter_pagamento"
+	msgFalhaObterIdempotencia              = "falha_obter_idempotencia"
+	msgFalhaObterTimestamp                 = "falha_obter_timestamp"
+	msgFalhaRegistrarIdempotencia          = "falha_registrar_idempotencia"
+	msgDestinoTabelaInvalido               = "destino_tabela_invalido"
+	msgFalhaRegistrarEvento                = "falha_registrar_evento"
+	msgFalhaRegistrarSequenciaEventos      = "falha_registrar_sequencia_eventos"
+	msgFalhaObterEvento                    = "falha_obter_evento"
+	msgFalhaObterSequenciaEventos          = "falha_obter_sequencia_eventos"
+	msgFalhaRegistrarEntrega               = "falha_registrar_entrega"
+	msgFalhaObterEntregas                  = "falha_obter_entregas"
+	msgFalhaGravarQuorum                   = "falha_gravar_quorum"
+	msgFalhaVerificarAtestado              = "falha_verificar_atestado"
+	msgFalhaRegistrarAtestado              = "falha_registrar_atestado"
+	msgFalhaRegistrarPagamento             = "falha_registrar_pagamento"
+	msgFalhaRemoverAtestados               = "falha_remover_atestados"
+	msgFalhaCodificarDocumentoAssinado     = "falha_codificar_documento_assinado"
+	msgFalhaObterOraculos                  = "falha_obter_oraculos"
+	msgFalhaGravarOraculos                 = "falha_gravar_oraculos"
+	msgFalhaObterQuorum                    = "falha_obter_quorum"
+	msgFalhaObterAtestados                 = "falha_obter_atestados"
+	msgFalhaGravarAtestados                = "falha_gravar_atestados"
+	msgFalhaObterPausa                     = "falha_obter_pausa"
+	msgFalhaGravarPausa                    = "falha_gravar_pausa"
+	msgFalhaObterPropostasCpf              = "falha_obter_propostas_cpf"
+	msgFalhaContarLinhas                   = "falha_contar_linhas"
+	msgFalhaContarPropostas                = "falha_contar_propostas"
+	msgFalhaCodificarRegistro              = "falha_codificar_registro"
+	msgFalhaDecodificarRegistro            = "falha_decodificar_registro"
+	msgIndiceDesconhecido                  = "indice_desconhecido"
+	msgFalhaRegistrarEntregaSemErro        = "falha_registrar_entrega_sem_erro"
+)
+
+// mensagens: catálogo de mensagens, com o formato (fmt) de cada idioma
+var mensagens = map[string]map[string]string{
+	msgInvokeDesconhecida: {
+		idiomaPtBR: "Invocação de função desconhecida: %s",
+		idiomaEn:   "Unknown invoke function: %s",
+	},
+	msgQueryDesconhecida: {
+		idiomaPtBR: "Query de função desconhecida: %s",
+		idiomaEn:   "Unknown query function: %s",
+	},
+	msgQuantidadeArgumentos: {
+		idiomaPtBR: "Quantidade de argumentos incorreta. Esperado %s",
+		idiomaEn:   "Incorrect number of arguments. Expecting %s",
+	},
+	msgQuantidadeArgumentosObservacao: {
+		idiomaPtBR: "Quantidade de argumentos incorreta. Esperado %s (%s)",
+		idiomaEn:   "Incorrect number of arguments. Expecting %s (%s)",
+	},
+	msgIdGeradoPeloChaincode: {
+		idiomaPtBR: "o Id da proposta é gerado pelo chaincode",
+		idiomaEn:   "the proposal Id is generated by the chaincode",
+	},
+	msgArgumentoInvalido: {
+		idiomaPtBR: "Falha ao decodificar %s",
+		idiomaEn:   "Failed decoding %s",
+	},
+	msgQuantidadeOu: {
+		idiomaPtBR: "%s ou %s",
+		idiomaEn:   "%s or %s",
+	},
+	msgQuantidadeMinima: {
+		idiomaPtBR: "pelo menos %d",
+		idiomaEn:   "at least %d",
+	},
+	msgIdiomaNaoSuportado: {
+		idiomaPtBR: "Idioma não suportado: %s",
+		idiomaEn:   "Unsupported language: %s",
+	},
+	msgModuloDesconhecido: {
+		idiomaPtBR: "Módulo desconhecido: %s",
+		idiomaEn:   "Unknown module: %s",
+	},
+	msgArmazenamentoDesconhecido: {
+		idiomaPtBR: "Armazenamento desconhecido: %s",
+		idiomaEn:   "Unknown storage: %s",
+	},
+	msgNivelLogDesconhecido: {
+		idiomaPtBR: "Nível de log desconhecido: %s (debug, info, aviso ou erro)",
+		idiomaEn:   "Unknown log level: %s (debug, info, aviso or erro)",
+	},
+	msgFalhaObterModulos: {
+		idiomaPtBR: "Falha ao obter os módulos: [%s]",
+		idiomaEn:   "Failed fetching modules: [%s]",
+	},
+	msgFalhaDecodificarModulos: {
+		idiomaPtBR: "Falha ao decodificar os módulos: [%s]",
+		idiomaEn:   "Failed decoding modules: [%s]",
+	},
+	msgFalhaCodificarModulos: {
+		idiomaPtBR: "Falha ao codificar os módulos: [%s]",
+		idiomaEn:   "Failed encoding modules: [%s]",
+	},
+	msgFalhaRegistrarModulos: {
+		idiomaPtBR: "Falha ao registrar os módulos: [%s]",
+		idiomaEn:   "Failed storing modules: [%s]",
+	},
+	msgEsquemaPosterior: {
+		idiomaPtBR: "Versão do esquema [%d] posterior à suportada pelo chaincode [%d]",
+		idiomaEn:   "Schema version [%d] is newer than the one supported by the chaincode [%d]",
+	},
+	msgFalhaObterAdmin: {
+		idiomaPtBR: "Falha ao obter a identidade do administrador",
+		idiomaEn:   "Failed fetching admin identity",
+	},
+	msgFalhaVerificarAdmin: {
+		idiomaPtBR: "Falha ao verificar a identidade do administrador",
+		idiomaEn:   "Failed checking admin identity",
+	},
+	msgCallerNaoAdmin: {
+		idiomaPtBR: "O caller não é o administrador",
+		idiomaEn:   "The caller is not an administrator",
+	},
+	msgFalhaObterMetadata: {
+		idiomaPtBR: "Falha ao obter a metadata do caller",
+		idiomaEn:   "Failed getting metadata",
+	},
+	msgCertificadoInvalido: {
+		idiomaPtBR: "Certificado inválido",
+		idiomaEn:   "Invalid certificate",
+	},
+	msgChaincodePausado: {
+		idiomaPtBR: "Chaincode pausado: alterações rejeitadas até ser retomado. Motivo: %s",
+		idiomaEn:   "Chaincode paused: changes rejected until it is resumed. Reason: %s",
+	},
+	msgMotivoNaoInformado: {
+		idiomaPtBR: "não informado",
+		idiomaEn:   "not informed",
+	},
+	msgPropostaJaExistente: {
+		idiomaPtBR: "Proposta [%s] já existente.",
+		idiomaEn:   "Proposal [%s] already exists.",
+	},
+	msgPropostaExistenteSemVersao: {
+		idiomaPtBR: "Proposta [%s] já existente. Informe a versão esperada para atualizá-la",
+		idiomaEn:   "Proposal [%s] already exists. Provide the expected version to update it",
+	},
+	msgPropostaNaoExistente: {
+		idiomaPtBR: "Proposta [%s] não existente.",
+		idiomaEn:   "Proposal [%s] not found.",
+	},
+	msgPropostaCancelada: {
+		idiomaPtBR: "Proposta [%s] cancelada.",
+		idiomaEn:   "Proposal [%s] cancelled.",
+	},
+	msgConflitoVersao: {
+		idiomaPtBR: "Conflito de versão na Proposta [%s]: versão esperada [%d], versão atual [%d]",
+		idiomaEn:   "Version conflict on Proposal [%s]: expected version [%d], current version [%d]",
+	},
+	msgRegistroInvalido: {
+		idiomaPtBR: "Registro da proposta inválido: %s",
+		idiomaEn:   "Invalid proposal record: %s",
+	},
+	msgRegistroConteudoAposFim: {
+		idiomaPtBR: "Registro da proposta inválido: conteúdo após o fim do documento",
+		idiomaEn:   "Invalid proposal record: content after the end of the document",
+	},
+	msgRegistroCampoObrigatorio: {
+		idiomaPtBR: "Registro da proposta inválido: campo %s obrigatório",
+		idiomaEn:   "Invalid proposal record: field %s is required",
+	},
+	msgRegistroValorNegativo: {
+		idiomaPtBR: "Registro da proposta inválido: valor negativo",
+		idiomaEn:   "Invalid proposal record: negative valor",
+	},
+	msgPagamentoPorOraculo: {
+		idiomaPtBR: "O pagamento do boleto deve ser confirmado por um oráculo",
+		idiomaEn:   "The boleto payment must be confirmed by an oracle",
+	},
+	msgFalhaRegistrarProposta: {
+		idiomaPtBR: "Falha ao registrar a Proposta nº %s: %v",
+		idiomaEn:   "Failed storing Proposal %s: %v",
+	},
+	msgFalhaRegistrarPropostaDupla: {
+		idiomaPtBR: "Falha ao registrar a Proposta nº %s: proposta já existente",
+		idiomaEn:   "Failed storing Proposal %s: proposal already exists",
+	},
+	msgFalhaAtualizarProposta: {
+		idiomaPtBR: "Falha ao atualizar a Proposta nº %s: %v",
+		idiomaEn:   "Failed updating Proposal %s: %v",
+	},
+	msgFalhaObterProposta: {
+		idiomaPtBR: "Erro ao obter Proposta [%s]: [%s]",
+		idiomaEn:   "Failed fetching Proposal [%s]: [%s]",
+	},
+	msgFalhaCodificarProposta: {
+		idiomaPtBR: "Falha ao codificar a Proposta: %s",
+		idiomaEn:   "Failed encoding the Proposal: %s",
+	},
+	msgCallerNaoParte: {
+		idiomaPtBR: "O caller não é o %s da Proposta [%s].",
+		idiomaEn:   "The caller is not the %s of Proposal [%s].",
+	},
+	msgCancelamentoPropostaPaga: {
+		idiomaPtBR: "Proposta [%s] já paga. O cancelamento é permitido somente antes do pagamento.",
+		idiomaEn:   "Proposal [%s] already paid. Cancellation is only allowed before payment.",
+	},
+	msgCancelamentoSomenteBenef: {
+		idiomaPtBR: "Proposta [%s] não aceita pelo pagador: o cancelamento é solicitado pelo beneficiário.",
+		idiomaEn:   "Proposal [%s] not accepted by the payer: cancellation is requested by the beneficiary.",
+	},
+	msgCancelamentoJaSolicitado: {
+		idiomaPtBR: "Cancelamento da Proposta [%s] já solicitado pelo %s.",
+		idiomaEn:   "Cancellation of Proposal [%s] already requested by the %s.",
+	},
+	msgPagamentoSomenteEstorno: {
+		idiomaPtBR: "Pagamento da Proposta [%s] desfeito somente com estornarPagamento.",
+		idiomaEn:   "Payment of Proposal [%s] can only be undone with estornarPagamento.",
+	},
+	msgMotivoEstornoInvalido: {
+		idiomaPtBR: "Motivo de estorno [%s] inválido. Esperado um de: %s",
+		idiomaEn:   "Invalid reversal reason [%s]. Expected one of: %s",
+	},
+	msgAssinaturaOraculoAusente: {
+		idiomaPtBR: "Assinatura do oráculo não informada",
+		idiomaEn:   "Oracle signature not provided",
+	},
+	msgPagamentoNaoConfirmado: {
+		idiomaPtBR: "Pagamento da Proposta [%s] não confirmado.",
+		idiomaEn:   "Payment of Proposal [%s] not confirmed.",
+	},
+	msgOraculoNaoAtestou: {
+		idiomaPtBR: "Oráculo [%s] não atestou o pagamento da Proposta [%s].",
+		idiomaEn:   "Oracle [%s] did not attest the payment of Proposal [%s].",
+	},
+	msgAssinaturaAtestadoInvalida: {
+		idiomaPtBR: "Assinatura do atestado inválida",
+		idiomaEn:   "Invalid attestation signature",
+	},
+	msgAssinaturaEstornoInvalida: {
+		idiomaPtBR: "Assinatura do estorno inválida",
+		idiomaEn:   "Invalid reversal signature",
+	},
+	msgURLExternaInvalida: {
+		idiomaPtBR: "URL externa inválida: %s",
+		idiomaEn:   "Invalid external URL: %s",
+	},
+	msgAlgoritmoSegurancaInvalido: {
+		idiomaPtBR: "Algoritmo de segurança [%s] inválido. Esperado SHA2 ou SHA3",
+		idiomaEn:   "Invalid security algorithm [%s]. Expected SHA2 or SHA3",
+	},
+	msgNivelSegurancaInvalido: {
+		idiomaPtBR: "Nível de segurança [%d] inválido. Esperado 256 ou 384",
+		idiomaEn:   "Invalid security level [%d]. Expected 256 or 384",
+	},
+	msgMaximosPropostasInvalidos: {
+		idiomaPtBR: "Os máximos de propostas por consulta e por lote devem ser maiores que zero",
+		idiomaEn:   "The maximum proposals per query and per batch must be greater than zero",
+	},
+	msgPrazoIdempotenciaNegativo: {
+		idiomaPtBR: "Prazo de idempotência [%d] negativo",
+		idiomaEn:   "Negative idempotency period [%d]",
+	},
+	msgConfiguracaoInvalida: {
+		idiomaPtBR: "Configuração inválida: %s",
+		idiomaEn:   "Invalid configuration: %s",
+	},
+	msgConfiguracaoConteudoAposFim: {
+		idiomaPtBR: "Configuração inválida: conteúdo após o fim do JSON",
+		idiomaEn:   "Invalid configuration: content after the end of the JSON",
+	},
+	msgConflitoVersaoConfiguracao: {
+		idiomaPtBR: "Configuração alterada por outra transação: versão esperada [%d], atual [%d].",
+		idiomaEn:   "Configuration changed by another transaction: expected version [%d], current [%d].",
+	},
+	msgMaximoConsultaExcedido: {
+		idiomaPtBR: "Quantidade de propostas [%d] maior que o máximo por consulta [%d].",
+		idiomaEn:   "Number of proposals [%d] greater than the maximum per query [%d].",
+	},
+	msgLoteInvalido: {
+		idiomaPtBR: "Lote inválido: %s",
+		idiomaEn:   "Invalid batch: %s",
+	},
+	msgLoteVazio: {
+		idiomaPtBR: "Lote vazio",
+		idiomaEn:   "Empty batch",
+	},
+	msgMaximoLoteExcedido: {
+		idiomaPtBR: "Quantidade de propostas [%d] maior que o máximo por lote [%d].",
+		idiomaEn:   "Number of proposals [%d] greater than the maximum per batch [%d].",
+	},
+	msgPropostaRepetidaLote: {
+		idiomaPtBR: "Proposta [%s] repetida no lote",
+		idiomaEn:   "Proposal [%s] repeated in the batch",
+	},
+	msgLoteRejeitado: {
+		idiomaPtBR: "Lote rejeitado",
+		idiomaEn:   "Batch rejected",
+	},
+	msgChaveIdempotenciaVazia: {
+		idiomaPtBR: "Chave de idempotência vazia",
+		idiomaEn:   "Empty idempotency key",
+	},
+	msgIdempotenciaConflitante: {
+		idiomaPtBR: "Chave de idempotência [%s] já utilizada com outros argumentos",
+		idiomaEn:   "Idempotency key [%s] already used with other arguments",
+	},
+	msgModuloNaoHabilitado: {
+		idiomaPtBR: "Módulo %s não habilitado",
+		idiomaEn:   "Module %s not enabled",
+	},
+	msgAssinanteNaoInformado: {
+		idiomaPtBR: "Assinante não informado",
+		idiomaEn:   "Subscriber not provided",
+	},
+	msgStatusEntregaInvalido: {
+		idiomaPtBR: "Status de entrega inválido: %s",
+		idiomaEn:   "Invalid delivery status: %s",
+	},
+	msgEventoNaoExistente: {
+		idiomaPtBR: "Evento [%s] não existente.",
+		idiomaEn:   "Event [%s] not found.",
+	},
+	msgOraculoNaoInformado: {
+		idiomaPtBR: "Oráculo não informado",
+		idiomaEn:   "Oracle not provided",
+	},
+	msgOraculoJaRegistrado: {
+		idiomaPtBR: "Oráculo [%s] já registrado.",
+		idiomaEn:   "Oracle [%s] already registered.",
+	},
+	msgOraculoNaoExistente: {
+		idiomaPtBR: "Oráculo [%s] não existente.",
+		idiomaEn:   "Oracle [%s] not found.",
+	},
+	msgOraculoNaoRegistrado: {
+		idiomaPtBR: "Oráculo [%s] não registrado.",
+		idiomaEn:   "Oracle [%s] not registered.",
+	},
+	msgQuorumMaiorRestantes: {
+		idiomaPtBR: "Quorum [%d] maior que a quantidade de oráculos restantes [%d].",
+		idiomaEn:   "Quorum [%d] greater than the number of remaining oracles [%d].",
+	},
+	msgQuorumMaiorRegistrados: {
+		idiomaPtBR: "Quorum [%d] maior que a quantidade de oráculos registrados [%d].",
+		idiomaEn:   "Quorum [%d] greater than the number of registered oracles [%d].",
+	},
+	msgAtestadoJaUtilizado: {
+		idiomaPtBR: "Atestado [%s] já utilizado pela Proposta [%s].",
+		idiomaEn:   "Attestation [%s] already used by Proposal [%s].",
+	},
+	msgPropostaJaPaga: {
+		idiomaPtBR: "Proposta [%s] já paga.",
+		idiomaEn:   "Proposal [%s] already paid.",
+	},
+	msgValorPagoDivergente: {
+		idiomaPtBR: "Valor pago [%d] diferente do valor da Proposta [%d].",
+		idiomaEn:   "Amount paid [%d] differs from the Proposal amount [%d].",
+	},
+	msgSemAtestadosPendentes: {
+		idiomaPtBR: "Proposta [%s] sem atestados pendentes.",
+		idiomaEn:   "Proposal [%s] has no pending attestations.",
+	},
+	msgOraculoJaAtestou: {
+		idiomaPtBR: "Oráculo [%s] já atestou o pagamento da Proposta [%s].",
+		idiomaEn:   "Oracle [%s] already attested the payment of Proposal [%s].",
+	},
+	msgAtestadoInvalido: {
+		idiomaPtBR: "Falha ao decodificar o atestado",
+		idiomaEn:   "Failed decoding attestation",
+	},
+	msgAtestadoNaoCanonico: {
+		idiomaPtBR: "Campos do atestado não correspondem ao JSON assinado",
+		idiomaEn:   "Attestation fields do not match the signed JSON",
+	},
+	msgAtestadoIncompleto: {
+		idiomaPtBR: "Atestado incompleto: id_oraculo, id_proposta e codigo_autenticacao são obrigatórios",
+		idiomaEn:   "Incomplete attestation: id_oraculo, id_proposta and codigo_autenticacao are required",
+	},
+	msgValorPagoInvalido: {
+		idiomaPtBR: "Valor pago inválido no atestado",
+		idiomaEn:   "Invalid amount paid in the attestation",
+	},
+	msgDataPagamentoInvalida: {
+		idiomaPtBR: "Data de pagamento inválida no atestado: %s",
+		idiomaEn:   "Invalid payment date in the attestation: %s",
+	},
+	msgAssinaturaNaoDecodificada: {
+		idiomaPtBR: "Falha ao decodificar a assinatura",
+		idiomaEn:   "Failed decoding signature",
+	},
+	msgChavePublicaInvalida: {
+		idiomaPtBR: "Chave pública do oráculo inválida",
+		idiomaEn:   "Invalid oracle public key",
+	},
+	msgChavePublicaNaoECDSA: {
+		idiomaPtBR: "Chave pública do oráculo não é ECDSA",
+		idiomaEn:   "Oracle public key is not ECDSA",
+	},
+	msgChaincodeJaPausado: {
+		idiomaPtBR: "Chaincode já pausado na transação [%s]",
+		idiomaEn:   "Chaincode already paused in transaction [%s]",
+	},
+	msgChaincodeNaoPausado: {
+		idiomaPtBR: "Chaincode não está pausado",
+		idiomaEn:   "Chaincode is not paused",
+	},
+	msgFalhaObterCancelamento: {
+		idiomaPtBR: "Falha ao obter o cancelamento da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed fetching the cancellation of Proposal [%s]: [%s]",
+	},
+	msgFalhaGravarCancelamento: {
+		idiomaPtBR: "Falha ao gravar o cancelamento da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed storing the cancellation of Proposal [%s]: [%s]",
+	},
+	msgFalhaObterConfiguracao: {
+		idiomaPtBR: "Falha ao obter a configuração: [%s]",
+		idiomaEn:   "Failed fetching the configuration: [%s]",
+	},
+	msgFalhaGravarConfiguracao: {
+		idiomaPtBR: "Falha ao gravar a configuração: [%s]",
+		idiomaEn:   "Failed storing the configuration: [%s]",
+	},
+	msgFalhaGravarHistoricoConfiguracao: {
+		idiomaPtBR: "Falha ao gravar o histórico da configuração: [%s]",
+		idiomaEn:   "Failed storing the configuration history: [%s]",
+	},
+	msgFalhaObterMaxConsulta: {
+		idiomaPtBR: "Falha ao obter o máximo de propostas por consulta: [%s]",
+		idiomaEn:   "Failed fetching the maximum proposals per query: [%s]",
+	},
+	msgMaxConsultaGravadoInvalido: {
+		idiomaPtBR: "Máximo de propostas por consulta inválido: %s",
+		idiomaEn:   "Invalid stored maximum proposals per query: %s",
+	},
+	msgFalhaExcluirMaxConsulta: {
+		idiomaPtBR: "Falha ao excluir o máximo de propostas por consulta: [%s]",
+		idiomaEn:   "Failed deleting the maximum proposals per query: [%s]",
+	},
+	msgFalhaConsultarHistoricoConfiguracao: {
+		idiomaPtBR: "Falha ao consultar o histórico da configuração: [%s]",
+		idiomaEn:   "Failed querying the configuration history: [%s]",
+	},
+	msgFalhaObterVersaoConfiguracao: {
+		idiomaPtBR: "Falha ao obter a versão [%s] da configuração: [%s]",
+		idiomaEn:   "Failed fetching version [%s] of the configuration: [%s]",
+	},
+	msgFalhaAplicarNivelSeguranca: {
+		idiomaPtBR: "Falha ao aplicar o nível de segurança %s: [%s]",
+		idiomaEn:   "Failed applying security level %s: [%s]",
+	},
+	msgFalhaCodificarResposta: {
+		idiomaPtBR: "Falha ao codificar a Resposta: %s",
+		idiomaEn:   "Failed encoding the Response: %s",
+	},
+	msgFalhaCodificarCatalogoErros: {
+		idiomaPtBR: "Falha ao codificar o catálogo de erros: %s",
+		idiomaEn:   "Failed encoding the error catalogue: %s",
+	},
+	msgFalhaCodificarFuncoes: {
+		idiomaPtBR: "Falha ao codificar as funções registradas: %s",
+		idiomaEn:   "Failed encoding the registered functions: %s",
+	},
+	msgFalhaObterVersaoEsquema: {
+		idiomaPtBR: "Falha ao obter a versão do esquema: [%s]",
+		idiomaEn:   "Failed fetching the schema version: [%s]",
+	},
+	msgVersaoEsquemaInvalida: {
+		idiomaPtBR: "Versão do esquema inválida: [%s]",
+		idiomaEn:   "Invalid schema version: [%s]",
+	},
+	msgFalhaRegistrarVersaoEsquema: {
+		idiomaPtBR: "Falha ao registrar a versão do esquema: [%s]",
+		idiomaEn:   "Failed storing the schema version: [%s]",
+	},
+	msgFalhaMigracao: {
+		idiomaPtBR: "Falha na migração para a versão %d: %v",
+		idiomaEn:   "Failed migrating to version %d: %v",
+	},
+	msgFalhaMoverProposta: {
+		idiomaPtBR: "Falha ao mover a Proposta nº %s: %v",
+		idiomaEn:   "Failed moving Proposal %s: %v",
+	},
+	msgFalhaLerTabela: {
+		idiomaPtBR: "Falha ao ler a tabela %s: [%v]",
+		idiomaEn:   "Failed reading table %s: [%v]",
+	},
+	msgFalhaExcluirTabela: {
+		idiomaPtBR: "Falha ao excluir a tabela %s: [%v]",
+		idiomaEn:   "Failed deleting table %s: [%v]",
+	},
+	msgFalhaCriarTabela: {
+		idiomaPtBR: "Falha ao criar a tabela %s: [%v]",
+		idiomaEn:   "Failed creating table %s: [%v]",
+	},
+	msgFalhaGravarLinha: {
+		idiomaPtBR: "Falha ao gravar a linha na tabela %s: [%v]",
+		idiomaEn:   "Failed writing the row to table %s: [%v]",
+	},
+	msgFalhaRegistrarEstorno: {
+		idiomaPtBR: "Falha ao registrar o estorno da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed storing the reversal of Proposal [%s]: [%s]",
+	},
+	msgFalhaRemoverPagamento: {
+		idiomaPtBR: "Falha ao remover o pagamento da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed removing the payment of Proposal [%s]: [%s]",
+	},
+	msgFalhaObterEstornos: {
+		idiomaPtBR: "Falha ao obter os estornos da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed fetching the reversals of Proposal [%s]: [%s]",
+	},
+	msgFalhaObterPagamento: {
+		idiomaPtBR: "Erro ao obter o pagamento da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed fetching the payment of Proposal [%s]: [%s]",
+	},
+	msgFalhaObterIdempotencia: {
+		idiomaPtBR: "Falha ao obter a chave de idempotência [%s]: [%s]",
+		idiomaEn:   "Failed fetching idempotency key [%s]: [%s]",
+	},
+	msgFalhaObterTimestamp: {
+		idiomaPtBR: "Falha ao obter o timestamp da transação: [%s]",
+		idiomaEn:   "Failed fetching the transaction timestamp: [%s]",
+	},
+	msgFalhaRegistrarIdempotencia: {
+		idiomaPtBR: "Falha ao registrar a chave de idempotência [%s]: [%s]",
+		idiomaEn:   "Failed storing idempotency key [%s]: [%s]",
+	},
+	msgDestinoTabelaInvalido: {
+		idiomaPtBR: "Destino inválido para a tabela %s: %T",
+		idiomaEn:   "Invalid destination for table %s: %T",
+	},
+	msgFalhaRegistrarEvento: {
+		idiomaPtBR: "Falha ao registrar o Evento [%d]: [%s]",
+		idiomaEn:   "Failed storing Event [%d]: [%s]",
+	},
+	msgFalhaRegistrarSequenciaEventos: {
+		idiomaPtBR: "Falha ao registrar a sequência de eventos: [%s]",
+		idiomaEn:   "Failed storing the event sequence: [%s]",
+	},
+	msgFalhaObterEvento: {
+		idiomaPtBR: "Falha ao obter o Evento [%v]: [%s]",
+		idiomaEn:   "Failed fetching Event [%v]: [%s]",
+	},
+	msgFalhaObterSequenciaEventos: {
+		idiomaPtBR: "Falha ao obter a sequência de eventos: [%s]",
+		idiomaEn:   "Failed fetching the event sequence: [%s]",
+	},
+	msgFalhaRegistrarEntrega: {
+		idiomaPtBR: "Falha ao registrar a Entrega do Evento [%s]: [%s]",
+		idiomaEn:   "Failed storing the delivery of Event [%s]: [%s]",
+	},
+	msgFalhaObterEntregas: {
+		idiomaPtBR: "Erro ao obter Entregas da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed fetching the deliveries of Proposal [%s]: [%s]",
+	},
+	msgFalhaGravarQuorum: {
+		idiomaPtBR: "Falha ao gravar o quorum de oráculos: [%s]",
+		idiomaEn:   "Failed storing the oracle quorum: [%s]",
+	},
+	msgFalhaVerificarAtestado: {
+		idiomaPtBR: "Falha ao verificar o atestado: [%s]",
+		idiomaEn:   "Failed checking the attestation: [%s]",
+	},
+	msgFalhaRegistrarAtestado: {
+		idiomaPtBR: "Falha ao registrar o atestado: [%s]",
+		idiomaEn:   "Failed storing the attestation: [%s]",
+	},
+	msgFalhaRegistrarPagamento: {
+		idiomaPtBR: "Falha ao registrar o pagamento: [%s]",
+		idiomaEn:   "Failed storing the payment: [%s]",
+	},
+	msgFalhaRemoverAtestados: {
+		idiomaPtBR: "Falha ao remover os atestados pendentes: [%s]",
+		idiomaEn:   "Failed removing the pending attestations: [%s]",
+	},
+	msgFalhaCodificarDocumentoAssinado: {
+		idiomaPtBR: "Falha ao codificar o documento assinado: %s",
+		idiomaEn:   "Failed encoding the signed document: %s",
+	},
+	msgFalhaObterOraculos: {
+		idiomaPtBR: "Falha ao obter os oráculos: [%s]",
+		idiomaEn:   "Failed fetching the oracles: [%s]",
+	},
+	msgFalhaGravarOraculos: {
+		idiomaPtBR: "Falha ao gravar os oráculos: [%s]",
+		idiomaEn:   "Failed storing the oracles: [%s]",
+	},
+	msgFalhaObterQuorum: {
+		idiomaPtBR: "Falha ao obter o quorum de oráculos: [%s]",
+		idiomaEn:   "Failed fetching the oracle quorum: [%s]",
+	},
+	msgFalhaObterAtestados: {
+		idiomaPtBR: "Falha ao obter os atestados da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed fetching the attestations of Proposal [%s]: [%s]",
+	},
+	msgFalhaGravarAtestados: {
+		idiomaPtBR: "Falha ao gravar os atestados da Proposta [%s]: [%s]",
+		idiomaEn:   "Failed storing the attestations of Proposal [%s]: [%s]",
+	},
+	msgFalhaObterPausa: {
+		idiomaPtBR: "Falha ao obter o estado de pausa: [%s]",
+		idiomaEn:   "Failed fetching the pause state: [%s]",
+	},
+	msgFalhaGravarPausa: {
+		idiomaPtBR: "Falha ao gravar o estado de pausa: [%s]",
+		idiomaEn:   "Failed storing the pause state: [%s]",
+	},
+	msgFalhaObterPropostasCpf: {
+		idiomaPtBR: "Erro ao obter as Propostas do CPF [%s]: [%s]",
+		idiomaEn:   "Failed fetching the Proposals of CPF [%s]: [%s]",
+	},
+	msgFalhaContarLinhas: {
+		idiomaPtBR: "Falha ao contar as linhas da tabela %s: [%s]",
+		idiomaEn:   "Failed counting the rows of table %s: [%s]",
+	},
+	msgFalhaContarPropostas: {
+		idiomaPtBR: "Falha ao contar as propostas: [%s]",
+		idiomaEn:   "Failed counting the Proposals: [%s]",
+	},
+	msgFalhaCodificarRegistro: {
+		idiomaPtBR: "Falha ao codificar %s: %s",
+		idiomaEn:   "Failed encoding %s: %s",
+	},
+	msgFalhaDecodificarRegistro: {
+		idiomaPtBR: "Falha ao decodificar %s: %s",
+		idiomaEn:   "Failed decoding %s: %s",
+	},
+	msgIndiceDesconhecido: {
+		idiomaPtBR: "Índice desconhecido: %s",
+		idiomaEn:   "Unknown index: %s",
+	},
+	msgFalhaRegistrarEntregaSemErro: {
+		idiomaPtBR: "Falha ao registrar a Entrega do Evento %s",
+		idiomaEn:   "Failed storing the delivery of Event %s",
+	},
+}
+
+// traduzivel - argumento de mensagem formatado conforme o idioma (ex.: quantidadesAceitas)
+type traduzivel interface {
+	traduzir(idioma string) string
+}
+
+// textoTraduzido - argumento de mensagem com a chave de outra mensagem do catálogo
+type textoTraduzido string
+
+// traduzir: a mensagem da chave no idioma informado
+func (t textoTraduzido) traduzir(idioma string) string {
+	return traduzir(idioma, string(t))
+}
+
+// traduzir: formata a mensagem do catálogo no idioma informado, ou no idioma padrão caso ela não
+// tenha tradução. Chaves fora do catálogo são utilizadas como o próprio formato, sem tradução
+func traduzir(idioma string, chave string, args ...interface{}) string {
+	formato := chave
+	if formatos, ok := mensagens[chave]; ok {
+		formato, ok = formatos[idioma]
+		if !ok {
+			formato = formatos[idiomaPadrao]
+		}
+	}
+	valores := make([]interface{}, len(args))
+	for i, a := range args {
+		if tr, ok := a.(traduzivel); ok {
+			valores[i] = tr.traduzir(idioma)
+		} else {
+			valores[i] = a
+		}
+	}
+	return fmt.Sprintf(formato, valores...)
+}
+
+// normalizarIdioma: converte o idioma informado em um dos idiomas do catálogo ("en-US" -> "en").
+// Retorna "" caso o idioma não seja suportado
+func normalizarIdioma(idioma string) string {
+	idioma = strings.ToLower(idioma)
+	switch {
+	case idioma == "pt" || strings.HasPrefix(idioma, "pt-"):
+		return idiomaPtBR
+	case idioma == "en" || strings.HasPrefix(idioma, "en-"):
+		return idiomaEn
+	}
+	return ""
+}
+
+// lerIdioma: retira o argumento "idioma=" dos argumentos opcionais ao final da chamada (ver argIdempotencia)
+// e retorna o idioma das mensagens. Sem o argumento, é utilizado o atributo "idioma" do certificado do caller
+func lerIdioma(stub shim.ChaincodeStubInterface, args []string) (string, []string, error) {
+	for i := len(args) - 1; i >= 0; i-- {
+		if strings.HasPrefix(args[i], argIdioma) {
+			valor := strings.TrimPrefix(args[i], argIdioma)
+			restantes := append(append([]string{}, args[:i]...), args[i+1:]...)
+			idioma := normalizarIdioma(valor)
+			if idioma == "" {
+				return idiomaPadrao, restantes, novoErro(codigoArgumentosInvalidos, msgIdiomaNaoSuportado, valor)
+			}
+			return idioma, restantes, nil
+		}
+		if !strings.HasPrefix(args[i], argIdempotencia) {
+			break
+		}
+	}
+
+	atributo, err := stub.ReadCertAttribute(atributoIdioma)
+	if err == nil {
+		if idioma := normalizarIdioma(string(atributo)); idioma != "" {
+			return idioma, args, nil
+		}
+	}
+	return idiomaPadrao, args, nil
+}
+
+// idiomaChamada: retorna o idioma das mensagens da chamada em andamento (ver lerIdioma), para as funções
+// que traduzem erros incluídos nos dados da resposta
+func idiomaChamada(stub shim.ChaincodeStubInterface) string {
+	args := stub.GetStringArgs()
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	idioma, _, _ := lerIdioma(stub, args)
+	return idioma
+}
@@ -0,0 +1,112 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// ============================================================================================================================
+// Mensagens
+// ============================================================================================================================
+
+func TestCatalogoMensagens(t *testing.T) {
+	for chave, formatos := range mensagens {
+		for _, idioma := range []string{idiomaPtBR, idiomaEn} {
+			if formatos[idioma] == "" {
+				t.Errorf("mensagem %s sem tradução para %s", chave, idioma)
+			}
+		}
+	}
+
+	casos := []struct{ idioma, esperado string }{
+		{idiomaPtBR, idiomaPtBR},
+		{"pt", idiomaPtBR},
+		{"EN-us", idiomaEn},
+		{"es", ""},
+	}
+	for _, c := range casos {
+		if idioma := normalizarIdioma(c.idioma); idioma != c.esperado {
+			t.Errorf("normalizarIdioma(%s) = %q; esperado %q", c.idioma, idioma, c.esperado)
+		}
+	}
+}
+
+func TestIdiomaMensagens(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+
+	// Padrão pt-BR, argumento "idioma=" ou atributo "idioma" do caller
+	_, err := stub.MockQuery(cc, "consultarProposta", "p9")
+	verificarErro(t, "padrão", err, "Proposta [p9] não existente.")
+	_, err = stub.MockQuery(cc, "consultarProposta", "p9", "idioma=en")
+	verificarErro(t, "argumento", err, "Proposal [p9] not found.")
+	verificarCodigo(t, "argumento", err, codigoPropostaNaoEncontrada)
+
+	stub.Attributes = map[string][]byte{atributoIdioma: []byte("en-US")}
+	_, err = stub.MockQuery(cc, "consultarProposta", "p9")
+	verificarErro(t, "atributo", err, "Proposal [p9] not found.")
+	_, err = stub.MockQuery(cc, "consultarProposta", "p9", "idioma=pt-BR")
+	verificarErro(t, "argumento sobre o atributo", err, "Proposta [p9] não existente.")
+	stub.Attributes = nil
+
+	// A quantidade de argumentos também é descrita no idioma escolhido
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p2", "222", "idioma=en")
+	verificarErro(t, "quantidade", err, "Incorrect number of arguments. Expecting 1, 5, 6 or 7")
+	_, err = stub.MockInvoke(cc, "criarProposta", "idioma=en")
+	verificarErro(t, "observação", err, "Expecting 4 or 5 (the proposal Id is generated by the chaincode)")
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "0", "9", "idioma=en")
+	verificarErro(t, "conflito", err, "Version conflict on Proposal [p1]: expected version [9], current version [1]")
+	_, err = stub.MockInit(cc, "init", "x", "idioma=en")
+	verificarErro(t, "init", err, "Unknown module: x")
+
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false", "idioma=en")
+	verificarErro(t, "isCaller", err, "Failed checking admin identity")
+	stub.CallerMetadata = []byte(adminTeste)
+
+	// O idioma pode ser informado antes ou depois da chave de idempotência
+	args := []string{"p2", "222", "true", "false", "false", "idioma=en", "idempotencia=k1"}
+	if _, err := stub.MockInvoke(cc, "registrarProposta", args...); err != nil {
+		t.Fatalf("registrarProposta com idioma e idempotência: %v", err)
+	}
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false", "idempotencia=k1", "idioma=pt"); err != nil {
+		t.Errorf("repetição com o idioma após a chave: %v", err)
+	}
+
+	// Os erros de cada Id de consultarPropostas e de cada item do lote também seguem o idioma
+	res, err := stub.MockQuery(cc, "consultarPropostas", "p1", "p9", "idioma=en")
+	if err != nil {
+		t.Fatalf("consultarPropostas: %v", err)
+	}
+	var resultados map[string]ResultadoConsulta
+	if err := lerDados(res, &resultados); err != nil || resultados["p9"].Erro != "Proposal [p9] not found." {
+		t.Errorf("consultarPropostas em inglês = %s", res)
+	}
+	lote := "[" + registroLote("p3", "333", "") + "," + registroLote("p3", "333", "") + "]"
+	_, err = stub.MockInvoke(cc, "registrarPropostasEmLote", lote, "idioma=en")
+	verificarErro(t, "lote", err, "Batch rejected")
+	var relatorio RelatorioLote
+	if e := json.Unmarshal(lerErro(err).Detalhes, &relatorio); e != nil || relatorio.Resultados[1].Motivo != "Proposal [p3] repeated in the batch" {
+		t.Errorf("relatório do lote em inglês = %+v", relatorio)
+	}
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", "[]", "x", "idioma=en")
+	verificarErro(t, "atestado", err, "Failed decoding attestation")
+	_, err = stub.MockInvoke(cc, "removerOraculo", "banco-9", "idioma=en")
+	verificarErro(t, "oráculo", err, "Oracle [banco-9] not found.")
+	_, err = stub.MockInvoke(cc, "retomar", "idioma=en")
+	verificarErro(t, "pausa", err, "Chaincode is not paused")
+
+	// As falhas internas também são do catálogo, com o erro do estado ao final
+	stub.SimularFalha("GetRows", errors.New("falha simulada"))
+	_, err = stub.MockQuery(cc, "consultarPropostasPorCpf", "111", "idioma=en")
+	verificarErro(t, "falha interna", err, "Failed fetching the Proposals of CPF [111]: [falha simulada]")
+	verificarCodigo(t, "falha interna", err, codigoErroInterno)
+	stub.SimularFalha("GetRows", nil)
+
+	_, err = stub.MockQuery(cc, "consultarProposta", "p1", "idioma=es")
+	verificarErro(t, "idioma não suportado", err, "Idioma não suportado: es")
+	verificarCodigo(t, "idioma não suportado", err, codigoArgumentosInvalidos)
+}
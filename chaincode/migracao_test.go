@@ -0,0 +1,178 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"testing"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/shimtest"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// versaoEsquema: executa consultarVersaoEsquema e decodifica o resultado
+func versaoEsquema(t *testing.T, stub *shimtest.Stub, cc *BoletoPropostaChaincode) VersaoEsquema {
+	res, err := stub.MockQuery(cc, "consultarVersaoEsquema")
+	if err != nil {
+		t.Fatalf("consultarVersaoEsquema: %v", err)
+	}
+	var v VersaoEsquema
+	lerDados(res, &v)
+	return v
+}
+
+// criarTabelaV1: tabela 'Proposta' como criada pelas primeiras versões, com a chave "id" e sem a coluna valor
+func criarTabelaV1(t *testing.T, stub *shimtest.Stub) {
+	texto := func(s string) *shim.Column { return &shim.Column{Value: &shim.Column_String_{String_: s}} }
+	booleano := func(b bool) *shim.Column { return &shim.Column{Value: &shim.Column_Bool{Bool: b}} }
+
+	stub.CreateTable(nomeTabelaProposta, []*shim.ColumnDefinition{
+		{Name: "id", Type: shim.ColumnDefinition_STRING, Key: true},
+		{Name: "cpfPagador", Type: shim.ColumnDefinition_STRING},
+		{Name: "pagadorAceitou", Type: shim.ColumnDefinition_BOOL},
+		{Name: "beneficiarioAceitou", Type: shim.ColumnDefinition_BOOL},
+		{Name: "boletoPago", Type: shim.ColumnDefinition_BOOL},
+	})
+	for _, id := range []string{"p1", "p2"} {
+		ok, err := stub.InsertRow(nomeTabelaProposta, shim.Row{Columns: []*shim.Column{
+			texto(id), texto("111"), booleano(true), booleano(id == "p2"), booleano(false),
+		}})
+		if err != nil || !ok {
+			t.Fatalf("InsertRow(%s) = %t, %v", id, ok, err)
+		}
+	}
+}
+
+func TestMigracaoEsquema(t *testing.T) {
+	stub := shimtest.NewStub()
+	stub.CallerMetadata = []byte(adminTeste)
+	cc := new(BoletoPropostaChaincode)
+	criarTabelaV1(t, stub)
+
+	if v := versaoEsquema(t, stub, cc); v.Versao != 1 || v.VersaoAtual != versaoEsquemaAtual || v.Armazenamento != armazenamentoTabela {
+		t.Errorf("versão antes da migração = %+v", v)
+	}
+
+	if _, err := stub.MockInit(cc, "init", argMigrar, moduloAutenticacao); err != nil {
+		t.Fatalf("Init migrar: %v", err)
+	}
+	if v := versaoEsquema(t, stub, cc); v.Versao != versaoEsquemaAtual {
+		t.Errorf("versão após a migração = %+v", v)
+	}
+
+	// A tabela passa a ter as colunas do mapeamento, mantendo as propostas
+	tb, _ := stub.GetTable(nomeTabelaProposta)
+	definicoes := mapeamentoProposta.definicoes()
+	if len(tb.ColumnDefinitions) != len(definicoes) {
+		t.Fatalf("colunas após a migração = %d; esperado %d", len(tb.ColumnDefinitions), len(definicoes))
+	}
+	for i, d := range tb.ColumnDefinitions {
+		if *d != *definicoes[i] {
+			t.Errorf("coluna %d = %+v; esperado %+v", i, *d, *definicoes[i])
+		}
+	}
+	if p := consultar(t, stub, cc, "p2"); !p.BeneficiarioAceitou || p.Valor != 0 || p.CpfPagador != "111" || p.Versao != 1 {
+		t.Errorf("p2 após a migração = %+v", p)
+	}
+	res, err := stub.MockQuery(cc, "consultarPropostasPorCpf", "111")
+	if err != nil {
+		t.Fatalf("consultarPropostasPorCpf: %v", err)
+	}
+	var propostas []Proposta
+	lerDados(res, &propostas)
+	if len(propostas) != 2 {
+		t.Errorf("índice após a migração = %+v", propostas)
+	}
+
+	// As propostas migradas aceitam as colunas incluídas
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false", "500", "1"); err != nil {
+		t.Fatalf("registrarProposta: %v", err)
+	}
+
+	// Uma nova migração sem alterações mantém os dados
+	if _, err := stub.MockInit(cc, "init", argMigrar, moduloAutenticacao); err != nil {
+		t.Fatalf("Init migrar sem alterações: %v", err)
+	}
+	if p := consultar(t, stub, cc, "p1"); p.Valor != 500 || p.Versao != 2 {
+		t.Errorf("p1 após nova migração = %+v", p)
+	}
+
+	// Somente o administrador registrado pode migrar
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInit(cc, "init", argMigrar, moduloAutenticacao)
+	verificarErro(t, "migração por outro usuário", err, "Falha ao verificar a identidade do administrador")
+	stub.CallerMetadata = []byte(adminTeste)
+
+	_, err = stub.MockQuery(cc, "consultarVersaoEsquema", "x")
+	verificarErro(t, "consultarVersaoEsquema com argumento", err, "Esperado 0")
+}
+
+func TestMigracaoArmazenamento(t *testing.T) {
+	stub, cc := iniciarChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false", "100")
+	stub.MockInvoke(cc, "registrarProposta", "p2", "222", "false", "false", "false")
+
+	// Da tabela para JSON
+	if _, err := stub.MockInit(cc, "init", argMigrar, "armazenamento=json"); err != nil {
+		t.Fatalf("Init migrar para json: %v", err)
+	}
+	if _, err := stub.GetTable(nomeTabelaProposta); err == nil {
+		t.Error("tabela Proposta mantida após a migração para json")
+	}
+	if stub.State(prefixoChaveProposta+"p1") == nil {
+		t.Error("proposta p1 não gravada em JSON")
+	}
+	if v := versaoEsquema(t, stub, cc); v.Armazenamento != armazenamentoJSON || v.Versao != versaoEsquemaAtual {
+		t.Errorf("versão após a migração para json = %+v", v)
+	}
+
+	// E de volta para a tabela
+	if _, err := stub.MockInit(cc, "init", argMigrar); err != nil {
+		t.Fatalf("Init migrar para tabela: %v", err)
+	}
+	if stub.State(prefixoChaveProposta+"p1") != nil {
+		t.Error("proposta em JSON mantida após a migração para tabela")
+	}
+	if p := consultar(t, stub, cc, "p1"); p.Valor != 100 || !p.PagadorAceitou {
+		t.Errorf("p1 após as migrações = %+v", p)
+	}
+	res, _ := stub.MockQuery(cc, "consultarPropostasPorCpf", "222")
+	var propostas []Proposta
+	lerDados(res, &propostas)
+	if len(propostas) != 1 || propostas[0].ID != "p2" {
+		t.Errorf("índice após as migrações = %+v", propostas)
+	}
+}
+
+func TestMigracaoSemDados(t *testing.T) {
+	stub := shimtest.NewStub()
+	cc := new(BoletoPropostaChaincode)
+	if v := versaoEsquema(t, stub, cc); v.Versao != 0 {
+		t.Errorf("versão sem dados = %+v", v)
+	}
+	if _, err := stub.MockInit(cc, "init", argMigrar, "armazenamento=json"); err != nil {
+		t.Fatalf("Init migrar sem dados: %v", err)
+	}
+	if v := versaoEsquema(t, stub, cc); v.Versao != versaoEsquemaAtual || v.Armazenamento != armazenamentoJSON {
+		t.Errorf("versão após Init migrar = %+v", v)
+	}
+
+	// Esquema gravado por uma versão posterior do chaincode
+	stub.PutState(chaveVersaoEsquema, []byte("99"))
+	_, err := stub.MockInit(cc, "init", argMigrar)
+	verificarErro(t, "versão posterior", err, "posterior à suportada")
+}
+
+func TestMigracaoVersaoJSON(t *testing.T) {
+	stub, cc := iniciarChaincode(t, "armazenamento=json")
+
+	// Proposta gravada em JSON antes da inclusão da versão
+	stub.PutState(prefixoChaveProposta+"p1", []byte(`{"id_proposta":"p1","cpf_pagador":"111","valor":100}`))
+	stub.PutState(chaveVersaoEsquema, []byte("4"))
+
+	if _, err := stub.MockInit(cc, "init", argMigrar, "armazenamento=json"); err != nil {
+		t.Fatalf("Init migrar: %v", err)
+	}
+	if p := consultar(t, stub, cc, "p1"); p.Versao != 1 || p.Valor != 100 {
+		t.Errorf("p1 após a migração = %+v", p)
+	}
+}
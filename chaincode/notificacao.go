@@ -0,0 +1,417 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Definição da Struct Evento, emitida a cada alteração de uma proposta.
+// Os campos da Proposta são exportados no mesmo nível do JSON, mantendo o formato esperado pela API externa.
+type Evento struct {
+	ID   string `json:"id_evento"`
+	Tipo string `json:"tipo_evento"`
+	TxID string `json:"tx_id"`
+	Proposta
+}
+
+// tipos de evento do ciclo de vida de uma proposta
+const (
+	eventoPropostaCriada            = "PropostaCriada"
+	eventoPropostaAtualizada        = "PropostaAtualizada"
+	eventoPropostaAceita            = "PropostaAceita"
+	eventoBoletoPago                = "BoletoPago"
+	eventoPropostaCancelada         = "PropostaCancelada"
+	eventoPagamentoEmDisputa        = "PagamentoEmDisputa"        // oráculos enviaram atestados divergentes
+	eventoDisputaPagamentoResolvida = "DisputaPagamentoResolvida" // o administrador descartou atestados pendentes
+	eventoPagamentoEstornado        = "PagamentoEstornado"
+	eventoCancelamentoSolicitado    = "CancelamentoSolicitado" // uma das partes solicitou o cancelamento, aguardando a outra
+)
+
+// consts associadas ao armazenamento dos eventos
+const (
+	nomeEventoProposta    = "eventosProposta" // nome do evento do chaincode, com a lista de eventos da transação
+	chaveSequenciaEvento  = "seqEvento"       // chave do último id_evento emitido
+	prefixoChaveEvento    = "evento_"
+	maxEventosPorConsulta = 100
+)
+
+// Definição da Struct Entrega, confirmação de entrega de um evento a um assinante
+type Entrega struct {
+	IDProposta string `json:"id_proposta"`
+	IDEvento   string `json:"id_evento"`
+	Assinante  string `json:"assinante"`
+	TipoEvento string `json:"tipo_evento"`
+	Status     string `json:"status"`
+	TxID       string `json:"tx_id"`
+}
+
+// consts associadas à tabela de Entregas
+const (
+	nomeTabelaEntrega = "Entrega"
+	colIDProposta     = "idProposta"
+	colIDEvento       = "idEvento"
+	colAssinante      = "assinante"
+	colTipoEvento     = "tipoEvento"
+	colStatus         = "status"
+	colTxID           = "txID"
+)
+
+// status de entrega aceitos por confirmarEntrega
+const (
+	statusEntregue  = "entregue"  // evento recebido pelo assinante
+	statusRejeitado = "rejeitado" // evento recusado definitivamente pelo assinante
+)
+
+// ============================================================================================================================
+// Módulo notificacao
+// ============================================================================================================================
+
+// verificarNotificacao: retorna erro caso o módulo notificacao não esteja habilitado
+func (t *BoletoPropostaChaincode) verificarNotificacao(stub shim.ChaincodeStubInterface) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Notificacao {
+		return novoErro(codigoModuloNaoHabilitado, msgModuloNaoHabilitado, moduloNotificacao)
+	}
+	return nil
+}
+
+// criarTabelaEntrega: cria a tabela de Entregas, mantida no reset assim como os eventos
+func (t *BoletoPropostaChaincode) criarTabelaEntrega(stub shim.ChaincodeStubInterface) error {
+	tbEntrega, err := stub.GetTable(nomeTabelaEntrega)
+	if err != nil {
+		logChamada(stub).Erro("Falha ao executar stub.GetTable", "tabela", nomeTabelaEntrega, "erro", err)
+	}
+	if tbEntrega != nil {
+		return nil
+	}
+
+	logChamada(stub).Info("Criando a tabela...", "tabela", nomeTabelaEntrega)
+	err = stub.CreateTable(nomeTabelaEntrega, []*shim.ColumnDefinition{
+		// Proposta a que o evento se refere
+		&shim.ColumnDefinition{Name: colIDProposta, Type: shim.ColumnDefinition_STRING, Key: true},
+		// Identificador do evento entregue
+		&shim.ColumnDefinition{Name: colIDEvento, Type: shim.ColumnDefinition_STRING, Key: true},
+		// Sistema que recebeu o evento
+		&shim.ColumnDefinition{Name: colAssinante, Type: shim.ColumnDefinition_STRING, Key: true},
+		// Tipo do evento entregue
+		&shim.ColumnDefinition{Name: colTipoEvento, Type: shim.ColumnDefinition_STRING, Key: false},
+		// Status da entrega
+		&shim.ColumnDefinition{Name: colStatus, Type: shim.ColumnDefinition_STRING, Key: false},
+		// Transação que registrou a confirmação
+		&shim.ColumnDefinition{Name: colTxID, Type: shim.ColumnDefinition_STRING, Key: false},
+	})
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCriarTabela, nomeTabelaEntrega, err)
+	}
+	logChamada(stub).Info("Tabela criada com sucesso.", "tabela", nomeTabelaEntrega)
+	return nil
+}
+
+// ============================================================================================================================
+// Eventos
+// ============================================================================================================================
+
+// eventosTransicao: identifica os eventos gerados pela alteração de uma proposta.
+// anterior é nil quando a proposta está sendo criada
+func eventosTransicao(anterior *Proposta, nova Proposta) []string {
+	var tipos []string
+
+	if anterior == nil {
+		tipos = append(tipos, eventoPropostaCriada)
+	} else {
+		tipos = append(tipos, eventoPropostaAtualizada)
+	}
+
+	// A proposta é aceita quando pagador e beneficiário aceitaram
+	aceitaAnterior := anterior != nil && anterior.PagadorAceitou && anterior.BeneficiarioAceitou
+	if nova.PagadorAceitou && nova.BeneficiarioAceitou && !aceitaAnterior {
+		tipos = append(tipos, eventoPropostaAceita)
+	}
+
+	if nova.BoletoPago && (anterior == nil || !anterior.BoletoPago) {
+		tipos = append(tipos, eventoBoletoPago)
+	}
+	if anterior != nil && anterior.BoletoPago && !nova.BoletoPago {
+		tipos = append(tipos, eventoPagamentoEstornado)
+	}
+	if nova.Cancelada && (anterior == nil || !anterior.Cancelada) {
+		tipos = append(tipos, eventoPropostaCancelada)
+	}
+
+	return tipos
+}
+
+// emitirEventos: registra os eventos no ledger, para entrega pelo relay, e os emite como evento do chaincode.
+// O Fabric mantém apenas o último evento de cada transação, por isso o evento do chaincode inclui também os
+// eventos registrados anteriormente na mesma transação (ex.: registrarPropostasEmLote).
+// Sem o módulo notificacao nenhum evento é emitido
+func (t *BoletoPropostaChaincode) emitirEventos(stub shim.ChaincodeStubInterface, tipos []string, proposta Proposta) error {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return err
+	}
+	if !modulos.Notificacao {
+		return nil
+	}
+
+	ultimo, err := t.ultimaSequenciaEvento(stub)
+	if err != nil {
+		return err
+	}
+
+	eventos, err := t.eventosTransacao(stub, ultimo)
+	if err != nil {
+		return err
+	}
+	for _, tipo := range tipos {
+		ultimo++
+		evento := Evento{
+			ID:       strconv.FormatUint(ultimo, 10),
+			Tipo:     tipo,
+			TxID:     stub.GetTxID(),
+			Proposta: proposta,
+		}
+
+		eventoAsBytes, err := json.Marshal(evento)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Evento", err)
+		}
+		err = stub.PutState(chaveEvento(ultimo), eventoAsBytes)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaRegistrarEvento, ultimo, err)
+		}
+		logChamada(stub).Info("Evento emitido", "tipo", tipo, "id_proposta", proposta.ID)
+
+		eventos = append(eventos, evento)
+	}
+
+	err = stub.PutState(chaveSequenciaEvento, []byte(strconv.FormatUint(ultimo, 10)))
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaRegistrarSequenciaEventos, err)
+	}
+
+	payload, err := json.Marshal(eventos)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Eventos", err)
+	}
+	return stub.SetEvent(nomeEventoProposta, payload)
+}
+
+// eventosTransacao: eventos já registrados pela transação em andamento, terminando no evento ultimo
+func (t *BoletoPropostaChaincode) eventosTransacao(stub shim.ChaincodeStubInterface, ultimo uint64) ([]Evento, error) {
+	var eventos []Evento
+	for seq := ultimo; seq > 0; seq-- {
+		eventoAsBytes, err := stub.GetState(chaveEvento(seq))
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaObterEvento, seq, err)
+		}
+		var evento Evento
+		err = json.Unmarshal(eventoAsBytes, &evento)
+		if err != nil || evento.TxID != stub.GetTxID() {
+			break
+		}
+		eventos = append([]Evento{evento}, eventos...)
+	}
+	return eventos, nil
+}
+
+// ultimaSequenciaEvento: retorna o id do último evento emitido (0 caso nenhum evento tenha sido emitido)
+func (t *BoletoPropostaChaincode) ultimaSequenciaEvento(stub shim.ChaincodeStubInterface) (uint64, error) {
+	seqAsBytes, err := stub.GetState(chaveSequenciaEvento)
+	if err != nil {
+		return 0, novoErro(codigoErroInterno, msgFalhaObterSequenciaEventos, err)
+	}
+	if len(seqAsBytes) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseUint(string(seqAsBytes), 10, 64)
+}
+
+// chaveEvento: chave do estado em que o evento é armazenado.
+// O id é preenchido com zeros para manter a ordenação das chaves
+func chaveEvento(seq uint64) string {
+	return fmt.Sprintf("%s%020d", prefixoChaveEvento, seq)
+}
+
+// consultarEventos: função Query utilizada pelo relay para obter os eventos emitidos, recebendo os seguintes argumentos
+// args[0]: aPartirDe. Retorna os eventos com id_evento maior que o informado ("0" para todos)
+func (t *BoletoPropostaChaincode) consultarEventos(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarEventos...")
+
+	err := t.verificarNotificacao(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	aPartirDe, err := lerNatural("aPartirDe", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	ultimo, err := t.ultimaSequenciaEvento(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limita a quantidade de eventos retornados por consulta
+	eventos := []json.RawMessage{}
+	for seq := aPartirDe + 1; seq <= ultimo && len(eventos) < maxEventosPorConsulta; seq++ {
+		eventoAsBytes, err := stub.GetState(chaveEvento(seq))
+		if err != nil {
+			return nil, novoErro(codigoErroInterno, msgFalhaObterEvento, seq, err)
+		}
+		eventos = append(eventos, json.RawMessage(eventoAsBytes))
+	}
+
+	return json.Marshal(eventos)
+}
+
+// ============================================================================================================================
+// Entregas
+// ============================================================================================================================
+
+// confirmarEntrega: função Invoke utilizada pelo relay para registrar a entrega de um evento, recebendo os seguintes argumentos:
+// args[0]: idEvento. Identificador do evento entregue
+// args[1]: assinante. Nome do sistema que recebeu o evento
+// args[2]: status. "entregue" ou "rejeitado"
+func (t *BoletoPropostaChaincode) confirmarEntrega(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("confirmarEntrega...")
+
+	err := t.verificarNotificacao(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	idEvento := args[0]
+	assinante := args[1]
+	status := args[2]
+
+	if assinante == "" {
+		return nil, novoErro(codigoArgumentosInvalidos, msgAssinanteNaoInformado)
+	}
+	if status != statusEntregue && status != statusRejeitado {
+		return nil, novoErro(codigoArgumentosInvalidos, msgStatusEntregaInvalido, status)
+	}
+
+	// O evento precisa ter sido emitido pelo chaincode
+	seq, err := lerNatural("idEvento", idEvento)
+	if err != nil {
+		return nil, err
+	}
+	// Forma canônica do id ("007" e "7" são o mesmo evento, com uma única entrega por assinante)
+	idEvento = strconv.FormatUint(seq, 10)
+	eventoAsBytes, err := stub.GetState(chaveEvento(seq))
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterEvento, idEvento, err)
+	}
+	if len(eventoAsBytes) == 0 {
+		return nil, novoErro(codigoEventoNaoEncontrado, msgEventoNaoExistente, idEvento)
+	}
+	var evento Evento
+	err = json.Unmarshal(eventoAsBytes, &evento)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Evento", err)
+	}
+
+	// Uma nova confirmação do mesmo assinante substitui a anterior
+	row := shim.Row{
+		Columns: []*shim.Column{
+			&shim.Column{Value: &shim.Column_String_{String_: evento.Proposta.ID}},
+			&shim.Column{Value: &shim.Column_String_{String_: idEvento}},
+			&shim.Column{Value: &shim.Column_String_{String_: assinante}},
+			&shim.Column{Value: &shim.Column_String_{String_: evento.Tipo}},
+			&shim.Column{Value: &shim.Column_String_{String_: status}},
+			&shim.Column{Value: &shim.Column_String_{String_: stub.GetTxID()}},
+		},
+	}
+	ok, err := stub.InsertRow(nomeTabelaEntrega, row)
+	if err == nil && !ok {
+		ok, err = stub.ReplaceRow(nomeTabelaEntrega, row)
+	}
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaRegistrarEntrega, idEvento, err)
+	}
+	if !ok {
+		return nil, novoErro(codigoErroInterno, msgFalhaRegistrarEntregaSemErro, idEvento)
+	}
+
+	logChamada(stub).Info("Entrega do Evento registrada", "id_evento", idEvento, "assinante", assinante, "status", status)
+	return nil, nil
+}
+
+// consultarEntregas: função Query para consultar as entregas dos eventos de uma proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarEntregas(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarEntregas...")
+
+	err := t.verificarNotificacao(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	idProposta := args[0]
+
+	rows, err := stub.GetRows(nomeTabelaEntrega, []shim.Column{
+		shim.Column{Value: &shim.Column_String_{String_: idProposta}},
+	})
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterEntregas, idProposta, err)
+	}
+
+	entregas := []Entrega{}
+	for row := range rows {
+		entregas = append(entregas, Entrega{
+			IDProposta: row.Columns[0].GetString_(),
+			IDEvento:   row.Columns[1].GetString_(),
+			Assinante:  row.Columns[2].GetString_(),
+			TipoEvento: row.Columns[3].GetString_(),
+			Status:     row.Columns[4].GetString_(),
+			TxID:       row.Columns[5].GetString_(),
+		})
+	}
+
+	// Ordena pela sequência do evento; as chaves da tabela são ordenadas como texto
+	sort.Sort(entregasPorEvento(entregas))
+
+	return json.Marshal(entregas)
+}
+
+// entregasPorEvento - ordenação das entregas pelo id do evento e assinante
+type entregasPorEvento []Entrega
+
+func (e entregasPorEvento) Len() int      { return len(e) }
+func (e entregasPorEvento) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e entregasPorEvento) Less(i, j int) bool {
+	if len(e[i].IDEvento) != len(e[j].IDEvento) {
+		return len(e[i].IDEvento) < len(e[j].IDEvento)
+	}
+	if e[i].IDEvento != e[j].IDEvento {
+		return e[i].IDEvento < e[j].IDEvento
+	}
+	return e[i].Assinante < e[j].Assinante
+}
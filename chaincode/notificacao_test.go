@@ -0,0 +1,57 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"strconv"
+	"testing"
+)
+
+// ============================================================================================================================
+// Eventos e entregas
+// ============================================================================================================================
+
+func TestEventosEEntregas(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	for i := 0; i < 10; i++ {
+		stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "false", "false", "0", strconv.Itoa(i))
+	}
+
+	res, err := stub.MockQuery(cc, "consultarEventos", "8")
+	if err != nil {
+		t.Fatalf("consultarEventos: %v", err)
+	}
+	var eventos []Evento
+	lerDados(res, &eventos)
+	if len(eventos) != 2 || eventos[0].ID != "9" || eventos[1].ID != "10" {
+		t.Errorf("eventos = %+v", eventos)
+	}
+	_, err = stub.MockQuery(cc, "consultarEventos", "x")
+	verificarErro(t, "consultarEventos inválido", err, "aPartirDe")
+
+	for _, id := range []string{"10", "2"} {
+		if _, err := stub.MockInvoke(cc, "confirmarEntrega", id, "bc-desafio", statusEntregue); err != nil {
+			t.Fatalf("confirmarEntrega(%s): %v", id, err)
+		}
+	}
+	// "002" é o mesmo evento 2: a confirmação substitui a anterior
+	stub.MockInvoke(cc, "confirmarEntrega", "002", "bc-desafio", statusRejeitado)
+
+	res, err = stub.MockQuery(cc, "consultarEntregas", "p1")
+	if err != nil {
+		t.Fatalf("consultarEntregas: %v", err)
+	}
+	var entregas []Entrega
+	lerDados(res, &entregas)
+	if len(entregas) != 2 || entregas[0].IDEvento != "2" || entregas[0].Status != statusRejeitado || entregas[1].IDEvento != "10" {
+		t.Errorf("entregas = %+v", entregas)
+	}
+
+	_, err = stub.MockInvoke(cc, "confirmarEntrega", "99", "bc-desafio", statusEntregue)
+	verificarErro(t, "evento inexistente", err, "Evento [99] não existente.")
+	_, err = stub.MockInvoke(cc, "confirmarEntrega", "1", "bc-desafio", "ok")
+	verificarErro(t, "status inválido", err, "Status de entrega inválido")
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "confirmarEntrega", "1", "bc-desafio", statusEntregue)
+	verificarErro(t, "caller não administrador", err, "identidade do administrador")
+}
@@ -0,0 +1,314 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/canonico"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"golang.org/x/crypto/sha3"
+)
+
+// ============================================================================================================================
+// Oráculos de pagamento
+// ============================================================================================================================
+
+// oraculoTeste - chave de um oráculo utilizada nos testes
+type oraculoTeste struct {
+	id    string
+	chave *ecdsa.PrivateKey
+}
+
+func novoOraculo(t *testing.T, id string) oraculoTeste {
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oraculoTeste{id: id, chave: chave}
+}
+
+// pem: chave pública do oráculo em formato PEM
+func (o oraculoTeste) pem(t *testing.T) string {
+	der, err := x509.MarshalPKIXPublicKey(&o.chave.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// assinar: assinatura ECDSA (DER, em base64) do hash SHA3-256 do conteúdo, como gerada pelo oráculo
+func (o oraculoTeste) assinar(t *testing.T, conteudo []byte) string {
+	hash := sha3.Sum256(conteudo)
+	r, s, err := ecdsa.Sign(rand.Reader, o.chave, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assinatura, err := asn1.Marshal(primitives.ECDSASignature{R: r, S: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(assinatura)
+}
+
+// atestar: argumentos de confirmarPagamentoOracle para o atestado informado
+func (o oraculoTeste) atestar(t *testing.T, a AtestadoPagamento) []string {
+	a.IDOraculo = o.id
+	atestadoAsBytes, _ := json.Marshal(a)
+	conteudo, _ := canonico.Codificar(a)
+	return []string{string(atestadoAsBytes), o.assinar(t, conteudo)}
+}
+
+func TestConfirmarPagamentoOracleQuorum(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	oraculos := []oraculoTeste{novoOraculo(t, "banco-1"), novoOraculo(t, "banco-2"), novoOraculo(t, "banco-3")}
+	for _, o := range oraculos {
+		if _, err := stub.MockInvoke(cc, "registrarOraculo", o.id, o.pem(t)); err != nil {
+			t.Fatalf("registrarOraculo(%s): %v", o.id, err)
+		}
+	}
+	_, err := stub.MockInvoke(cc, "configurarQuorumOraculos", "4")
+	verificarErro(t, "quorum maior que N", err, "Quorum [4]")
+	if _, err := stub.MockInvoke(cc, "configurarQuorumOraculos", "2"); err != nil {
+		t.Fatalf("configurarQuorumOraculos: %v", err)
+	}
+
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "true", "15000", "1")
+	verificarErro(t, "pagamento sem oráculo", err, "deve ser confirmado por um oráculo")
+
+	atestado := AtestadoPagamento{IDProposta: "p1", ValorPago: 15000, DataPagamento: "2016-11-30", CodigoAutenticacao: "AUT1"}
+
+	// Primeiro atestado fica pendente
+	res, err := stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[0].atestar(t, atestado)...)
+	if err != nil {
+		t.Fatalf("confirmarPagamentoOracle: %v", err)
+	}
+	if string(res) != `{"sucesso":true,"dados":{"pago":false,"atestados":1,"quorum":2}}` {
+		t.Errorf("resposta = %s", res)
+	}
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[0].atestar(t, atestado)...)
+	verificarErro(t, "atestado duplicado", err, "já atestou")
+
+	// Atestado divergente emite disputa
+	divergente := atestado
+	divergente.DataPagamento = "2016-12-01"
+	if _, err := stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[2].atestar(t, divergente)...); err != nil {
+		t.Fatalf("atestado divergente: %v", err)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != eventoPagamentoEmDisputa {
+		t.Errorf("eventos = %v", tipos)
+	}
+	res, _ = stub.MockQuery(cc, "consultarAtestadosPendentes", "p1")
+	var pendentes []AtestadoAssinado
+	lerDados(res, &pendentes)
+	if len(pendentes) != 2 {
+		t.Errorf("atestados pendentes = %d; esperado 2", len(pendentes))
+	}
+
+	// Segundo atestado coincidente atinge o quorum
+	res, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[1].atestar(t, atestado)...)
+	if err != nil {
+		t.Fatalf("confirmarPagamentoOracle: %v", err)
+	}
+	if string(res) != `{"sucesso":true,"dados":{"pago":true,"atestados":2,"quorum":2}}` {
+		t.Errorf("resposta = %s", res)
+	}
+	if !consultar(t, stub, cc, "p1").BoletoPago {
+		t.Error("boleto não foi marcado como pago")
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != "PagamentoEmDisputa,PropostaAtualizada,BoletoPago" {
+		t.Errorf("eventos = %v", tipos)
+	}
+	res, err = stub.MockQuery(cc, "consultarPagamento", "p1")
+	var confirmacao ConfirmacaoPagamento
+	if err != nil || lerDados(res, &confirmacao) != nil || len(confirmacao.Atestados) != 2 {
+		t.Errorf("consultarPagamento = %s, %v", res, err)
+	}
+
+	// A autenticação não pode ser reutilizada
+	stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "true", "false", "15000")
+	reuso := atestado
+	reuso.IDProposta = "p2"
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[0].atestar(t, reuso)...)
+	verificarErro(t, "autenticação reutilizada", err, "já utilizado")
+}
+
+func TestConfirmarPagamentoOracleErros(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	oraculo := novoOraculo(t, "banco-1")
+	stub.MockInvoke(cc, "registrarOraculo", oraculo.id, oraculo.pem(t))
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+
+	atestado := AtestadoPagamento{IDProposta: "p1", ValorPago: 15000, DataPagamento: "2016-11-30", CodigoAutenticacao: "AUT1"}
+
+	valorErrado := atestado
+	valorErrado.ValorPago = 100
+	_, err := stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, valorErrado)...)
+	verificarErro(t, "valor divergente", err, "Valor pago [100] diferente")
+
+	dataInvalida := atestado
+	dataInvalida.DataPagamento = "30/11/2016"
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, dataInvalida)...)
+	verificarErro(t, "data inválida", err, "Data de pagamento inválida")
+
+	inexistente := atestado
+	inexistente.IDProposta = "p9"
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, inexistente)...)
+	verificarErro(t, "proposta inexistente", err, "Proposta [p9] não existente.")
+
+	// Assinatura de outra chave
+	args := novoOraculo(t, "banco-1").atestar(t, atestado)
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", args...)
+	verificarErro(t, "assinatura inválida", err, "Assinatura do atestado inválida")
+
+	args = novoOraculo(t, "banco-9").atestar(t, atestado)
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", args...)
+	verificarErro(t, "oráculo não registrado", err, "Oráculo [banco-9] não registrado.")
+
+	_, err = stub.MockInvoke(cc, "registrarOraculo", "banco-2", "chave")
+	verificarErro(t, "chave inválida", err, "Chave pública do oráculo inválida")
+	_, err = stub.MockInvoke(cc, "registrarOraculo", oraculo.id, oraculo.pem(t))
+	verificarErro(t, "oráculo duplicado", err, "já registrado")
+
+	// A assinatura cobre o JSON canônico: chaves duplicadas são rejeitadas, e a ordem
+	// dos campos e os espaços do JSON enviado não alteram a verificação
+	args = oraculo.atestar(t, atestado)
+	duplicado := strings.Replace(args[0], `{`, `{"id_proposta":"p2",`, 1)
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", duplicado, args[1])
+	verificarErro(t, "chave duplicada", err, "Chave duplicada no JSON: id_proposta")
+
+	// Campos desconhecidos ou aceitos pelo decoder do Go com outra capitalização são rejeitados
+	desconhecido := strings.Replace(args[0], `{`, `{"observacao":"x",`, 1)
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", desconhecido, args[1])
+	verificarErro(t, "campo desconhecido", err, "Falha ao decodificar o atestado")
+	maiusculo := strings.Replace(args[0], `"id_proposta"`, `"ID_PROPOSTA"`, 1)
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", maiusculo, oraculo.assinar(t, []byte(maiusculo)))
+	verificarErro(t, "chave com outra capitalização", err, "não correspondem ao JSON assinado")
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", args[0]+` {}`, args[1])
+	verificarErro(t, "conteúdo após o atestado", err, "Falha ao decodificar o atestado")
+
+	var campos map[string]interface{}
+	json.Unmarshal([]byte(args[0]), &campos)
+	reordenado, _ := json.MarshalIndent(campos, "", "  ")
+
+	// Com quorum 1 o primeiro atestado confirma o pagamento
+	if _, err := stub.MockInvoke(cc, "confirmarPagamentoOracle", string(reordenado), args[1]); err != nil {
+		t.Fatalf("confirmarPagamentoOracle: %v", err)
+	}
+	_, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, atestado)...)
+	verificarErro(t, "proposta já paga", err, "já utilizado")
+}
+
+func TestRemoverOraculo(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	oraculos := []oraculoTeste{novoOraculo(t, "banco-1"), novoOraculo(t, "banco-2")}
+	for _, o := range oraculos {
+		stub.MockInvoke(cc, "registrarOraculo", o.id, o.pem(t))
+	}
+	stub.MockInvoke(cc, "configurarQuorumOraculos", "2")
+
+	_, err := stub.MockInvoke(cc, "removerOraculo", "banco-1")
+	verificarErro(t, "quorum maior que os restantes", err, "Quorum [2]")
+	stub.MockInvoke(cc, "configurarQuorumOraculos", "1")
+	if _, err := stub.MockInvoke(cc, "removerOraculo", "banco-1"); err != nil {
+		t.Fatalf("removerOraculo: %v", err)
+	}
+
+	// A remoção do último oráculo reinicia o quorum
+	if _, err := stub.MockInvoke(cc, "removerOraculo", "banco-2"); err != nil {
+		t.Fatalf("removerOraculo do último oráculo: %v", err)
+	}
+	res, _ := stub.MockQuery(cc, "consultarOraculos")
+	var configuracao ConfiguracaoOraculos
+	lerDados(res, &configuracao)
+	if configuracao.Quorum != 1 || len(configuracao.Oraculos) != 0 {
+		t.Errorf("consultarOraculos = %s", res)
+	}
+}
+
+func TestResolverDisputaPagamento(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	oraculos := []oraculoTeste{novoOraculo(t, "banco-1"), novoOraculo(t, "banco-2"), novoOraculo(t, "banco-3")}
+	for _, o := range oraculos {
+		stub.MockInvoke(cc, "registrarOraculo", o.id, o.pem(t))
+	}
+	stub.MockInvoke(cc, "configurarQuorumOraculos", "2")
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "15000")
+
+	_, err := stub.MockInvoke(cc, "resolverDisputaPagamento", "p1")
+	verificarErro(t, "sem atestados", err, "Proposta [p1] sem atestados pendentes.")
+
+	atestado := AtestadoPagamento{IDProposta: "p1", ValorPago: 15000, DataPagamento: "2016-11-30", CodigoAutenticacao: "AUT1"}
+	divergente := atestado
+	divergente.DataPagamento = "2016-12-01"
+	stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[0].atestar(t, atestado)...)
+	stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[1].atestar(t, divergente)...)
+
+	_, err = stub.MockInvoke(cc, "resolverDisputaPagamento", "p1", "banco-3")
+	verificarErro(t, "oráculo sem atestado", err, "Oráculo [banco-3] não atestou")
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "resolverDisputaPagamento", "p1", "banco-2")
+	verificarErro(t, "caller não administrador", err, "identidade do administrador")
+	stub.CallerMetadata = []byte(adminTeste)
+
+	// Descarta o atestado divergente: o atestado restante continua valendo para o quorum
+	res, err := stub.MockInvoke(cc, "resolverDisputaPagamento", "p1", "banco-2")
+	if err != nil {
+		t.Fatalf("resolverDisputaPagamento: %v", err)
+	}
+	if string(res) != `{"sucesso":true,"dados":{"descartados":1,"restantes":1}}` {
+		t.Errorf("resposta = %s", res)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != eventoDisputaPagamentoResolvida {
+		t.Errorf("eventos = %v", tipos)
+	}
+
+	// O oráculo descartado pode enviar um novo atestado
+	res, err = stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[1].atestar(t, atestado)...)
+	if err != nil {
+		t.Fatalf("confirmarPagamentoOracle após a resolução: %v", err)
+	}
+	if !strings.Contains(string(res), `"pago":true`) {
+		t.Errorf("resposta = %s", res)
+	}
+
+	// Sem oráculos, todos os atestados pendentes são descartados
+	stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "true", "false", "100")
+	atestado = AtestadoPagamento{IDProposta: "p2", ValorPago: 100, DataPagamento: "2016-11-30", CodigoAutenticacao: "AUT2"}
+	stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculos[0].atestar(t, atestado)...)
+	if _, err := stub.MockInvoke(cc, "resolverDisputaPagamento", "p2"); err != nil {
+		t.Fatalf("resolverDisputaPagamento sem oráculos: %v", err)
+	}
+	res, _ = stub.MockQuery(cc, "consultarAtestadosPendentes", "p2")
+	var pendentes []AtestadoAssinado
+	lerDados(res, &pendentes)
+	if len(pendentes) != 0 {
+		t.Errorf("atestados pendentes = %d; esperado 0", len(pendentes))
+	}
+}
+
+func TestAssinaturaOraculoNivelSeguranca(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	oraculo := novoOraculo(t, "banco-1")
+	stub.MockInvoke(cc, "registrarOraculo", oraculo.id, oraculo.pem(t))
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "true", "false", "100")
+
+	// O hash das assinaturas dos oráculos continua SHA3-256 após a alteração do nível de segurança
+	if _, err := stub.MockInvoke(cc, "atualizarConfiguracao", `{"algoritmo_seguranca":"SHA2","nivel_seguranca":384}`); err != nil {
+		t.Fatalf("atualizarConfiguracao: %v", err)
+	}
+	atestado := AtestadoPagamento{IDProposta: "p1", ValorPago: 100, DataPagamento: "2016-11-30", CodigoAutenticacao: "AUT1"}
+	if _, err := stub.MockInvoke(cc, "confirmarPagamentoOracle", oraculo.atestar(t, atestado)...); err != nil {
+		t.Errorf("confirmarPagamentoOracle com SHA2-384 configurado: %v", err)
+	}
+}
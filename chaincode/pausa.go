@@ -0,0 +1,151 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ============================================================================================================================
+// Pausa (modo somente leitura)
+// ============================================================================================================================
+
+// Definição da Struct Pausa, estado de pausa do chaincode. Pausado, todas as funções Invoke, exceto
+// pausar e retomar (as únicas marcadas como Pausada no registro), são rejeitadas com codigoChaincodePausado,
+// inclusive o confirmarEntrega do relay; as funções Query continuam disponíveis
+type Pausa struct {
+	Pausado bool   `json:"pausado"`
+	Motivo  string `json:"motivo,omitempty"`
+	TxID    string `json:"tx_id,omitempty"` // transação que pausou ou retomou o chaincode
+	Desde   int64  `json:"desde,omitempty"` // timestamp da transação, em segundos (Unix)
+}
+
+// consts associadas à pausa
+const (
+	chavePausa         = "pausa"
+	nomeEventoPausa    = "ChaincodePausado"  // evento do chaincode emitido por pausar, com a Pausa
+	nomeEventoRetomada = "ChaincodeRetomado" // evento do chaincode emitido por retomar, com a Pausa
+)
+
+// obterPausa: retorna o estado de pausa do chaincode
+func obterPausa(stub shim.ChaincodeStubInterface) (Pausa, error) {
+	var pausa Pausa
+	pausaAsBytes, err := stub.GetState(chavePausa)
+	if err != nil {
+		return pausa, novoErro(codigoErroInterno, msgFalhaObterPausa, err)
+	}
+	if len(pausaAsBytes) == 0 {
+		return pausa, nil
+	}
+	err = json.Unmarshal(pausaAsBytes, &pausa)
+	if err != nil {
+		return pausa, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Pausa", err)
+	}
+	return pausa, nil
+}
+
+// verificarPausa: retorna erro caso o chaincode esteja pausado
+func verificarPausa(stub shim.ChaincodeStubInterface) error {
+	pausa, err := obterPausa(stub)
+	if err != nil {
+		return err
+	}
+	if !pausa.Pausado {
+		return nil
+	}
+	var motivo interface{} = pausa.Motivo
+	if pausa.Motivo == "" {
+		motivo = textoTraduzido(msgMotivoNaoInformado)
+	}
+	return novoErro(codigoChaincodePausado, msgChaincodePausado, motivo)
+}
+
+// pausar: função Invoke para colocar o chaincode em modo somente leitura, recebendo os seguintes argumentos:
+// args[0]: motivo (opcional). Informado no erro das funções rejeitadas e no evento ChaincodePausado
+func (t *BoletoPropostaChaincode) pausar(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("pausar...")
+
+	anterior, err := obterPausa(stub)
+	if err != nil {
+		return nil, err
+	}
+	if anterior.Pausado {
+		return nil, novoErro(codigoChaincodePausado, msgChaincodeJaPausado, anterior.TxID)
+	}
+	motivo := ""
+	if len(args) > 0 {
+		motivo = args[0]
+	}
+	return gravarPausa(stub, true, motivo, nomeEventoPausa)
+}
+
+// retomar: função Invoke para encerrar a pausa do chaincode, recebendo os seguintes argumentos:
+// args[0]: motivo (opcional). Informado no evento ChaincodeRetomado
+func (t *BoletoPropostaChaincode) retomar(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("retomar...")
+
+	anterior, err := obterPausa(stub)
+	if err != nil {
+		return nil, err
+	}
+	if !anterior.Pausado {
+		return nil, novoErro(codigoArgumentosInvalidos, msgChaincodeNaoPausado)
+	}
+	motivo := ""
+	if len(args) > 0 {
+		motivo = args[0]
+	}
+	return gravarPausa(stub, false, motivo, nomeEventoRetomada)
+}
+
+// gravarPausa: grava o novo estado de pausa e o emite como evento do chaincode, independente do módulo notificacao
+func gravarPausa(stub shim.ChaincodeStubInterface, pausado bool, motivo, nomeEvento string) ([]byte, error) {
+	agora, err := stub.GetTxTimestamp()
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterTimestamp, err)
+	}
+	pausa := Pausa{Pausado: pausado, Motivo: motivo, TxID: stub.GetTxID(), Desde: agora.Seconds}
+	pausaAsBytes, err := json.Marshal(pausa)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Pausa", err)
+	}
+	err = stub.PutState(chavePausa, pausaAsBytes)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaGravarPausa, err)
+	}
+	err = stub.SetEvent(nomeEvento, pausaAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	logChamada(stub).Aviso(nomeEvento, "motivo", motivo)
+	return pausaAsBytes, nil
+}
+
+// consultarPausa: função Query para consultar se o chaincode está pausado. Não recebe argumentos
+func (t *BoletoPropostaChaincode) consultarPausa(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarPausa...")
+
+	pausa, err := obterPausa(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(pausa)
+}
@@ -0,0 +1,72 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPausarERetomar(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false", "15000")
+
+	_, err := stub.MockInvoke(cc, "retomar")
+	verificarErro(t, "retomar sem pausa", err, "não está pausado")
+
+	if _, err := stub.MockInvoke(cc, "pausar", "incidente 42"); err != nil {
+		t.Fatalf("pausar: %v", err)
+	}
+	e := stub.LastEvent()
+	var pausa Pausa
+	if e == nil || e.Name != nomeEventoPausa || json.Unmarshal(e.Payload, &pausa) != nil || !pausa.Pausado || pausa.Motivo != "incidente 42" {
+		t.Fatalf("evento da pausa = %+v", e)
+	}
+	_, err = stub.MockInvoke(cc, "pausar")
+	verificarErro(t, "pausa repetida", err, "já pausado")
+
+	// As funções Invoke são rejeitadas com o código específico; as Query continuam disponíveis
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false")
+	if err == nil || lerErro(err).Codigo != codigoChaincodePausado {
+		t.Fatalf("registrarProposta pausado = %v; esperado %s", err, codigoChaincodePausado)
+	}
+	verificarErro(t, "motivo no erro", err, "Motivo: incidente 42")
+	_, err = stub.MockInvoke(cc, "atualizarConfiguracao", `{"max_lote_propostas":10}`)
+	verificarErro(t, "configuração pausada", err, "Chaincode pausado")
+	_, err = stub.MockInvoke(cc, "confirmarEntrega", "1", "api", "entregue")
+	verificarErro(t, "confirmarEntrega pausado", err, "Chaincode pausado")
+	verificarCodigo(t, "confirmarEntrega pausado", err, codigoChaincodePausado)
+	if p := consultar(t, stub, cc, "p1"); p.Versao != 1 {
+		t.Errorf("proposta = %+v", p)
+	}
+	res, err := stub.MockQuery(cc, "consultarPausa")
+	if err != nil || lerDados(res, &pausa) != nil || !pausa.Pausado || pausa.TxID == "" {
+		t.Errorf("consultarPausa = %s, %v", res, err)
+	}
+	var versao VersaoChaincode
+	res, _ = stub.MockQuery(cc, "versao")
+	if lerDados(res, &versao) != nil || !versao.Pausado {
+		t.Errorf("versao = %s", res)
+	}
+
+	// Somente o administrador pausa ou retoma o chaincode
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "retomar")
+	verificarErro(t, "caller não administrador", err, "Falha ao verificar a identidade do administrador")
+	stub.CallerMetadata = []byte(adminTeste)
+
+	if _, err := stub.MockInvoke(cc, "retomar"); err != nil {
+		t.Fatalf("retomar: %v", err)
+	}
+	if e := stub.LastEvent(); e == nil || e.Name != nomeEventoRetomada {
+		t.Errorf("evento da retomada = %+v", e)
+	}
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false"); err != nil {
+		t.Errorf("registrarProposta após retomar: %v", err)
+	}
+
+	// Sem motivo, o erro informa que o motivo não foi informado
+	stub.MockInvoke(cc, "pausar")
+	_, err = stub.MockInvoke(cc, "registrarProposta", "p3", "333", "true", "false", "false")
+	verificarErro(t, "pausa sem motivo", err, "Motivo: não informado")
+}
@@ -0,0 +1,462 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/canonico"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+)
+
+// Definição da Struct Proposta e parametros para exportação para JSON.
+// A tag coluna define a coluna da tabela 'Proposta' (ver mapeamento)
+type Proposta struct {
+	ID                  string `json:"id_proposta" coluna:"Id,chave"`
+	CpfPagador          string `json:"cpf_pagador" coluna:"cpfPagador,indice"`
+	PagadorAceitou      bool   `json:"pagador_aceitou" coluna:"pagadorAceitou"`
+	BeneficiarioAceitou bool   `json:"beneficiario_aceitou" coluna:"beneficiarioAceitou"`
+	BoletoPago          bool   `json:"boleto_pago" coluna:"boletoPago"`
+	Valor               int64  `json:"valor" coluna:"valor"`                   // valor do boleto em centavos
+	Versao              uint64 `json:"versao" coluna:"versao"`                 // incrementada a cada alteração (ver atualizarProposta)
+	Cancelada           bool   `json:"cancelada,omitempty" coluna:"cancelada"` // ver cancelarProposta
+}
+
+// consts associadas à tabela de Propostas
+const (
+	nomeTabelaProposta = "Proposta"
+	colCpfPagador      = "cpfPagador"
+)
+
+// ============================================================================================================================
+// Propostas
+// ============================================================================================================================
+
+// Definição da Struct RegistroProposta, argumento JSON de registrarProposta publicado em
+// registrarProposta.schema.json. Os campos ausentes ficam nil, para diferenciá-los do valor zero
+type RegistroProposta struct {
+	ID                  *string `json:"id_proposta"`
+	CpfPagador          *string `json:"cpf_pagador"`
+	PagadorAceitou      *bool   `json:"pagador_aceitou"`
+	BeneficiarioAceitou *bool   `json:"beneficiario_aceitou"`
+	BoletoPago          *bool   `json:"boleto_pago"`
+	Valor               *int64  `json:"valor"`           // opcional
+	VersaoEsperada      *uint64 `json:"versao_esperada"` // obrigatório na atualização
+}
+
+// registrarProposta: função Invoke para registrar uma nova proposta, recebendo os seguintes argumentos:
+// args[0]: Id. Hash que identificará a proposta
+// args[1]: cpfPagador. CPF do Pagador
+// args[2]: pagadorAceitou. Status de aceite do Pagador da proposta
+// args[3]: beneficiarioAceitou. Status de aceite do Beneficiario da proposta
+// args[4]: boletoPago. Status do Pagamento do Boleto
+// args[5]: valor (opcional). Valor do boleto em centavos, conferido na confirmação de pagamento pelos oráculos
+// args[6]: versaoEsperada (obrigatório na atualização). Versão da proposta retornada por consultarProposta,
+// ou 0 para uma nova proposta. A alteração falha com conflito de versão se a proposta foi alterada desde a consulta
+// Alternativamente, args[0] é o único argumento, com o JSON do RegistroProposta (ver lerRegistroProposta)
+// Cada alteração emite os eventos do ciclo de vida da proposta (ver eventosTransicao)
+func (t *BoletoPropostaChaincode) registrarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("registrarProposta...")
+
+	if len(args) == 1 {
+		nova, versaoEsperada, err := lerRegistroProposta(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.gravarProposta(stub, nova, versaoEsperada)
+	}
+
+	// Obtem os valores da array de arguments (args) e
+	// os converte no tipo necessário para salvar a 'Proposta'
+	idProposta := args[0]
+	fim := len(args)
+	if fim > 6 {
+		fim = 6
+	}
+	nova, err := lerProposta(idProposta, args[1:fim])
+	if err != nil {
+		return nil, err
+	}
+	var versaoEsperada *uint64
+	if len(args) == 7 {
+		versao, err := lerNatural("versaoEsperada", args[6])
+		if err != nil {
+			return nil, err
+		}
+		versaoEsperada = &versao
+	}
+	return t.gravarProposta(stub, nova, versaoEsperada)
+}
+
+// gravarProposta: registra a proposta nova ou atualiza a existente. A atualização exige a versão
+// esperada; nil indica que ela não foi informada
+func (t *BoletoPropostaChaincode) gravarProposta(stub shim.ChaincodeStubInterface, nova Proposta, versaoEsperada *uint64) ([]byte, error) {
+	idProposta := nova.ID
+
+	// Consulta o estado anterior da proposta, utilizado para identificar os eventos da transição
+	anterior, err := t.validarProposta(stub, nova, versaoEsperada)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registra a proposta no repositório
+	log := logChamada(stub).Com("id_proposta", idProposta)
+	log.Debug("Gravando Proposta", "cpf_pagador", nova.CpfPagador, "pagador_aceitou", nova.PagadorAceitou,
+		"beneficiario_aceitou", nova.BeneficiarioAceitou, "boleto_pago", nova.BoletoPago)
+
+	if anterior == nil {
+		err = t.gravarNovaProposta(stub, nova)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Trecho para atualizar uma proposta existente
+		//	substitui o registro associado ao idProposta recebido nos argumentos
+		nova, err = t.atualizarProposta(stub, nova, *versaoEsperada)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Notifica os sistemas externos através dos eventos da proposta.
+	// A entrega para a API externa é feita fora do chaincode, pelo relay.
+	err = t.emitirEventos(stub, eventosTransicao(anterior, nova), nova)
+	if err != nil {
+		return nil, err
+	}
+
+	if anterior != nil {
+		log.Info("Proposta atualizada!", "versao", nova.Versao)
+		return []byte(`{"atualizado":true}`), nil
+	}
+
+	log.Info("Proposta criada!")
+
+	return []byte(`{"registrado":true}`), nil
+}
+
+// criarProposta: função Invoke para registrar uma nova proposta com o Id gerado pelo chaincode
+// (ver gerarIDProposta), recebendo os seguintes argumentos:
+// args[0]: cpfPagador. CPF do Pagador
+// args[1]: pagadorAceitou. Status de aceite do Pagador da proposta
+// args[2]: beneficiarioAceitou. Status de aceite do Beneficiario da proposta
+// args[3]: boletoPago. Status do Pagamento do Boleto
+// args[4]: valor (opcional). Valor do boleto em centavos
+// Retorna o Id gerado, utilizado nas demais funções
+func (t *BoletoPropostaChaincode) criarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("criarProposta...")
+
+	// O Id não é aceito como argumento nesta função
+	nova, err := lerProposta("", args)
+	if err != nil {
+		return nil, err
+	}
+
+	nova.ID, err = gerarIDProposta(stub, nova)
+	if err != nil {
+		return nil, err
+	}
+	anterior, err := t.obterProposta(stub, nova.ID)
+	if err != nil {
+		return nil, err
+	}
+	if anterior != nil {
+		return nil, novoErro(codigoPropostaJaExistente, msgPropostaJaExistente, nova.ID)
+	}
+
+	err = t.verificarPagamentoOraculo(stub, nil, nova)
+	if err != nil {
+		return nil, err
+	}
+	err = t.gravarNovaProposta(stub, nova)
+	if err != nil {
+		return nil, err
+	}
+	err = t.emitirEventos(stub, eventosTransicao(nil, nova), nova)
+	if err != nil {
+		return nil, err
+	}
+
+	logChamada(stub).Info("Proposta criada com Id gerado", "id_proposta", nova.ID)
+	jsonResp := `{"registrado":true,"id_proposta":"` + nova.ID + `"}`
+	return []byte(jsonResp), nil
+}
+
+// gerarIDProposta: hash (primitives.Hash, no nível de segurança da Configuracao) do JSON canônico da proposta,
+// sem o Id, e do Id da transação. O Id da transação torna o Id único mesmo para propostas iguais
+func gerarIDProposta(stub shim.ChaincodeStubInterface, proposta Proposta) (string, error) {
+	proposta.ID = ""
+	conteudo, err := canonico.Codificar(proposta)
+	if err != nil {
+		return "", novoErro(codigoErroInterno, msgFalhaCodificarProposta, err)
+	}
+	err = carregarNivelSeguranca(stub)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(primitives.Hash(append(append(conteudo, 0), stub.GetTxID()...))), nil
+}
+
+// validarProposta: verifica se a proposta pode ser gravada, retornando o estado anterior (nil para uma nova proposta)
+func (t *BoletoPropostaChaincode) validarProposta(stub shim.ChaincodeStubInterface, nova Proposta, versaoEsperada *uint64) (*Proposta, error) {
+	anterior, err := t.obterProposta(stub, nova.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A atualização exige a versão consultada pelo cliente, para não sobrescrever alterações concorrentes
+	if anterior != nil && versaoEsperada == nil {
+		return nil, novoErro(codigoPropostaJaExistente, msgPropostaExistenteSemVersao, nova.ID)
+	}
+	if anterior == nil && versaoEsperada != nil && *versaoEsperada != 0 {
+		return nil, erroConflitoVersao(nova.ID, *versaoEsperada, 0)
+	}
+	if anterior != nil && anterior.Versao != *versaoEsperada {
+		return nil, erroConflitoVersao(nova.ID, *versaoEsperada, anterior.Versao)
+	}
+
+	// Propostas canceladas não são alteradas, e o pagamento só é desfeito por estornarPagamento
+	if anterior != nil && anterior.Cancelada {
+		return nil, novoErro(codigoPropostaCancelada, msgPropostaCancelada, nova.ID)
+	}
+	if anterior != nil && anterior.BoletoPago && !nova.BoletoPago {
+		return nil, novoErro(codigoPagamentoRejeitado, msgPagamentoSomenteEstorno, nova.ID)
+	}
+
+	err = t.verificarPagamentoOraculo(stub, anterior, nova)
+	if err != nil {
+		return nil, err
+	}
+	return anterior, nil
+}
+
+// lerRegistroProposta: converte o JSON do RegistroProposta na proposta e na versão esperada.
+// A validação é estrita: campos desconhecidos, tipos diferentes dos publicados, campos obrigatórios
+// ausentes (ou null) e conteúdo após o documento são rejeitados
+func lerRegistroProposta(registroJSON string) (Proposta, *uint64, error) {
+	var registro RegistroProposta
+	dec := json.NewDecoder(strings.NewReader(registroJSON))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&registro)
+	if err != nil {
+		return Proposta{}, nil, novoErro(codigoArgumentosInvalidos, msgRegistroInvalido, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Proposta{}, nil, novoErro(codigoArgumentosInvalidos, msgRegistroConteudoAposFim)
+	}
+
+	obrigatorios := []struct {
+		nome    string
+		ausente bool
+	}{
+		{"id_proposta", registro.ID == nil || *registro.ID == ""},
+		{"cpf_pagador", registro.CpfPagador == nil},
+		{"pagador_aceitou", registro.PagadorAceitou == nil},
+		{"beneficiario_aceitou", registro.BeneficiarioAceitou == nil},
+		{"boleto_pago", registro.BoletoPago == nil},
+	}
+	for _, c := range obrigatorios {
+		if c.ausente {
+			return Proposta{}, nil, novoErro(codigoArgumentosInvalidos, msgRegistroCampoObrigatorio, c.nome)
+		}
+	}
+
+	proposta := Proposta{
+		ID:                  *registro.ID,
+		CpfPagador:          *registro.CpfPagador,
+		PagadorAceitou:      *registro.PagadorAceitou,
+		BeneficiarioAceitou: *registro.BeneficiarioAceitou,
+		BoletoPago:          *registro.BoletoPago,
+		Versao:              1,
+	}
+	if registro.Valor != nil {
+		if *registro.Valor < 0 {
+			return Proposta{}, nil, novoErro(codigoArgumentosInvalidos, msgRegistroValorNegativo)
+		}
+		proposta.Valor = *registro.Valor
+	}
+	return proposta, registro.VersaoEsperada, nil
+}
+
+// lerProposta: converte os argumentos (cpfPagador, pagadorAceitou, beneficiarioAceitou, boletoPago[, valor])
+// na proposta com o Id informado, na versão 1
+func lerProposta(idProposta string, args []string) (Proposta, error) {
+	proposta := Proposta{ID: idProposta, CpfPagador: args[0], Versao: 1}
+	var err error
+	proposta.PagadorAceitou, err = lerBooleano("pagadorAceitou", args[1])
+	if err != nil {
+		return proposta, err
+	}
+	proposta.BeneficiarioAceitou, err = lerBooleano("beneficiarioAceitou", args[2])
+	if err != nil {
+		return proposta, err
+	}
+	proposta.BoletoPago, err = lerBooleano("boletoPago", args[3])
+	if err != nil {
+		return proposta, err
+	}
+	if len(args) == 5 {
+		proposta.Valor, err = lerInteiro("valor", args[4])
+		if err != nil {
+			return proposta, err
+		}
+	}
+	return proposta, nil
+}
+
+// verificarPagamentoOraculo: com oráculos registrados, o pagamento só pode ser confirmado por confirmarPagamentoOracle
+func (t *BoletoPropostaChaincode) verificarPagamentoOraculo(stub shim.ChaincodeStubInterface, anterior *Proposta, nova Proposta) error {
+	if !nova.BoletoPago || (anterior != nil && anterior.BoletoPago) {
+		return nil
+	}
+	oraculos, err := t.obterOraculos(stub)
+	if err != nil {
+		return err
+	}
+	if len(oraculos) > 0 {
+		return novoErro(codigoPagamentoRejeitado, msgPagamentoPorOraculo)
+	}
+	return nil
+}
+
+// gravarNovaProposta: registra a proposta no repositório
+func (t *BoletoPropostaChaincode) gravarNovaProposta(stub shim.ChaincodeStubInterface, proposta Proposta) error {
+	repositorio, err := t.repositorioProposta(stub)
+	if err != nil {
+		return err
+	}
+	ok, err := repositorio.Gravar(stub, proposta)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaRegistrarProposta, proposta.ID, err)
+	}
+	if !ok {
+		return novoErro(codigoErroInterno, msgFalhaRegistrarPropostaDupla, proposta.ID)
+	}
+	return nil
+}
+
+// atualizarProposta: substitui o registro da proposta, se a versão gravada for a versão esperada.
+// Retorna a proposta gravada, com a versão incrementada
+func (t *BoletoPropostaChaincode) atualizarProposta(stub shim.ChaincodeStubInterface, proposta Proposta, versaoEsperada uint64) (Proposta, error) {
+	repositorio, err := t.repositorioProposta(stub)
+	if err != nil {
+		return proposta, err
+	}
+	atual, err := repositorio.Obter(stub, proposta.ID)
+	if err != nil {
+		return proposta, novoErro(codigoErroInterno, msgFalhaAtualizarProposta, proposta.ID, err)
+	}
+	if atual == nil {
+		return proposta, novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, proposta.ID)
+	}
+	if atual.Versao != versaoEsperada {
+		return proposta, erroConflitoVersao(proposta.ID, versaoEsperada, atual.Versao)
+	}
+
+	proposta.Versao = atual.Versao + 1
+	err = repositorio.Atualizar(stub, proposta)
+	if err != nil {
+		return proposta, novoErro(codigoErroInterno, msgFalhaAtualizarProposta, proposta.ID, err)
+	}
+	return proposta, nil
+}
+
+// erroConflitoVersao: a proposta foi alterada por outra transação desde a consulta do cliente
+func erroConflitoVersao(idProposta string, esperada, atual uint64) error {
+	return novoErro(codigoConflitoVersao, msgConflitoVersao, idProposta, esperada, atual)
+}
+
+// consultarProposta: função Query para consultar uma proposta existente, recebendo os seguintes argumentos
+// args[0]: Id. Hash da proposta
+func (t *BoletoPropostaChaincode) consultarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarProposta...")
+	var propostaAsBytes []byte // retorno do json em bytes
+
+	// Obtem os valores dos argumentos
+	idProposta := args[0]
+
+	// Consultar a proposta no repositório
+	resProposta, err := t.lerPropostaConsulta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+
+	logChamada(stub).Debug("Proposta consultada", "id_proposta", resProposta.ID, "cpf_pagador", resProposta.CpfPagador,
+		"pagador_aceitou", resProposta.PagadorAceitou, "beneficiario_aceitou", resProposta.BeneficiarioAceitou,
+		"boleto_pago", resProposta.BoletoPago, "versao", resProposta.Versao)
+
+	// Converter o objeto da Proposta para Bytes, para retorná-lo em formato JSON
+	propostaAsBytes, err = json.Marshal(resProposta)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaCodificarProposta, err)
+	}
+	// retorna o objeto em bytes
+	return propostaAsBytes, nil
+}
+
+// lerPropostaConsulta: obtém a proposta retornada pelas consultas (consultarProposta e consultarPropostas).
+// Retorna erro caso a proposta não exista
+func (t *BoletoPropostaChaincode) lerPropostaConsulta(stub shim.ChaincodeStubInterface, idProposta string) (*Proposta, error) {
+	// [To do] verificar identidade
+
+	proposta, err := t.obterProposta(stub, idProposta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tratamento para o caso de não encontrar nenhuma proposta correspondente
+	if proposta == nil {
+		return nil, novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, idProposta)
+	}
+	return proposta, nil
+}
+
+// consultarPropostasPorCpf: função Query para consultar as propostas de um pagador, recebendo os seguintes argumentos
+// args[0]: cpfPagador. CPF do Pagador
+func (t *BoletoPropostaChaincode) consultarPropostasPorCpf(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("consultarPropostasPorCpf...")
+
+	repositorio, err := t.repositorioProposta(stub)
+	if err != nil {
+		return nil, err
+	}
+	propostas, err := repositorio.Listar(stub, colCpfPagador, args[0])
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterPropostasCpf, args[0], err)
+	}
+	return json.Marshal(propostas)
+}
+
+// obterProposta: busca a proposta no repositório. Retorna nil caso a proposta não exista
+func (t *BoletoPropostaChaincode) obterProposta(stub shim.ChaincodeStubInterface, idProposta string) (*Proposta, error) {
+	repositorio, err := t.repositorioProposta(stub)
+	if err != nil {
+		return nil, err
+	}
+	proposta, err := repositorio.Obter(stub, idProposta)
+	if err != nil {
+		logChamada(stub).Erro("Erro ao obter Proposta", "id_proposta", idProposta, "erro", err)
+		return nil, novoErro(codigoErroInterno, msgFalhaObterProposta, idProposta, err)
+	}
+	return proposta, nil
+}
@@ -0,0 +1,295 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+)
+
+// ============================================================================================================================
+// registrarProposta
+// ============================================================================================================================
+
+func TestRegistrarPropostaCriacao(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	res, err := stub.MockInvoke(cc, "registrarProposta", "p1", "373.745.808-20", "true", "false", "false", "15000")
+	if err != nil {
+		t.Fatalf("registrarProposta: %v", err)
+	}
+	if string(res) != `{"sucesso":true,"dados":{"registrado":true}}` {
+		t.Errorf("resposta = %s", res)
+	}
+
+	esperado := Proposta{ID: "p1", CpfPagador: "373.745.808-20", PagadorAceitou: true, Valor: 15000, Versao: 1}
+	if p := consultar(t, stub, cc, "p1"); p != esperado {
+		t.Errorf("proposta = %+v; esperado %+v", p, esperado)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != eventoPropostaCriada {
+		t.Errorf("eventos = %v", tipos)
+	}
+
+	// Criação já aceita e paga emite todos os eventos da transição
+	stub.MockInvoke(cc, "registrarProposta", "p2", "111", "true", "true", "true")
+	tipos := tiposEventos(t, stub)
+	if strings.Join(tipos, ",") != "PropostaCriada,PropostaAceita,BoletoPago" {
+		t.Errorf("eventos = %v", tipos)
+	}
+}
+
+func TestRegistrarPropostaAtualizacao(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+
+	res, err := stub.MockInvoke(cc, "registrarProposta", "p1", "222", "true", "true", "false", "0", "1")
+	if err != nil {
+		t.Fatalf("registrarProposta: %v", err)
+	}
+	if string(res) != `{"sucesso":true,"dados":{"atualizado":true}}` {
+		t.Errorf("resposta da atualização = %s", res)
+	}
+
+	esperado := Proposta{ID: "p1", CpfPagador: "222", PagadorAceitou: true, BeneficiarioAceitou: true, Versao: 2}
+	if p := consultar(t, stub, cc, "p1"); p != esperado {
+		t.Errorf("proposta = %+v; esperado %+v", p, esperado)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != "PropostaAtualizada,PropostaAceita" {
+		t.Errorf("eventos = %v", tipos)
+	}
+
+	// Aceite já existente não é emitido novamente
+	stub.MockInvoke(cc, "registrarProposta", "p1", "222", "true", "true", "true", "0", "2")
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != "PropostaAtualizada,BoletoPago" {
+		t.Errorf("eventos = %v", tipos)
+	}
+}
+
+func TestRegistrarPropostaConflitoVersao(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "false", "false")
+
+	// Dois clientes consultam a versão 1; a segunda alteração é rejeitada
+	versao := strconv.FormatUint(consultar(t, stub, cc, "p1").Versao, 10)
+	if _, err := stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false", "0", versao); err != nil {
+		t.Fatalf("primeira alteração: %v", err)
+	}
+	_, err := stub.MockInvoke(cc, "registrarProposta", "p1", "111", "false", "true", "false", "0", versao)
+	verificarErro(t, "alteração concorrente", err, "Conflito de versão na Proposta [p1]: versão esperada [1], versão atual [2]")
+
+	if p := consultar(t, stub, cc, "p1"); !p.PagadorAceitou || p.BeneficiarioAceitou || p.Versao != 2 {
+		t.Errorf("proposta após o conflito = %+v", p)
+	}
+}
+
+func TestRegistrarPropostaJSON(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	res, err := stub.MockInvoke(cc, "registrarProposta", `{"id_proposta":"p1","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false,"valor":15000}`)
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"registrado":true}}` {
+		t.Fatalf("criação = %s, %v", res, err)
+	}
+	res, err = stub.MockInvoke(cc, "registrarProposta", `{"id_proposta":"p1","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":true,"boleto_pago":false,"versao_esperada":1}`)
+	if err != nil || string(res) != `{"sucesso":true,"dados":{"atualizado":true}}` {
+		t.Fatalf("atualização = %s, %v", res, err)
+	}
+	esperado := Proposta{ID: "p1", CpfPagador: "111", PagadorAceitou: true, BeneficiarioAceitou: true, Versao: 2}
+	if p := consultar(t, stub, cc, "p1"); p != esperado {
+		t.Errorf("proposta = %+v; esperado %+v", p, esperado)
+	}
+
+	casos := []struct {
+		nome   string
+		json   string
+		trecho string
+	}{
+		{"JSON inválido", `{"id_proposta":`, "Registro da proposta inválido"},
+		{"campo desconhecido", `{"id_proposta":"p2","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false,"boletoPago":true}`, `unknown field "boletoPago"`},
+		{"tipo inválido", `{"id_proposta":"p2","cpf_pagador":"111","pagador_aceitou":"true","beneficiario_aceitou":false,"boleto_pago":false}`, "pagador_aceitou"},
+		{"valor não inteiro", `{"id_proposta":"p2","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false,"valor":1.5}`, "valor"},
+		{"valor negativo", `{"id_proposta":"p2","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false,"valor":-1}`, "valor negativo"},
+		{"campo ausente", `{"id_proposta":"p2","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false}`, "campo boleto_pago obrigatório"},
+		{"campo null", `{"id_proposta":"p2","cpf_pagador":null,"pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false}`, "campo cpf_pagador obrigatório"},
+		{"id vazio", `{"id_proposta":"","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false}`, "campo id_proposta obrigatório"},
+		{"conteúdo após o documento", `{"id_proposta":"p2","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":false,"boleto_pago":false}}`, "conteúdo após o fim do documento"},
+		{"atualização sem versão", `{"id_proposta":"p1","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":true,"boleto_pago":false}`, "Informe a versão esperada"},
+		{"versão desatualizada", `{"id_proposta":"p1","cpf_pagador":"111","pagador_aceitou":true,"beneficiario_aceitou":true,"boleto_pago":false,"versao_esperada":1}`, "Conflito de versão"},
+	}
+	for _, c := range casos {
+		_, err := stub.MockInvoke(cc, "registrarProposta", c.json)
+		verificarErro(t, c.nome, err, c.trecho)
+	}
+}
+
+// TestEsquemaRegistroProposta verifica se o esquema publicado corresponde à validação do chaincode
+func TestEsquemaRegistroProposta(t *testing.T) {
+	esquemaAsBytes, err := ioutil.ReadFile("registrarProposta.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var esquema struct {
+		AdditionalProperties bool                       `json:"additionalProperties"`
+		Required             []string                   `json:"required"`
+		Properties           map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(esquemaAsBytes, &esquema); err != nil {
+		t.Fatalf("esquema inválido: %v", err)
+	}
+	if esquema.AdditionalProperties {
+		t.Error("o esquema deve rejeitar campos desconhecidos")
+	}
+
+	tipo := reflect.TypeOf(RegistroProposta{})
+	if len(esquema.Properties) != tipo.NumField() {
+		t.Errorf("%d propriedades no esquema; esperado %d", len(esquema.Properties), tipo.NumField())
+	}
+	for i := 0; i < tipo.NumField(); i++ {
+		if _, ok := esquema.Properties[tipo.Field(i).Tag.Get("json")]; !ok {
+			t.Errorf("campo %s ausente no esquema", tipo.Field(i).Tag.Get("json"))
+		}
+	}
+
+	// Cada campo obrigatório do esquema é exigido pelo chaincode
+	completo := map[string]interface{}{
+		"id_proposta": "p1", "cpf_pagador": "111", "pagador_aceitou": true, "beneficiario_aceitou": false, "boleto_pago": false,
+	}
+	for _, obrigatorio := range esquema.Required {
+		registro := map[string]interface{}{}
+		for k, v := range completo {
+			if k != obrigatorio {
+				registro[k] = v
+			}
+		}
+		registroAsBytes, _ := json.Marshal(registro)
+		if _, _, err := lerRegistroProposta(string(registroAsBytes)); err == nil {
+			t.Errorf("registro sem %s aceito", obrigatorio)
+		}
+	}
+	completoAsBytes, _ := json.Marshal(completo)
+	if _, _, err := lerRegistroProposta(string(completoAsBytes)); err != nil || len(esquema.Required) != len(completo) {
+		t.Errorf("registro com os campos obrigatórios: %v", err)
+	}
+}
+
+func TestRegistrarPropostaErros(t *testing.T) {
+	valida := []string{"p1", "111", "true", "false", "false"}
+
+	casos := []struct {
+		nome   string
+		args   []string
+		caller string
+		falha  string // método do stub que deve falhar
+		existe bool   // registra a proposta antes do teste
+		trecho string
+	}{
+		{nome: "argumentos insuficientes", args: valida[:4], trecho: "Esperado 1, 5, 6 ou 7"},
+		{nome: "argumentos em excesso", args: append(valida, "1", "2", "3"), trecho: "Esperado 1, 5, 6 ou 7"},
+		{nome: "pagadorAceitou inválido", args: []string{"p1", "111", "x", "false", "false"}, trecho: "pagadorAceitou"},
+		{nome: "beneficiarioAceitou inválido", args: []string{"p1", "111", "true", "x", "false"}, trecho: "beneficiarioAceitou"},
+		{nome: "boletoPago inválido", args: []string{"p1", "111", "true", "false", "x"}, trecho: "boletoPago"},
+		{nome: "valor inválido", args: append(valida, "abc"), trecho: "valor"},
+		{nome: "valor negativo", args: append(valida, "-1"), trecho: "valor"},
+		{nome: "versão inválida", args: append(valida, "0", "-1"), trecho: "versaoEsperada"},
+		{nome: "versão não informada", args: valida, existe: true, trecho: "Informe a versão esperada"},
+		{nome: "versão na criação", args: append(valida, "0", "1"), trecho: "Conflito de versão na Proposta [p1]: versão esperada [1], versão atual [0]"},
+		{nome: "caller não administrador", args: valida, caller: "outro", trecho: "Falha ao verificar a identidade do administrador"},
+		{nome: "falha ao obter módulos", args: valida, falha: "GetState", trecho: "Falha ao obter os módulos"},
+		{nome: "falha ao obter metadata", args: valida, falha: "GetCallerMetadata", trecho: "Falha ao verificar a identidade do administrador"},
+		{nome: "falha ao consultar proposta", args: valida, falha: "GetRow", trecho: "Erro ao obter Proposta"},
+		{nome: "falha ao inserir", args: valida, falha: "InsertRow", trecho: "Falha ao registrar a Proposta"},
+		{nome: "falha ao atualizar", args: append(valida, "0", "1"), falha: "ReplaceRow", existe: true, trecho: "Falha ao atualizar a Proposta"},
+		{nome: "falha ao gravar evento", args: valida, falha: "PutState", trecho: "Falha ao registrar o Evento"},
+		{nome: "falha ao emitir evento", args: valida, falha: "SetEvent", trecho: "falha simulada"},
+	}
+
+	for _, c := range casos {
+		stub, cc := novoChaincode(t)
+		if c.existe {
+			stub.MockInvoke(cc, "registrarProposta", valida...)
+		}
+		if c.caller != "" {
+			stub.CallerMetadata = []byte(c.caller)
+		}
+		if c.falha != "" {
+			stub.SimularFalha(c.falha, errors.New("falha simulada"))
+		}
+
+		_, err := stub.MockInvoke(cc, "registrarProposta", c.args...)
+		verificarErro(t, c.nome, err, c.trecho)
+	}
+}
+
+// ============================================================================================================================
+// criarProposta
+// ============================================================================================================================
+
+func TestCriarProposta(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	res, err := stub.MockInvoke(cc, "criarProposta", "111", "true", "false", "false", "15000")
+	if err != nil {
+		t.Fatalf("criarProposta: %v", err)
+	}
+	var resposta struct {
+		Registrado bool   `json:"registrado"`
+		IDProposta string `json:"id_proposta"`
+	}
+	lerDados(res, &resposta)
+
+	// O Id é o SHA3 do JSON canônico da proposta sem o Id, seguido do Id da transação
+	conteudo := `{"beneficiario_aceitou":false,"boleto_pago":false,"cpf_pagador":"111","id_proposta":"","pagador_aceitou":true,"valor":15000,"versao":1}` + "\x00" + stub.TxID
+	id := hex.EncodeToString(primitives.Hash([]byte(conteudo)))
+	if !resposta.Registrado || resposta.IDProposta != id {
+		t.Fatalf("resposta = %s; esperado o Id %s", res, id)
+	}
+	esperado := Proposta{ID: id, CpfPagador: "111", PagadorAceitou: true, Valor: 15000, Versao: 1}
+	if p := consultar(t, stub, cc, id); p != esperado {
+		t.Errorf("proposta = %+v; esperado %+v", p, esperado)
+	}
+	if tipos := tiposEventos(t, stub); strings.Join(tipos, ",") != eventoPropostaCriada {
+		t.Errorf("eventos = %v", tipos)
+	}
+
+	// A mesma proposta em outra transação recebe outro Id
+	res, _ = stub.MockInvoke(cc, "criarProposta", "111", "true", "false", "false", "15000")
+	var outra map[string]string
+	lerDados(res, &outra)
+	if outra["id_proposta"] == "" || outra["id_proposta"] == id {
+		t.Errorf("Id da segunda proposta = %q", outra["id_proposta"])
+	}
+
+	// O Id escolhido pelo cliente não é aceito
+	_, err = stub.MockInvoke(cc, "criarProposta", "p1", "111", "true", "false", "false", "15000")
+	verificarErro(t, "Id do cliente", err, "o Id da proposta é gerado pelo chaincode")
+	_, err = stub.MockInvoke(cc, "criarProposta", "111", "x", "false", "false")
+	verificarErro(t, "pagadorAceitou inválido", err, "pagadorAceitou")
+	stub.CallerMetadata = []byte("outro")
+	_, err = stub.MockInvoke(cc, "criarProposta", "111", "true", "false", "false")
+	verificarErro(t, "caller não administrador", err, "Falha ao verificar a identidade do administrador")
+}
+
+// ============================================================================================================================
+// consultarProposta
+// ============================================================================================================================
+
+func TestConsultarPropostaErros(t *testing.T) {
+	stub, cc := novoChaincode(t)
+
+	_, err := stub.MockQuery(cc, "consultarProposta")
+	verificarErro(t, "sem argumentos", err, "Esperado 1")
+
+	_, err = stub.MockQuery(cc, "consultarProposta", "inexistente")
+	verificarErro(t, "proposta inexistente", err, "Proposta [inexistente] não existente.")
+
+	stub.SimularFalha("GetRow", errors.New("falha simulada"))
+	_, err = stub.MockQuery(cc, "consultarProposta", "p1")
+	verificarErro(t, "falha no GetRow", err, "Erro ao obter Proposta [p1]")
+}
@@ -0,0 +1,484 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// RepositorioProposta - persistência das propostas, independente da forma de armazenamento
+type RepositorioProposta interface {
+	// Criar cria a estrutura de armazenamento (tabelas) das propostas
+	Criar(stub shim.ChaincodeStubInterface) error
+	// Excluir remove todas as propostas e a estrutura de armazenamento
+	Excluir(stub shim.ChaincodeStubInterface) error
+	// Obter retorna a proposta com o id informado, ou nil caso ela não exista
+	Obter(stub shim.ChaincodeStubInterface, idProposta string) (*Proposta, error)
+	// Gravar registra uma nova proposta. Retorna false se a proposta já existir
+	Gravar(stub shim.ChaincodeStubInterface, proposta Proposta) (bool, error)
+	// Atualizar substitui uma proposta existente
+	Atualizar(stub shim.ChaincodeStubInterface, proposta Proposta) error
+	// Listar retorna as propostas com o valor informado na coluna indexada
+	Listar(stub shim.ChaincodeStubInterface, indice, valor string) ([]Proposta, error)
+	// ListarTodas retorna todas as propostas, em ordem de id
+	ListarTodas(stub shim.ChaincodeStubInterface) ([]Proposta, error)
+	// Contar retorna a quantidade de propostas, sem decodificá-las
+	Contar(stub shim.ChaincodeStubInterface) (int, error)
+}
+
+// formas de armazenamento das propostas, escolhidas no Init com o argumento "armazenamento=<forma>"
+const (
+	armazenamentoTabela = "tabela" // tabela 'Proposta' (padrão)
+	armazenamentoJSON   = "json"   // JSON da proposta no estado, por chave
+)
+
+// consts associadas ao armazenamento das propostas em JSON
+const (
+	prefixoChaveProposta = "proposta_"
+	prefixoChaveIndice   = "indice_" // indice_<tabela>_<coluna>_<valor>\x00<id>
+)
+
+// mapeamento da Proposta, pelas tags `coluna`
+var mapeamentoProposta = mapear(nomeTabelaProposta, Proposta{})
+
+// repositorios: formas de armazenamento suportadas
+var repositorios = map[string]RepositorioProposta{
+	armazenamentoTabela: repositorioTabela{mapeamentoProposta},
+	armazenamentoJSON:   repositorioJSON{mapeamentoProposta},
+}
+
+// repositorioProposta: retorna o repositório da forma de armazenamento escolhida no Init
+func (t *BoletoPropostaChaincode) repositorioProposta(stub shim.ChaincodeStubInterface) (RepositorioProposta, error) {
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return nil, err
+	}
+	return obterRepositorio(modulos.Armazenamento)
+}
+
+// obterRepositorio: repositório da forma de armazenamento informada ("" para o padrão)
+func obterRepositorio(armazenamento string) (RepositorioProposta, error) {
+	armazenamento = normalizarArmazenamento(armazenamento)
+	repositorio, ok := repositorios[armazenamento]
+	if !ok {
+		return nil, novoErro(codigoArgumentosInvalidos, msgArmazenamentoDesconhecido, armazenamento)
+	}
+	return repositorio, nil
+}
+
+// normalizarArmazenamento: forma de armazenamento, com "" (módulos gravados antes da opção) para o padrão
+func normalizarArmazenamento(armazenamento string) string {
+	if armazenamento == "" {
+		return armazenamentoTabela
+	}
+	return armazenamento
+}
+
+// ============================================================================================================================
+// Armazenamento em tabela
+// ============================================================================================================================
+
+// repositorioTabela - propostas na tabela 'Proposta'. Cada coluna indexada tem uma tabela
+// '<tabela>_<coluna>' com as chaves (valor, id), utilizada por Listar
+type repositorioTabela struct {
+	m mapeamento
+}
+
+// tabelaIndice: nome da tabela do índice da coluna
+func (r repositorioTabela) tabelaIndice(c campoMapeado) string {
+	return r.m.tabela + "_" + c.coluna
+}
+
+func (r repositorioTabela) Criar(stub shim.ChaincodeStubInterface) error {
+	logChamada(stub).Info("Criando a tabela...", "tabela", r.m.tabela)
+	err := stub.CreateTable(r.m.tabela, r.m.definicoes())
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCriarTabela, r.m.tabela, err)
+	}
+
+	for _, c := range r.m.indices() {
+		err = r.criarIndice(stub, c)
+		if err != nil {
+			return err
+		}
+	}
+	logChamada(stub).Info("Tabela criada com sucesso.", "tabela", r.m.tabela)
+	return nil
+}
+
+// criarIndice: cria a tabela do índice da coluna, com as chaves (valor, id)
+func (r repositorioTabela) criarIndice(stub shim.ChaincodeStubInterface, c campoMapeado) error {
+	err := stub.CreateTable(r.tabelaIndice(c), []*shim.ColumnDefinition{
+		&shim.ColumnDefinition{Name: c.coluna, Type: shim.ColumnDefinition_STRING, Key: true},
+		&shim.ColumnDefinition{Name: r.m.chaves()[0].coluna, Type: shim.ColumnDefinition_STRING, Key: true},
+	})
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCriarTabela, r.tabelaIndice(c), err)
+	}
+	return nil
+}
+
+// tabelas: nomes da tabela das propostas e das tabelas dos índices
+func (r repositorioTabela) tabelas() []string {
+	tabelas := []string{r.m.tabela}
+	for _, c := range r.m.indices() {
+		tabelas = append(tabelas, r.tabelaIndice(c))
+	}
+	return tabelas
+}
+
+func (r repositorioTabela) Excluir(stub shim.ChaincodeStubInterface) error {
+	for _, tabela := range r.tabelas() {
+		// GetTable retorna erro quando a tabela não existe
+		tb, err := stub.GetTable(tabela)
+		if err != nil || tb == nil {
+			continue
+		}
+		err = stub.DeleteTable(tabela)
+		if err != nil {
+			return novoErro(codigoErroInterno, msgFalhaExcluirTabela, tabela, err)
+		}
+		logChamada(stub).Info("Tabela excluída.", "tabela", tabela)
+	}
+	return nil
+}
+
+func (r repositorioTabela) Obter(stub shim.ChaincodeStubInterface, idProposta string) (*Proposta, error) {
+	row, err := stub.GetRow(r.m.tabela, []shim.Column{colunaTexto(idProposta)})
+	if err != nil {
+		return nil, err
+	}
+	if len(row.Columns) == 0 {
+		return nil, nil
+	}
+
+	var proposta Proposta
+	err = r.m.decodificar(row, &proposta)
+	if err != nil {
+		return nil, err
+	}
+	return &proposta, nil
+}
+
+func (r repositorioTabela) Gravar(stub shim.ChaincodeStubInterface, proposta Proposta) (bool, error) {
+	ok, err := stub.InsertRow(r.m.tabela, r.m.linha(proposta))
+	if err != nil || !ok {
+		return ok, err
+	}
+	for _, c := range r.m.indices() {
+		err = r.gravarIndice(stub, c, r.m.texto(proposta, c), proposta.ID)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (r repositorioTabela) Atualizar(stub shim.ChaincodeStubInterface, proposta Proposta) error {
+	anterior, err := r.Obter(stub, proposta.ID)
+	if err != nil {
+		return err
+	}
+	if anterior == nil {
+		return novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, proposta.ID)
+	}
+
+	ok, err := stub.ReplaceRow(r.m.tabela, r.m.linha(proposta))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, proposta.ID)
+	}
+
+	// Atualiza os índices cujo valor foi alterado
+	for _, c := range r.m.indices() {
+		valorAnterior, valor := r.m.texto(anterior, c), r.m.texto(proposta, c)
+		if valorAnterior == valor {
+			continue
+		}
+		err = stub.DeleteRow(r.tabelaIndice(c), []shim.Column{colunaTexto(valorAnterior), colunaTexto(proposta.ID)})
+		if err != nil {
+			return err
+		}
+		err = r.gravarIndice(stub, c, valor, proposta.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r repositorioTabela) Listar(stub shim.ChaincodeStubInterface, indice, valor string) ([]Proposta, error) {
+	c, ok := r.m.indice(indice)
+	if !ok {
+		return nil, novoErro(codigoErroInterno, msgIndiceDesconhecido, indice)
+	}
+	rows, err := stub.GetRows(r.tabelaIndice(c), []shim.Column{colunaTexto(valor)})
+	if err != nil {
+		return nil, err
+	}
+
+	propostas := []Proposta{}
+	for row := range rows {
+		proposta, err := r.Obter(stub, row.Columns[1].GetString_())
+		if err != nil {
+			return nil, err
+		}
+		if proposta != nil {
+			propostas = append(propostas, *proposta)
+		}
+	}
+	return propostas, nil
+}
+
+func (r repositorioTabela) ListarTodas(stub shim.ChaincodeStubInterface) ([]Proposta, error) {
+	rows, err := stub.GetRows(r.m.tabela, []shim.Column{})
+	if err != nil {
+		return nil, err
+	}
+
+	propostas := []Proposta{}
+	for row := range rows {
+		var proposta Proposta
+		err = r.m.decodificar(row, &proposta)
+		if err != nil {
+			return nil, err
+		}
+		propostas = append(propostas, proposta)
+	}
+	return propostas, nil
+}
+
+// Contar: a API de tabelas da versão 0.6 não tem contagem, e as linhas da tabela principal são lidas
+// sem decodificar. Sem a tabela (ex.: antes da migração) não há propostas
+func (r repositorioTabela) Contar(stub shim.ChaincodeStubInterface) (int, error) {
+	// GetTable retorna erro quando a tabela não existe
+	tb, err := stub.GetTable(r.m.tabela)
+	if err != nil || tb == nil {
+		return 0, nil
+	}
+	return contarLinhas(stub, r.m.tabela)
+}
+
+// gravarIndice: registra o id da proposta no índice da coluna
+func (r repositorioTabela) gravarIndice(stub shim.ChaincodeStubInterface, c campoMapeado, valor, idProposta string) error {
+	_, err := stub.InsertRow(r.tabelaIndice(c), shim.Row{
+		Columns: []*shim.Column{
+			&shim.Column{Value: &shim.Column_String_{String_: valor}},
+			&shim.Column{Value: &shim.Column_String_{String_: idProposta}},
+		},
+	})
+	return err
+}
+
+// ============================================================================================================================
+// Armazenamento em JSON
+// ============================================================================================================================
+
+// repositorioJSON - propostas em JSON no estado, na chave proposta_<id>. Cada coluna indexada
+// grava a chave indice_<tabela>_<coluna>_<valor>\x00<id>, utilizada por Listar
+type repositorioJSON struct {
+	m mapeamento
+}
+
+// chave: chave do estado da proposta
+func (r repositorioJSON) chave(idProposta string) string {
+	return prefixoChaveProposta + idProposta
+}
+
+// prefixoIndice: prefixo das chaves do índice da coluna para o valor informado
+func (r repositorioJSON) prefixoIndice(c campoMapeado, valor string) string {
+	return prefixoChaveIndice + r.m.tabela + "_" + c.coluna + "_" + valor + "\x00"
+}
+
+func (r repositorioJSON) Criar(stub shim.ChaincodeStubInterface) error {
+	return nil
+}
+
+func (r repositorioJSON) Excluir(stub shim.ChaincodeStubInterface) error {
+	for _, prefixo := range []string{prefixoChaveProposta, prefixoChaveIndice + r.m.tabela + "_"} {
+		chaves, err := chavesComPrefixo(stub, prefixo)
+		if err != nil {
+			return err
+		}
+		for _, chave := range chaves {
+			err = stub.DelState(chave)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r repositorioJSON) Obter(stub shim.ChaincodeStubInterface, idProposta string) (*Proposta, error) {
+	propostaAsBytes, err := stub.GetState(r.chave(idProposta))
+	if err != nil {
+		return nil, err
+	}
+	if len(propostaAsBytes) == 0 {
+		return nil, nil
+	}
+
+	var proposta Proposta
+	err = json.Unmarshal(propostaAsBytes, &proposta)
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaDecodificarRegistro, "Proposta", err)
+	}
+	return &proposta, nil
+}
+
+func (r repositorioJSON) Gravar(stub shim.ChaincodeStubInterface, proposta Proposta) (bool, error) {
+	anterior, err := r.Obter(stub, proposta.ID)
+	if err != nil {
+		return false, err
+	}
+	if anterior != nil {
+		return false, nil
+	}
+
+	err = r.gravar(stub, proposta)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range r.m.indices() {
+		err = stub.PutState(r.prefixoIndice(c, r.m.texto(proposta, c))+proposta.ID, []byte(proposta.ID))
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (r repositorioJSON) Atualizar(stub shim.ChaincodeStubInterface, proposta Proposta) error {
+	anterior, err := r.Obter(stub, proposta.ID)
+	if err != nil {
+		return err
+	}
+	if anterior == nil {
+		return novoErro(codigoPropostaNaoEncontrada, msgPropostaNaoExistente, proposta.ID)
+	}
+
+	err = r.gravar(stub, proposta)
+	if err != nil {
+		return err
+	}
+
+	// Atualiza os índices cujo valor foi alterado
+	for _, c := range r.m.indices() {
+		valorAnterior, valor := r.m.texto(anterior, c), r.m.texto(proposta, c)
+		if valorAnterior == valor {
+			continue
+		}
+		err = stub.DelState(r.prefixoIndice(c, valorAnterior) + proposta.ID)
+		if err != nil {
+			return err
+		}
+		err = stub.PutState(r.prefixoIndice(c, valor)+proposta.ID, []byte(proposta.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r repositorioJSON) Listar(stub shim.ChaincodeStubInterface, indice, valor string) ([]Proposta, error) {
+	c, ok := r.m.indice(indice)
+	if !ok {
+		return nil, novoErro(codigoErroInterno, msgIndiceDesconhecido, indice)
+	}
+	chaves, err := chavesComPrefixo(stub, r.prefixoIndice(c, valor))
+	if err != nil {
+		return nil, err
+	}
+
+	prefixo := r.prefixoIndice(c, valor)
+	propostas := []Proposta{}
+	for _, chave := range chaves {
+		proposta, err := r.Obter(stub, chave[len(prefixo):])
+		if err != nil {
+			return nil, err
+		}
+		if proposta != nil {
+			propostas = append(propostas, *proposta)
+		}
+	}
+	return propostas, nil
+}
+
+func (r repositorioJSON) ListarTodas(stub shim.ChaincodeStubInterface) ([]Proposta, error) {
+	chaves, err := chavesComPrefixo(stub, prefixoChaveProposta)
+	if err != nil {
+		return nil, err
+	}
+
+	propostas := []Proposta{}
+	for _, chave := range chaves {
+		proposta, err := r.Obter(stub, chave[len(prefixoChaveProposta):])
+		if err != nil {
+			return nil, err
+		}
+		if proposta != nil {
+			propostas = append(propostas, *proposta)
+		}
+	}
+	return propostas, nil
+}
+
+// Contar: conta as chaves das propostas, sem decodificar o JSON
+func (r repositorioJSON) Contar(stub shim.ChaincodeStubInterface) (int, error) {
+	chaves, err := chavesComPrefixo(stub, prefixoChaveProposta)
+	if err != nil {
+		return 0, novoErro(codigoErroInterno, msgFalhaContarPropostas, err)
+	}
+	return len(chaves), nil
+}
+
+// gravar: grava o JSON da proposta
+func (r repositorioJSON) gravar(stub shim.ChaincodeStubInterface, proposta Proposta) error {
+	propostaAsBytes, err := json.Marshal(proposta)
+	if err != nil {
+		return novoErro(codigoErroInterno, msgFalhaCodificarRegistro, "Proposta", err)
+	}
+	return stub.PutState(r.chave(proposta.ID), propostaAsBytes)
+}
+
+// chavesComPrefixo: chaves do estado iniciadas pelo prefixo informado, em ordem
+func chavesComPrefixo(stub shim.ChaincodeStubInterface, prefixo string) ([]string, error) {
+	// O fim do intervalo é o prefixo com o último byte incrementado
+	fim := prefixo[:len(prefixo)-1] + string([]byte{prefixo[len(prefixo)-1] + 1})
+	it, err := stub.RangeQueryState(prefixo, fim)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var chaves []string
+	for it.HasNext() {
+		chave, _, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		chaves = append(chaves, chave)
+	}
+	return chaves, nil
+}
@@ -0,0 +1,116 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/CaueP/BlockchainDesafio/chaincode/shimtest"
+)
+
+// TestRepositorios executa os mesmos cenários nas duas formas de armazenamento
+func TestRepositorios(t *testing.T) {
+	for nome, r := range repositorios {
+		stub := shimtest.NewStub()
+		if err := r.Criar(stub); err != nil {
+			t.Fatalf("%s: Criar: %v", nome, err)
+		}
+
+		p1 := Proposta{ID: "p1", CpfPagador: "111", PagadorAceitou: true, Valor: 100}
+		p2 := Proposta{ID: "p2", CpfPagador: "111"}
+		for _, p := range []Proposta{p1, p2} {
+			if ok, err := r.Gravar(stub, p); err != nil || !ok {
+				t.Fatalf("%s: Gravar(%s) = %t, %v", nome, p.ID, ok, err)
+			}
+		}
+		if ok, err := r.Gravar(stub, p1); err != nil || ok {
+			t.Errorf("%s: Gravar de proposta existente = %t, %v; esperado false", nome, ok, err)
+		}
+
+		obtida, err := r.Obter(stub, "p1")
+		if err != nil || obtida == nil || *obtida != p1 {
+			t.Errorf("%s: Obter(p1) = %+v, %v", nome, obtida, err)
+		}
+		if obtida, err := r.Obter(stub, "inexistente"); err != nil || obtida != nil {
+			t.Errorf("%s: Obter(inexistente) = %+v, %v; esperado nil", nome, obtida, err)
+		}
+
+		// A alteração do CPF move a proposta entre os índices
+		p2.CpfPagador = "222"
+		p2.BoletoPago = true
+		if err := r.Atualizar(stub, p2); err != nil {
+			t.Fatalf("%s: Atualizar: %v", nome, err)
+		}
+		if err := r.Atualizar(stub, Proposta{ID: "inexistente"}); err == nil {
+			t.Errorf("%s: Atualizar de proposta inexistente: esperado erro", nome)
+		}
+
+		listaCpf := func(cpf string) []Proposta {
+			lista, err := r.Listar(stub, colCpfPagador, cpf)
+			if err != nil {
+				t.Fatalf("%s: Listar(%s): %v", nome, cpf, err)
+			}
+			return lista
+		}
+		if lista := listaCpf("111"); len(lista) != 1 || lista[0] != p1 {
+			t.Errorf("%s: Listar(111) = %+v", nome, lista)
+		}
+		if lista := listaCpf("222"); len(lista) != 1 || lista[0] != p2 {
+			t.Errorf("%s: Listar(222) = %+v", nome, lista)
+		}
+		if lista := listaCpf("11"); len(lista) != 0 {
+			t.Errorf("%s: Listar(11) = %+v; esperado vazio", nome, lista)
+		}
+		if _, err := r.Listar(stub, "valor", "100"); err == nil {
+			t.Errorf("%s: Listar por coluna não indexada: esperado erro", nome)
+		}
+
+		if err := r.Excluir(stub); err != nil {
+			t.Fatalf("%s: Excluir: %v", nome, err)
+		}
+		r.Criar(stub)
+		if obtida, _ := r.Obter(stub, "p1"); obtida != nil {
+			t.Errorf("%s: proposta mantida após Excluir: %+v", nome, obtida)
+		}
+		if lista := listaCpf("111"); len(lista) != 0 {
+			t.Errorf("%s: índice mantido após Excluir: %+v", nome, lista)
+		}
+	}
+}
+
+func TestArmazenamentoJSON(t *testing.T) {
+	stub, cc := iniciarChaincode(t, "armazenamento=json")
+
+	if _, err := stub.GetTable(nomeTabelaProposta); err == nil {
+		t.Error("tabela Proposta criada com armazenamento json")
+	}
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false", "100")
+	stub.MockInvoke(cc, "registrarProposta", "p2", "111", "false", "false", "false")
+
+	var gravada Proposta
+	if err := json.Unmarshal(stub.State(prefixoChaveProposta+"p1"), &gravada); err != nil || gravada.Valor != 100 {
+		t.Errorf("JSON gravado = %s", stub.State(prefixoChaveProposta+"p1"))
+	}
+
+	res, err := stub.MockQuery(cc, "consultarPropostasPorCpf", "111")
+	if err != nil {
+		t.Fatalf("consultarPropostasPorCpf: %v", err)
+	}
+	var propostas []Proposta
+	lerDados(res, &propostas)
+	if len(propostas) != 2 || propostas[0].ID != "p1" || propostas[1].ID != "p2" {
+		t.Errorf("propostas = %+v", propostas)
+	}
+
+	// O reset com outra forma de armazenamento exclui as propostas gravadas em JSON
+	if _, err := stub.MockInit(cc, "init"); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if stub.State(prefixoChaveProposta+"p1") != nil {
+		t.Error("proposta em JSON mantida após o reset")
+	}
+
+	_, err = stub.MockInit(cc, "init", "armazenamento=xml")
+	verificarErro(t, "armazenamento desconhecido", err, "Armazenamento desconhecido: xml")
+}
@@ -0,0 +1,630 @@
+/*
+Implementação em memória de shim.ChaincodeStubInterface para testes unitários
+do chaincode, com suporte a estado, tabelas, metadata do caller e eventos.
+
+Uso típico:
+
+	stub := shimtest.NewStub()
+	stub.CallerMetadata = []byte("admin")
+	_, err := stub.MockInit(cc, "init")
+	res, err := stub.MockInvoke(cc, "registrarProposta", "1", "123", "true", "false", "false")
+	res, err = stub.MockQuery(cc, "consultarProposta", "1")
+
+MockInit, MockInvoke e MockQuery executam cada chamada como uma transação:
+um novo TxID é gerado e as alterações são descartadas se o chaincode retornar
+erro. Em MockQuery qualquer escrita retorna erro.
+*/
+
+// nome do package
+package shimtest
+
+// lista de imports
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
+)
+
+// garante que o Stub implementa a interface do shim
+var _ shim.ChaincodeStubInterface = (*Stub)(nil)
+
+// Event - evento emitido pelo chaincode com SetEvent
+type Event struct {
+	TxID    string
+	Name    string
+	Payload []byte
+}
+
+// Stub - implementação em memória de shim.ChaincodeStubInterface
+type Stub struct {
+	TxID              string
+	Timestamp         *timestamp.Timestamp
+	CallerMetadata    []byte
+	CallerCertificate []byte
+	Attributes        map[string][]byte // atributos do certificado do caller
+	Events            []Event           // eventos emitidos pelas transações confirmadas
+
+	args    []string
+	state   map[string][]byte
+	tables  map[string]*table
+	event   *Event // evento da transação em andamento (o Fabric 0.6 mantém apenas o último)
+	falhas  map[string]error
+	leitura bool // transação de Query: escritas não são permitidas
+	seqTx   int
+}
+
+// tabela em memória: definição e linhas indexadas pela chave codificada
+type table struct {
+	def  shim.Table
+	rows map[string]shim.Row
+}
+
+// NewStub - cria um stub vazio
+func NewStub() *Stub {
+	return &Stub{
+		Timestamp:  &timestamp.Timestamp{},
+		Attributes: map[string][]byte{},
+		state:      map[string][]byte{},
+		tables:     map[string]*table{},
+		falhas:     map[string]error{},
+	}
+}
+
+// ============================================================================================================================
+// Transações
+// ============================================================================================================================
+
+// MockInit - executa o Init do chaincode em uma transação
+func (s *Stub) MockInit(cc shim.Chaincode, function string, args ...string) ([]byte, error) {
+	return s.transacao(false, function, args, func() ([]byte, error) {
+		return cc.Init(s, function, args)
+	})
+}
+
+// MockInvoke - executa uma função Invoke do chaincode em uma transação
+func (s *Stub) MockInvoke(cc shim.Chaincode, function string, args ...string) ([]byte, error) {
+	return s.transacao(false, function, args, func() ([]byte, error) {
+		return cc.Invoke(s, function, args)
+	})
+}
+
+// MockQuery - executa uma função Query do chaincode, sem permitir escritas
+func (s *Stub) MockQuery(cc shim.Chaincode, function string, args ...string) ([]byte, error) {
+	return s.transacao(true, function, args, func() ([]byte, error) {
+		return cc.Query(s, function, args)
+	})
+}
+
+// SimularFalha - faz o método informado (ex.: "GetRow", "PutState") retornar err
+// até que SimularFalha seja chamado novamente com err nil
+func (s *Stub) SimularFalha(metodo string, err error) {
+	if err == nil {
+		delete(s.falhas, metodo)
+		return
+	}
+	s.falhas[metodo] = err
+}
+
+// LastEvent - último evento confirmado, ou nil se nenhum evento foi emitido
+func (s *Stub) LastEvent() *Event {
+	if len(s.Events) == 0 {
+		return nil
+	}
+	return &s.Events[len(s.Events)-1]
+}
+
+// State - valor atual de uma chave do estado, sem passar pelo controle de transação
+func (s *Stub) State(key string) []byte {
+	return s.state[key]
+}
+
+// transacao: executa fn com um novo TxID, descartando as alterações em caso de erro
+func (s *Stub) transacao(leitura bool, function string, args []string, fn func() ([]byte, error)) ([]byte, error) {
+	s.seqTx++
+	s.TxID = fmt.Sprintf("tx%d", s.seqTx)
+	s.args = append([]string{function}, args...)
+	s.leitura = leitura
+	s.event = nil
+
+	state, tables := s.copiar()
+	res, err := fn()
+	if err != nil {
+		s.state, s.tables = state, tables
+	} else if s.event != nil {
+		s.Events = append(s.Events, *s.event)
+	}
+
+	s.leitura = false
+	s.event = nil
+	return res, err
+}
+
+// copiar: cópia do estado e das tabelas, utilizada para desfazer transações com erro
+func (s *Stub) copiar() (map[string][]byte, map[string]*table) {
+	state := make(map[string][]byte, len(s.state))
+	for k, v := range s.state {
+		state[k] = v
+	}
+	tables := make(map[string]*table, len(s.tables))
+	for name, t := range s.tables {
+		rows := make(map[string]shim.Row, len(t.rows))
+		for k, r := range t.rows {
+			rows[k] = r
+		}
+		tables[name] = &table{def: t.def, rows: rows}
+	}
+	return state, tables
+}
+
+// falha: erro simulado para o método, se houver
+func (s *Stub) falha(metodo string) error {
+	return s.falhas[metodo]
+}
+
+// escrita: verifica se a transação permite escritas
+func (s *Stub) escrita(metodo string) error {
+	if err := s.falha(metodo); err != nil {
+		return err
+	}
+	if s.leitura {
+		return fmt.Errorf("%s não permitido em Query", metodo)
+	}
+	return nil
+}
+
+// ============================================================================================================================
+// Argumentos e identidade
+// ============================================================================================================================
+
+// GetArgs - função e argumentos da transação em andamento
+func (s *Stub) GetArgs() [][]byte {
+	args := make([][]byte, len(s.args))
+	for i, a := range s.args {
+		args[i] = []byte(a)
+	}
+	return args
+}
+
+// GetStringArgs - função e argumentos da transação em andamento
+func (s *Stub) GetStringArgs() []string {
+	return s.args
+}
+
+// GetTxID - id da transação em andamento
+func (s *Stub) GetTxID() string {
+	return s.TxID
+}
+
+// GetTxTimestamp - timestamp da transação em andamento
+func (s *Stub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	if err := s.falha("GetTxTimestamp"); err != nil {
+		return nil, err
+	}
+	return s.Timestamp, nil
+}
+
+// GetCallerMetadata - metadata enviada pelo caller
+func (s *Stub) GetCallerMetadata() ([]byte, error) {
+	if err := s.falha("GetCallerMetadata"); err != nil {
+		return nil, err
+	}
+	return s.CallerMetadata, nil
+}
+
+// GetCallerCertificate - certificado do caller
+func (s *Stub) GetCallerCertificate() ([]byte, error) {
+	if err := s.falha("GetCallerCertificate"); err != nil {
+		return nil, err
+	}
+	return s.CallerCertificate, nil
+}
+
+// GetBinding - não suportado pelo stub
+func (s *Stub) GetBinding() ([]byte, error) {
+	return nil, s.falha("GetBinding")
+}
+
+// GetPayload - não suportado pelo stub
+func (s *Stub) GetPayload() ([]byte, error) {
+	return nil, s.falha("GetPayload")
+}
+
+// ReadCertAttribute - atributo do certificado do caller
+func (s *Stub) ReadCertAttribute(attributeName string) ([]byte, error) {
+	if err := s.falha("ReadCertAttribute"); err != nil {
+		return nil, err
+	}
+	v, ok := s.Attributes[attributeName]
+	if !ok {
+		return nil, fmt.Errorf("Atributo %s não encontrado", attributeName)
+	}
+	return v, nil
+}
+
+// VerifyAttribute - compara o atributo do certificado do caller com o valor informado
+func (s *Stub) VerifyAttribute(attributeName string, attributeValue []byte) (bool, error) {
+	v, err := s.ReadCertAttribute(attributeName)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(v, attributeValue), nil
+}
+
+// VerifyAttributes - compara os atributos do certificado do caller com os valores informados
+func (s *Stub) VerifyAttributes(attrs ...*attr.Attribute) (bool, error) {
+	for _, a := range attrs {
+		ok, err := s.VerifyAttribute(a.Name, a.Value)
+		if err != nil || !ok {
+			return ok, err
+		}
+	}
+	return true, nil
+}
+
+// VerifySignature - não suportado pelo stub
+func (s *Stub) VerifySignature(certificate, signature, message []byte) (bool, error) {
+	return false, errors.New("VerifySignature não suportado pelo shimtest")
+}
+
+// InvokeChaincode - não suportado pelo stub
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte) ([]byte, error) {
+	return nil, errors.New("InvokeChaincode não suportado pelo shimtest")
+}
+
+// QueryChaincode - não suportado pelo stub
+func (s *Stub) QueryChaincode(chaincodeName string, args [][]byte) ([]byte, error) {
+	return nil, errors.New("QueryChaincode não suportado pelo shimtest")
+}
+
+// SetEvent - registra o evento da transação; apenas o último evento da transação é mantido
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if err := s.falha("SetEvent"); err != nil {
+		return err
+	}
+	s.event = &Event{TxID: s.TxID, Name: name, Payload: payload}
+	return nil
+}
+
+// ============================================================================================================================
+// Estado
+// ============================================================================================================================
+
+// GetState - valor da chave, ou nil se a chave não existir
+func (s *Stub) GetState(key string) ([]byte, error) {
+	if err := s.falha("GetState"); err != nil {
+		return nil, err
+	}
+	return s.state[key], nil
+}
+
+// PutState - grava o valor da chave
+func (s *Stub) PutState(key string, value []byte) error {
+	if err := s.escrita("PutState"); err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("Chave vazia")
+	}
+	s.state[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// DelState - remove a chave
+func (s *Stub) DelState(key string) error {
+	if err := s.escrita("DelState"); err != nil {
+		return err
+	}
+	delete(s.state, key)
+	return nil
+}
+
+// RangeQueryState - itera as chaves no intervalo [startKey, endKey), em ordem
+func (s *Stub) RangeQueryState(startKey, endKey string) (shim.StateRangeQueryIteratorInterface, error) {
+	if err := s.falha("RangeQueryState"); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for k := range s.state {
+		if k >= startKey && (endKey == "" || k < endKey) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	it := &rangeIterator{}
+	for _, k := range keys {
+		it.keys = append(it.keys, k)
+		it.values = append(it.values, s.state[k])
+	}
+	return it, nil
+}
+
+// rangeIterator - iterador retornado por RangeQueryState
+type rangeIterator struct {
+	keys   []string
+	values [][]byte
+	pos    int
+}
+
+func (it *rangeIterator) HasNext() bool {
+	return it.pos < len(it.keys)
+}
+
+func (it *rangeIterator) Next() (string, []byte, error) {
+	if !it.HasNext() {
+		return "", nil, errors.New("Iterador sem próximos valores")
+	}
+	it.pos++
+	return it.keys[it.pos-1], it.values[it.pos-1], nil
+}
+
+func (it *rangeIterator) Close() error {
+	return nil
+}
+
+// ============================================================================================================================
+// Tabelas
+// ============================================================================================================================
+
+// CreateTable - cria a tabela; retorna erro se ela já existir
+func (s *Stub) CreateTable(name string, columnDefinitions []*shim.ColumnDefinition) error {
+	if err := s.escrita("CreateTable"); err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("Nome da tabela vazio")
+	}
+	if _, ok := s.tables[name]; ok {
+		return fmt.Errorf("CreateTable operation failed. Table %s already exists.", name)
+	}
+	if len(columnDefinitions) == 0 || !columnDefinitions[0].Key {
+		return errors.New("A primeira coluna da tabela deve ser chave")
+	}
+	nomes := map[string]bool{}
+	for _, c := range columnDefinitions {
+		if c.Name == "" || nomes[c.Name] {
+			return fmt.Errorf("Coluna inválida ou repetida: [%s]", c.Name)
+		}
+		nomes[c.Name] = true
+	}
+
+	s.tables[name] = &table{
+		def:  shim.Table{Name: name, ColumnDefinitions: columnDefinitions},
+		rows: map[string]shim.Row{},
+	}
+	return nil
+}
+
+// GetTable - definição da tabela; retorna erro se ela não existir, como o shim do Fabric 0.6
+func (s *Stub) GetTable(tableName string) (*shim.Table, error) {
+	if err := s.falha("GetTable"); err != nil {
+		return nil, err
+	}
+	t, ok := s.tables[tableName]
+	if !ok {
+		return nil, errors.New("Table not found.")
+	}
+	def := t.def
+	return &def, nil
+}
+
+// DeleteTable - remove a tabela e todas as suas linhas
+func (s *Stub) DeleteTable(tableName string) error {
+	if err := s.escrita("DeleteTable"); err != nil {
+		return err
+	}
+	if _, ok := s.tables[tableName]; !ok {
+		return errors.New("Table not found.")
+	}
+	delete(s.tables, tableName)
+	return nil
+}
+
+// InsertRow - insere a linha; retorna false, nil se já existir uma linha com a mesma chave
+func (s *Stub) InsertRow(tableName string, row shim.Row) (bool, error) {
+	return s.gravarLinha("InsertRow", tableName, row, false)
+}
+
+// ReplaceRow - substitui a linha; retorna false, nil se não existir linha com a mesma chave
+func (s *Stub) ReplaceRow(tableName string, row shim.Row) (bool, error) {
+	return s.gravarLinha("ReplaceRow", tableName, row, true)
+}
+
+// GetRow - linha com a chave completa informada, ou Row vazia se ela não existir
+func (s *Stub) GetRow(tableName string, key []shim.Column) (shim.Row, error) {
+	if err := s.falha("GetRow"); err != nil {
+		return shim.Row{}, err
+	}
+	t, ok := s.tables[tableName]
+	if !ok {
+		return shim.Row{}, errors.New("Table not found.")
+	}
+	if len(key) != len(t.colunasChave()) {
+		return shim.Row{}, fmt.Errorf("GetRow requer a chave completa (%d colunas)", len(t.colunasChave()))
+	}
+	chave, err := t.codificarChave(key)
+	if err != nil {
+		return shim.Row{}, err
+	}
+	return t.rows[chave], nil
+}
+
+// GetRows - linhas cujas primeiras colunas chave correspondem à chave parcial informada, em ordem de chave
+func (s *Stub) GetRows(tableName string, key []shim.Column) (<-chan shim.Row, error) {
+	if err := s.falha("GetRows"); err != nil {
+		return nil, err
+	}
+	t, ok := s.tables[tableName]
+	if !ok {
+		return nil, errors.New("Table not found.")
+	}
+	if len(key) > len(t.colunasChave()) {
+		return nil, errors.New("Chave maior que a quantidade de colunas chave")
+	}
+	prefixo, err := t.codificarChave(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var chaves []string
+	for k := range t.rows {
+		if strings.HasPrefix(k, prefixo) {
+			chaves = append(chaves, k)
+		}
+	}
+	sort.Strings(chaves)
+
+	rows := make(chan shim.Row, len(chaves))
+	for _, k := range chaves {
+		rows <- t.rows[k]
+	}
+	close(rows)
+	return rows, nil
+}
+
+// DeleteRow - remove a linha com a chave completa informada
+func (s *Stub) DeleteRow(tableName string, key []shim.Column) error {
+	if err := s.escrita("DeleteRow"); err != nil {
+		return err
+	}
+	t, ok := s.tables[tableName]
+	if !ok {
+		return errors.New("Table not found.")
+	}
+	chave, err := t.codificarChave(key)
+	if err != nil {
+		return err
+	}
+	delete(t.rows, chave)
+	return nil
+}
+
+// gravarLinha: implementação de InsertRow e ReplaceRow
+func (s *Stub) gravarLinha(metodo, tableName string, row shim.Row, substituir bool) (bool, error) {
+	if err := s.escrita(metodo); err != nil {
+		return false, err
+	}
+	t, ok := s.tables[tableName]
+	if !ok {
+		return false, errors.New("Table not found.")
+	}
+	if err := t.validarLinha(row); err != nil {
+		return false, err
+	}
+
+	var key []shim.Column
+	for i, c := range t.def.ColumnDefinitions {
+		if c.Key {
+			key = append(key, *row.Columns[i])
+		}
+	}
+	chave, err := t.codificarChave(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, existe := t.rows[chave]
+	if existe != substituir {
+		return false, nil
+	}
+	t.rows[chave] = copiarLinha(row)
+	return true, nil
+}
+
+// colunasChave: definições das colunas chave, na ordem da tabela
+func (t *table) colunasChave() []*shim.ColumnDefinition {
+	var chaves []*shim.ColumnDefinition
+	for _, c := range t.def.ColumnDefinitions {
+		if c.Key {
+			chaves = append(chaves, c)
+		}
+	}
+	return chaves
+}
+
+// validarLinha: verifica a quantidade e o tipo das colunas da linha
+func (t *table) validarLinha(row shim.Row) error {
+	if len(row.Columns) != len(t.def.ColumnDefinitions) {
+		return fmt.Errorf("Invalid row. Expected %d columns, got %d", len(t.def.ColumnDefinitions), len(row.Columns))
+	}
+	for i, c := range t.def.ColumnDefinitions {
+		if !tipoCompativel(c.Type, row.Columns[i]) {
+			return fmt.Errorf("Invalid row. Column [%s] has an incompatible type", c.Name)
+		}
+	}
+	return nil
+}
+
+// codificarChave: representação textual das colunas chave, preservando a ordem de prefixo
+func (t *table) codificarChave(key []shim.Column) (string, error) {
+	defs := t.colunasChave()
+	var b bytes.Buffer
+	for i := range key {
+		if !tipoCompativel(defs[i].Type, &key[i]) {
+			return "", fmt.Errorf("Chave inválida para a coluna [%s]", defs[i].Name)
+		}
+		fmt.Fprintf(&b, "%d:%v\x00", len(valorColuna(&key[i])), valorColuna(&key[i]))
+	}
+	return b.String(), nil
+}
+
+// valorColuna: valor da coluna formatado como texto
+func valorColuna(c *shim.Column) string {
+	switch v := c.Value.(type) {
+	case *shim.Column_String_:
+		return v.String_
+	case *shim.Column_Int32:
+		return fmt.Sprint(v.Int32)
+	case *shim.Column_Int64:
+		return fmt.Sprint(v.Int64)
+	case *shim.Column_Uint32:
+		return fmt.Sprint(v.Uint32)
+	case *shim.Column_Uint64:
+		return fmt.Sprint(v.Uint64)
+	case *shim.Column_Bytes:
+		return string(v.Bytes)
+	case *shim.Column_Bool:
+		return fmt.Sprint(v.Bool)
+	}
+	return ""
+}
+
+// tipoCompativel: verifica se o valor da coluna corresponde ao tipo definido na tabela
+func tipoCompativel(tipo shim.ColumnDefinition_Type, c *shim.Column) bool {
+	if c == nil {
+		return false
+	}
+	switch c.Value.(type) {
+	case *shim.Column_String_:
+		return tipo == shim.ColumnDefinition_STRING
+	case *shim.Column_Int32:
+		return tipo == shim.ColumnDefinition_INT32
+	case *shim.Column_Int64:
+		return tipo == shim.ColumnDefinition_INT64
+	case *shim.Column_Uint32:
+		return tipo == shim.ColumnDefinition_UINT32
+	case *shim.Column_Uint64:
+		return tipo == shim.ColumnDefinition_UINT64
+	case *shim.Column_Bytes:
+		return tipo == shim.ColumnDefinition_BYTES
+	case *shim.Column_Bool:
+		return tipo == shim.ColumnDefinition_BOOL
+	}
+	return false
+}
+
+// copiarLinha: cópia da linha, para que alterações do chaincode não afetem a tabela
+func copiarLinha(row shim.Row) shim.Row {
+	columns := make([]*shim.Column, len(row.Columns))
+	for i, c := range row.Columns {
+		copia := *c
+		columns[i] = &copia
+	}
+	return shim.Row{Columns: columns}
+}
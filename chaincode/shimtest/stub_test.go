@@ -0,0 +1,153 @@
+package shimtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// colunas de teste: (conta STRING chave, item STRING chave, valor INT64)
+func criarTabela(t *testing.T, s *Stub) {
+	err := s.CreateTable("T", []*shim.ColumnDefinition{
+		&shim.ColumnDefinition{Name: "conta", Type: shim.ColumnDefinition_STRING, Key: true},
+		&shim.ColumnDefinition{Name: "item", Type: shim.ColumnDefinition_STRING, Key: true},
+		&shim.ColumnDefinition{Name: "valor", Type: shim.ColumnDefinition_INT64, Key: false},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+}
+
+func linha(conta, item string, valor int64) shim.Row {
+	return shim.Row{Columns: []*shim.Column{
+		&shim.Column{Value: &shim.Column_String_{String_: conta}},
+		&shim.Column{Value: &shim.Column_String_{String_: item}},
+		&shim.Column{Value: &shim.Column_Int64{Int64: valor}},
+	}}
+}
+
+func chave(valores ...string) []shim.Column {
+	var key []shim.Column
+	for _, v := range valores {
+		key = append(key, shim.Column{Value: &shim.Column_String_{String_: v}})
+	}
+	return key
+}
+
+func TestTabelas(t *testing.T) {
+	s := NewStub()
+	criarTabela(t, s)
+
+	if err := s.CreateTable("T", nil); err == nil {
+		t.Error("CreateTable deveria falhar para tabela existente")
+	}
+	if _, err := s.GetTable("X"); err == nil {
+		t.Error("GetTable deveria falhar para tabela inexistente")
+	}
+
+	if ok, err := s.InsertRow("T", linha("a", "1", 10)); !ok || err != nil {
+		t.Fatalf("InsertRow = %v, %v", ok, err)
+	}
+	if ok, err := s.InsertRow("T", linha("a", "1", 20)); ok || err != nil {
+		t.Errorf("InsertRow duplicado = %v, %v; esperado false, nil", ok, err)
+	}
+	if ok, err := s.ReplaceRow("T", linha("b", "1", 20)); ok || err != nil {
+		t.Errorf("ReplaceRow inexistente = %v, %v; esperado false, nil", ok, err)
+	}
+	if ok, err := s.ReplaceRow("T", linha("a", "1", 30)); !ok || err != nil {
+		t.Errorf("ReplaceRow = %v, %v", ok, err)
+	}
+	if _, err := s.InsertRow("T", shim.Row{Columns: linha("a", "2", 1).Columns[:2]}); err == nil {
+		t.Error("InsertRow deveria validar a quantidade de colunas")
+	}
+
+	row, err := s.GetRow("T", chave("a", "1"))
+	if err != nil || row.Columns[2].GetInt64() != 30 {
+		t.Errorf("GetRow = %v, %v", row, err)
+	}
+	row, err = s.GetRow("T", chave("a", "9"))
+	if err != nil || len(row.Columns) != 0 {
+		t.Errorf("GetRow inexistente = %v, %v; esperado linha vazia", row, err)
+	}
+
+	s.InsertRow("T", linha("a", "2", 1))
+	s.InsertRow("T", linha("ab", "1", 1))
+	rows, err := s.GetRows("T", chave("a"))
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	n := 0
+	for range rows {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("GetRows retornou %d linhas; esperado 2", n)
+	}
+
+	if err := s.DeleteTable("T"); err != nil {
+		t.Errorf("DeleteTable: %v", err)
+	}
+	if _, err := s.GetRow("T", chave("a", "1")); err == nil {
+		t.Error("GetRow deveria falhar após DeleteTable")
+	}
+}
+
+// chaincode de teste que grava o argumento e falha se ele for "erro"
+type ccTeste struct{}
+
+func (ccTeste) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (ccTeste) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	if err := stub.PutState(args[0], []byte(stub.GetTxID())); err != nil {
+		return nil, err
+	}
+	stub.SetEvent("gravado", []byte(args[0]))
+	if args[0] == "erro" {
+		return nil, errors.New("erro")
+	}
+	return nil, nil
+}
+
+func (c ccTeste) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return c.Invoke(stub, function, args)
+}
+
+func TestTransacoes(t *testing.T) {
+	s := NewStub()
+
+	if _, err := s.MockInvoke(ccTeste{}, "gravar", "ok"); err != nil {
+		t.Fatalf("MockInvoke: %v", err)
+	}
+	if string(s.State("ok")) != "tx1" {
+		t.Errorf("estado = %q; esperado tx1", s.State("ok"))
+	}
+	if e := s.LastEvent(); e == nil || e.Name != "gravado" || e.TxID != "tx1" {
+		t.Errorf("evento = %+v", e)
+	}
+
+	// Transação com erro é descartada
+	if _, err := s.MockInvoke(ccTeste{}, "gravar", "erro"); err == nil {
+		t.Fatal("MockInvoke deveria retornar erro")
+	}
+	if s.State("erro") != nil || len(s.Events) != 1 {
+		t.Error("alterações de transação com erro não foram descartadas")
+	}
+
+	// Query não pode gravar
+	if _, err := s.MockQuery(ccTeste{}, "gravar", "query"); err == nil {
+		t.Error("MockQuery deveria impedir escritas")
+	}
+
+	// Falhas simuladas
+	s.SimularFalha("PutState", errors.New("falha"))
+	if _, err := s.MockInvoke(ccTeste{}, "gravar", "x"); err == nil {
+		t.Error("SimularFalha não foi aplicada")
+	}
+	s.SimularFalha("PutState", nil)
+	if _, err := s.MockInvoke(ccTeste{}, "gravar", "x"); err != nil {
+		t.Errorf("SimularFalha não foi removida: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// versaoChaincode: versão do build do chaincode, atualizada a cada publicação. Nos builds locais
+// pode ser substituída com -ldflags "-X main.versaoChaincode=<versão>"
+var versaoChaincode = "1.0.0"
+
+// varianteChaincode: plataforma do chaincode. O chaincode do diretório chaincode-v2 informa "fabric-2.x"
+const varianteChaincode = "fabric-0.6"
+
+// Definição da Struct VersaoChaincode, retornada por versao
+type VersaoChaincode struct {
+	Versao                  string        `json:"versao"`
+	Variante                string        `json:"variante"`
+	Esquema                 VersaoEsquema `json:"esquema"`
+	Modulos                 Modulos       `json:"modulos"`
+	AdministradorRegistrado bool          `json:"administrador_registrado"` // o Init registrou o administrador (módulo autenticacao)
+	Pausado                 bool          `json:"pausado"`                  // somente leitura (ver pausar)
+	Propostas               int           `json:"propostas"`                // quantidade de propostas, em qualquer forma de armazenamento
+	Entregas                int           `json:"entregas"`                 // quantidade de entregas registradas (módulo notificacao)
+	Tabelas                 []string      `json:"tabelas"`                  // tabelas existentes, sem a contagem das linhas
+}
+
+// versao: função Query para verificar a versão e o estado do chaincode implantado no peer.
+// Não recebe argumentos
+func (t *BoletoPropostaChaincode) versao(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	logChamada(stub).Debug("versao...")
+
+	modulos, err := t.obterModulos(stub)
+	if err != nil {
+		return nil, err
+	}
+	esquema, err := obterVersaoEsquema(stub)
+	if err != nil {
+		return nil, err
+	}
+	modulos.Armazenamento = normalizarArmazenamento(modulos.Armazenamento)
+	resultado := VersaoChaincode{
+		Versao:   versaoChaincode,
+		Variante: varianteChaincode,
+		Esquema: VersaoEsquema{
+			Versao:        esquema,
+			VersaoAtual:   versaoEsquemaAtual,
+			Armazenamento: modulos.Armazenamento,
+		},
+		Modulos: modulos,
+		Tabelas: []string{},
+	}
+
+	admin, err := stub.GetState("admin")
+	if err != nil {
+		return nil, novoErro(codigoErroInterno, msgFalhaObterAdmin)
+	}
+	resultado.AdministradorRegistrado = len(admin) > 0
+	pausa, err := obterPausa(stub)
+	if err != nil {
+		return nil, err
+	}
+	resultado.Pausado = pausa.Pausado
+
+	// As propostas são contadas pelo repositório, que conhece a forma de armazenamento
+	repositorio, err := obterRepositorio(modulos.Armazenamento)
+	if err != nil {
+		return nil, err
+	}
+	resultado.Propostas, err = repositorio.Contar(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	tabelas := append(repositorioTabela{mapeamentoProposta}.tabelas(), nomeTabelaEntrega)
+	for _, tabela := range tabelas {
+		// GetTable retorna erro quando a tabela não existe
+		tb, err := stub.GetTable(tabela)
+		if err != nil || tb == nil {
+			continue
+		}
+		resultado.Tabelas = append(resultado.Tabelas, tabela)
+		if tabela == nomeTabelaEntrega {
+			resultado.Entregas, err = contarLinhas(stub, tabela)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return json.Marshal(resultado)
+}
+
+// contarLinhas: quantidade de linhas da tabela. A API de tabelas da versão 0.6 não tem contagem,
+// e as linhas são lidas sem decodificar
+func contarLinhas(stub shim.ChaincodeStubInterface, tabela string) (int, error) {
+	rows, err := stub.GetRows(tabela, []shim.Column{})
+	if err != nil {
+		return 0, novoErro(codigoErroInterno, msgFalhaContarLinhas, tabela, err)
+	}
+	linhas := 0
+	for range rows {
+		linhas++
+	}
+	return linhas, nil
+}
@@ -0,0 +1,55 @@
+// nome do package
+package main
+
+// lista de imports
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersao(t *testing.T) {
+	stub, cc := novoChaincode(t)
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false")
+	stub.MockInvoke(cc, "confirmarEntrega", "1", "bc-desafio", statusEntregue)
+
+	res, err := stub.MockQuery(cc, "versao")
+	var versao VersaoChaincode
+	if err != nil || lerDados(res, &versao) != nil {
+		t.Fatalf("versao = %s, %v", res, err)
+	}
+	if versao.Versao != versaoChaincode || versao.Variante != "fabric-0.6" {
+		t.Errorf("versão = %s %s", versao.Variante, versao.Versao)
+	}
+	if versao.Esquema != (VersaoEsquema{versaoEsquemaAtual, versaoEsquemaAtual, armazenamentoTabela}) {
+		t.Errorf("esquema = %+v", versao.Esquema)
+	}
+	if !versao.Modulos.Autenticacao || !versao.Modulos.Notificacao || !versao.AdministradorRegistrado {
+		t.Errorf("módulos = %+v, administrador registrado = %t", versao.Modulos, versao.AdministradorRegistrado)
+	}
+	if versao.Propostas != 2 || versao.Entregas != 1 {
+		t.Errorf("propostas = %d, entregas = %d", versao.Propostas, versao.Entregas)
+	}
+	if strings.Join(versao.Tabelas, ",") != "Proposta,Proposta_cpfPagador,Entrega" {
+		t.Errorf("tabelas = %v", versao.Tabelas)
+	}
+}
+
+func TestVersaoArmazenamentoJSON(t *testing.T) {
+	// Sem módulos, não há administrador nem a tabela de entregas
+	stub, cc := iniciarChaincode(t, "armazenamento=json")
+	stub.MockInvoke(cc, "registrarProposta", "p1", "111", "true", "false", "false")
+	stub.MockInvoke(cc, "registrarProposta", "p2", "222", "true", "false", "false")
+	stub.MockInvoke(cc, "registrarProposta", "p3", "333", "true", "false", "false")
+
+	res, _ := stub.MockQuery(cc, "versao")
+	var versao VersaoChaincode
+	lerDados(res, &versao)
+	if versao.AdministradorRegistrado || versao.Modulos.Autenticacao || versao.Esquema.Armazenamento != armazenamentoJSON || len(versao.Tabelas) != 0 {
+		t.Errorf("versao sem módulos = %s", res)
+	}
+	// As propostas em JSON são contadas pelo repositório, sem tabelas
+	if versao.Propostas != 3 || versao.Entregas != 0 {
+		t.Errorf("propostas = %d, entregas = %d", versao.Propostas, versao.Entregas)
+	}
+}
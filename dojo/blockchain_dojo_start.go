@@ -1,3 +1,6 @@
+//go:build ignore
+// +build ignore
+
 /*
 Copyright IBM Corp 2016 All Rights Reserved.
 
@@ -28,33 +31,17 @@ import (
 	"errors"
 	"fmt"
 
-	
-	"github.com/hyperledger/fabric/core/chaincode/shim"	
+	"github.com/hyperledger/fabric/core/chaincode/shim"
 )
 
 // BoletoPropostaChaincode - implementacao do chaincode
 type BoletoPropostaChaincode struct {
 }
 
-
-
 // Definição da Struct Proposta e parametros para exportação para JSON
 
-
-
-
-
-
-
 // consts associadas à tabela de Propostas
 
-
-
-
-
-
-
-
 // ============================================================================================================================
 // Main
 // ============================================================================================================================
@@ -67,45 +54,22 @@ func main() {
 
 // ============================================================================================================================
 // Init
-// 		Inicia/Reinicia a tabela de propostas
+//
+//	Inicia/Reinicia a tabela de propostas
+//
 // ============================================================================================================================
 func (t *BoletoPropostaChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	fmt.Println("Init Chaincode...")
 
 	// Verificação da quantidade de argumentos recebidos
 
-
-
-
-
 	// Verifica se a tabela 'Proposta' existe
 	fmt.Println("Verificando se a tabela 'Proposta' existe...")
-	
-
 
 	// Se a tabela 'Proposta' já existir, excluir a tabela
-	
-
-
-
-
 
 	// Criar tabela de Propostas
 	fmt.Println("Criando a tabela 'Proposta'...")
-	
-
-
-
-
-
-
-
-
-
-
-
-
-
 
 	fmt.Println("Tabela 'Proposta' criada com sucesso.")
 
@@ -114,7 +78,6 @@ func (t *BoletoPropostaChaincode) Init(stub shim.ChaincodeStubInterface, functio
 	return nil, nil
 }
 
-
 // ============================================================================================================================
 // Invoke Functions
 // ============================================================================================================================
@@ -122,19 +85,14 @@ func (t *BoletoPropostaChaincode) Init(stub shim.ChaincodeStubInterface, functio
 // Invoke - Ponto de entrada para chamadas do tipo Invoke.
 // Funções suportadas:
 // "init": inicializa o estado do chaincode, também utilizado como reset
-// "registrarProposta(Id, cpfPagador, pagadorAceitou, beneficiarioAceitou, 
+// "registrarProposta(Id, cpfPagador, pagadorAceitou, beneficiarioAceitou,
 // boletoPago)": para registrar uma nova proposta ou atualizar uma já existente.
 func (t *BoletoPropostaChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	fmt.Println("Invoke Chaincode...")
 	fmt.Println("invoke is running " + function)
 
-	// Estrutura de Seleção para escolher qual função será executada, 
+	// Estrutura de Seleção para escolher qual função será executada,
 	// de acordo com a funcao chamada
-	
-
-
-
-
 
 	fmt.Println("invoke não encontrou a func: " + function) //error
 
@@ -152,63 +110,21 @@ func (t *BoletoPropostaChaincode) registrarProposta(stub shim.ChaincodeStubInter
 
 	// Verifica se a quantidade de argumentos recebidas corresponde a esperada
 
-
-
-
-
-	// Obtem os valores da array de arguments (args) e 
+	// Obtem os valores da array de arguments (args) e
 	// os converte no tipo necessário para salvar na tabela 'Proposta'
 
-
-
-
-
-
-
-
-
-
-
-
-
-
 	// Registra a proposta na tabela 'Proposta'
-	
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
 
 	// Caso a proposta já exista
-	
-		// Trecho para atualizar uma proposta existente
-		// substitui um registro existente em uma linha com o registro associado ao idProposta recebido nos argumentos
-
-		
-
-
-
-
-		
 
+	// Trecho para atualizar uma proposta existente
+	// substitui um registro existente em uma linha com o registro associado ao idProposta recebido nos argumentos
 
 	fmt.Println("Proposta criada!")
 
 	return nil, nil
 }
 
-
 // ============================================================================================================================
 // Query
 // ============================================================================================================================
@@ -223,15 +139,10 @@ func (t *BoletoPropostaChaincode) Query(stub shim.ChaincodeStubInterface, functi
 
 	fmt.Println("query is running " + function)
 
-	// Estrutura de Seleção para escolher qual função será executada, 
+	// Estrutura de Seleção para escolher qual função será executada,
 	// de acordo com a funcao chamada
 
-
-
-
-
-
-	fmt.Println("query encontrou a func: " + function) 
+	fmt.Println("query encontrou a func: " + function)
 
 	return nil, errors.New("Query de função desconhecida: " + function)
 }
@@ -240,55 +151,24 @@ func (t *BoletoPropostaChaincode) Query(stub shim.ChaincodeStubInterface, functi
 // args[0]: Id. Hash da proposta
 func (t *BoletoPropostaChaincode) consultarProposta(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	fmt.Println("consultarProposta...")
-	
-	//var resProposta Proposta		// Proposta
-	var propostaAsBytes []byte		// retorno do json em bytes
-	
-	// Verifica se a quantidade de argumentos recebidas corresponde a esperada
-
-
 
+	//var resProposta Proposta		// Proposta
+	var propostaAsBytes []byte // retorno do json em bytes
 
+	// Verifica se a quantidade de argumentos recebidas corresponde a esperada
 
 	// Obtem os valores dos argumentos e os prepara para salvar na tabela 'Proposta'
-	
-
-
 
 	// Define o valor de coluna do registro a ser buscado
-	
-
-
 
 	// Consultar a proposta na tabela 'Proposta'
-	
-
-
-
 
 	// Tratamento para o caso de não encontrar nenhuma proposta correspondente
-	
 
-
-
-
-
-	// Criação do objeto Proposta	
-	
-
-
-
-
-
-
-	
+	// Criação do objeto Proposta
 
 	// Converter o objeto da Proposta para Bytes, para retorná-lo em formato JSON
-	
-
-
-
 
 	// retorna o objeto em bytes
 	return propostaAsBytes, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,300 @@
+/*
+Relay responsável por entregar os eventos das propostas à API externa.
+
+O chaincode não faz chamadas HTTP: cada alteração de proposta é registrada no
+ledger como um evento (PropostaCriada, PropostaAtualizada, PropostaAceita,
+BoletoPago, CancelamentoSolicitado, PropostaCancelada, PagamentoEstornado). O relay consulta os eventos pela função
+consultarEventos, envia cada um via POST para a API externa (argumento -api ou
+url_externa da configuração do chaincode), registra o
+resultado no ledger pela função confirmarEntrega e guarda em um arquivo o id do
+último evento processado.
+
+O Invoke de confirmarEntrega retorna quando a transação é submetida, antes do
+commit. Por isso o cursor só avança depois que a entrega aparece em
+consultarEntregas (ver aguardarConfirmacao). A entrega é "ao menos uma vez": se a
+confirmação falhar ou não for confirmada no prazo (argumento -confirmacao), o
+evento é reenviado à API externa no próximo ciclo. Cada assinante deve ter o seu
+próprio relay e arquivo de cursor.
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// evento - campos do evento utilizados pelo relay; o JSON completo é repassado à API externa
+type evento struct {
+	ID         string `json:"id_evento"`
+	Tipo       string `json:"tipo_evento"`
+	IDProposta string `json:"id_proposta"`
+}
+
+// entrega - campos da entrega retornada por consultarEntregas
+type entrega struct {
+	IDEvento  string `json:"id_evento"`
+	Assinante string `json:"assinante"`
+	Status    string `json:"status"`
+}
+
+// erroTemporario - falha de entrega que pode ser resolvida com uma nova tentativa
+type erroTemporario struct {
+	err error
+}
+
+func (e erroTemporario) Error() string { return e.err.Error() }
+
+// urlAPIPadrao: URL da API externa sem o argumento -api e sem a url_externa na configuração do chaincode
+const urlAPIPadrao = "http://localhost:8080/atualizar"
+
+// intervaloConfirmacao: intervalo entre as consultas da entrega enquanto o commit de confirmarEntrega é aguardado
+const intervaloConfirmacao = time.Second
+
+// Relay - estado do relay
+type Relay struct {
+	Peer        *Peer
+	URLAPI      string // vazia para utilizar a url_externa da configuração do chaincode (ver urlConfigurada)
+	Assinante   string // nome do sistema que recebe os eventos, registrado em confirmarEntrega
+	Client      *http.Client
+	Tentativas  int
+	Confirmacao time.Duration // tempo máximo de espera pelo commit de confirmarEntrega
+	Arquivo     string        // arquivo com o id do último evento entregue
+	cursor      uint64
+}
+
+// ============================================================================================================================
+// Main
+// ============================================================================================================================
+func main() {
+	urlPeer := flag.String("peer", "http://localhost:7050", "URL da API REST do peer")
+	chaincode := flag.String("chaincode", "", "nome (hash) do chaincode implantado")
+	secureContext := flag.String("secure-context", "", "usuário registrado no peer (secureContext)")
+	metadata := flag.String("metadata", "", "metadata do administrador do chaincode, enviada em confirmarEntrega")
+	assinante := flag.String("assinante", "bc-desafio", "nome do assinante registrado nas confirmações de entrega")
+	urlAPI := flag.String("api", "", "URL da API externa (padrão: url_externa da configuração do chaincode ou "+urlAPIPadrao+")")
+	arquivo := flag.String("cursor", "relay.cursor", "arquivo em que o id do último evento entregue é armazenado")
+	intervalo := flag.Duration("intervalo", 5*time.Second, "intervalo entre as consultas de novos eventos")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout das chamadas HTTP")
+	tentativas := flag.Int("tentativas", 3, "tentativas de entrega de cada evento antes de aguardar o próximo ciclo")
+	confirmacao := flag.Duration("confirmacao", 30*time.Second, "tempo máximo de espera pelo commit de confirmarEntrega antes de reenviar o evento")
+	flag.Parse()
+
+	if *chaincode == "" {
+		log.Fatal("Informe o nome do chaincode (-chaincode)")
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	r := &Relay{
+		Peer: &Peer{
+			URL:           strings.TrimRight(*urlPeer, "/"),
+			Chaincode:     *chaincode,
+			SecureContext: *secureContext,
+			Metadata:      []byte(*metadata),
+			Client:        client,
+		},
+		URLAPI:      *urlAPI,
+		Assinante:   *assinante,
+		Client:      client,
+		Tentativas:  *tentativas,
+		Confirmacao: *confirmacao,
+		Arquivo:     *arquivo,
+	}
+	if err := r.carregarCursor(); err != nil {
+		log.Fatalf("Falha ao carregar o cursor %s: %v", r.Arquivo, err)
+	}
+
+	log.Printf("Relay iniciado a partir do evento %d", r.cursor)
+	for {
+		entregues, err := r.processar()
+		if err != nil {
+			log.Printf("Falha ao processar eventos: %v", err)
+		}
+		if entregues == 0 || err != nil {
+			time.Sleep(*intervalo)
+		}
+	}
+}
+
+// processar: consulta os eventos pendentes e os entrega em ordem.
+// Retorna a quantidade de eventos processados
+func (r *Relay) processar() (int, error) {
+	payload, err := r.Peer.Query("consultarEventos", strconv.FormatUint(r.cursor, 10))
+	if err != nil {
+		return 0, err
+	}
+
+	var eventos []json.RawMessage
+	if err := json.Unmarshal(payload, &eventos); err != nil {
+		return 0, fmt.Errorf("Resposta inválida de consultarEventos: %v", err)
+	}
+	if len(eventos) == 0 {
+		return 0, nil
+	}
+	urlAPI, err := r.urlConfigurada()
+	if err != nil {
+		return 0, fmt.Errorf("Falha ao obter a URL da API externa: %v", err)
+	}
+
+	for i, raw := range eventos {
+		var e evento
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return i, fmt.Errorf("Evento inválido: %v", err)
+		}
+		seq, err := strconv.ParseUint(e.ID, 10, 64)
+		if err != nil {
+			return i, fmt.Errorf("id_evento inválido: %s", e.ID)
+		}
+
+		err = r.entregar(urlAPI, raw)
+		if _, ok := err.(erroTemporario); ok {
+			// Mantém a ordem: o evento será reenviado no próximo ciclo
+			return i, fmt.Errorf("Evento %s não entregue: %v", e.ID, err)
+		}
+		status := "entregue"
+		if err != nil {
+			// Falhas definitivas (ex.: 4xx) não são reenviadas
+			log.Printf("Evento %s (%s) rejeitado pela API externa: %v", e.ID, e.Tipo, err)
+			status = "rejeitado"
+		} else {
+			log.Printf("Evento %s (%s) entregue", e.ID, e.Tipo)
+		}
+
+		// Registra o resultado da entrega no ledger e aguarda o commit antes de avançar o cursor
+		if _, err := r.Peer.Invoke("confirmarEntrega", e.ID, r.Assinante, status); err != nil {
+			return i, fmt.Errorf("Falha ao confirmar a entrega do evento %s: %v", e.ID, err)
+		}
+		if err := r.aguardarConfirmacao(e, status); err != nil {
+			return i, err
+		}
+
+		r.cursor = seq
+		if err := r.salvarCursor(); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(eventos), nil
+}
+
+// entregar: envia o evento para a API externa, com novas tentativas para falhas temporárias
+func (r *Relay) entregar(urlAPI string, raw []byte) error {
+	var err error
+	espera := time.Second
+	for tentativa := 1; tentativa <= r.Tentativas; tentativa++ {
+		err = r.enviar(urlAPI, raw)
+		if _, ok := err.(erroTemporario); !ok {
+			return err
+		}
+		log.Printf("Tentativa %d falhou: %v", tentativa, err)
+		if tentativa < r.Tentativas {
+			time.Sleep(espera)
+			espera *= 2
+		}
+	}
+	return err
+}
+
+// aguardarConfirmacao: consulta as entregas da proposta até que a entrega do evento, com o status
+// informado, esteja no ledger. O Invoke retorna antes do commit, e a transação pode ainda ser rejeitada
+// pelo chaincode (ex.: chaincode pausado); nesse caso, após o prazo, o evento é processado novamente
+func (r *Relay) aguardarConfirmacao(e evento, status string) error {
+	limite := time.Now().Add(r.Confirmacao)
+	for {
+		payload, err := r.Peer.Query("consultarEntregas", e.IDProposta)
+		if err != nil {
+			return fmt.Errorf("Falha ao consultar a entrega do evento %s: %v", e.ID, err)
+		}
+		var entregas []entrega
+		if err := json.Unmarshal(payload, &entregas); err != nil {
+			return fmt.Errorf("Resposta inválida de consultarEntregas: %v", err)
+		}
+		for _, en := range entregas {
+			if en.IDEvento == e.ID && en.Assinante == r.Assinante && en.Status == status {
+				return nil
+			}
+		}
+		if time.Now().After(limite) {
+			return fmt.Errorf("Entrega do evento %s não confirmada no ledger em %v", e.ID, r.Confirmacao)
+		}
+		time.Sleep(intervaloConfirmacao)
+	}
+}
+
+// urlConfigurada: URL da API externa informada no argumento -api ou, sem ele, na configuração do chaincode.
+// A configuração é consultada a cada ciclo, para seguir as alterações feitas com atualizarConfiguracao
+func (r *Relay) urlConfigurada() (string, error) {
+	if r.URLAPI != "" {
+		return r.URLAPI, nil
+	}
+	payload, err := r.Peer.Query("consultarConfiguracao")
+	if err != nil {
+		return "", err
+	}
+	var configuracao struct {
+		URLExterna string `json:"url_externa"`
+	}
+	if err := json.Unmarshal(payload, &configuracao); err != nil {
+		return "", fmt.Errorf("Resposta inválida de consultarConfiguracao: %v", err)
+	}
+	if configuracao.URLExterna == "" {
+		return urlAPIPadrao, nil
+	}
+	return configuracao.URLExterna, nil
+}
+
+// enviar: executa o POST do evento na API externa
+func (r *Relay) enviar(urlAPI string, raw []byte) error {
+	req, err := http.NewRequest("POST", urlAPI, bytes.NewBuffer(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return erroTemporario{err}
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode >= 500:
+		return erroTemporario{fmt.Errorf("%s: %s", resp.Status, string(body))}
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("%s: %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// carregarCursor: lê o id do último evento entregue
+func (r *Relay) carregarCursor() error {
+	dados, err := ioutil.ReadFile(r.Arquivo)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.cursor, err = strconv.ParseUint(strings.TrimSpace(string(dados)), 10, 64)
+	return err
+}
+
+// salvarCursor: persiste o id do último evento entregue
+func (r *Relay) salvarCursor() error {
+	tmp := r.Arquivo + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatUint(r.cursor, 10)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.Arquivo)
+}
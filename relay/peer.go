@@ -0,0 +1,148 @@
+/*
+Cliente da API REST do peer do Hyperledger Fabric 0.6, utilizado pelo relay.
+
+As funções Query retornam os dados do envelope de resposta do chaincode. As
+funções Invoke apenas submetem a transação: o peer retorna o id da transação
+antes do commit, e o resultado deve ser verificado com uma Query (ver
+aguardarConfirmacao em main.go).
+*/
+
+// nome do package
+package main
+
+// lista de imports
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Peer - cliente da API REST (JSON-RPC 2.0) do peer do Hyperledger Fabric 0.6
+type Peer struct {
+	URL           string // ex.: http://localhost:7050
+	Chaincode     string // nome (hash) do chaincode implantado
+	SecureContext string // usuário registrado no peer, quando a segurança está habilitada
+	Metadata      []byte // metadata do administrador, verificada pelo chaincode nas funções Invoke
+	Client        *http.Client
+}
+
+// requisição JSON-RPC enviada para o endpoint /chaincode
+type requisicaoRPC struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  parametrosRPC `json:"params"`
+	ID      int64         `json:"id"`
+}
+
+type parametrosRPC struct {
+	Type          int           `json:"type"`
+	ChaincodeID   chaincodeID   `json:"chaincodeID"`
+	CtorMsg       chaincodeArgs `json:"ctorMsg"`
+	SecureContext string        `json:"secureContext,omitempty"`
+	Metadata      []byte        `json:"metadata,omitempty"`
+}
+
+type chaincodeID struct {
+	Name string `json:"name"`
+}
+
+type chaincodeArgs struct {
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+}
+
+// resposta JSON-RPC do endpoint /chaincode
+type respostaRPC struct {
+	Result *struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// resposta do chaincode: todas as funções retornam o resultado ou o erro neste envelope
+type respostaChaincode struct {
+	Sucesso bool            `json:"sucesso"`
+	Dados   json.RawMessage `json:"dados"`
+	Erro    *struct {
+		Codigo   string `json:"codigo"`
+		Mensagem string `json:"mensagem"`
+	} `json:"erro"`
+}
+
+// Query: executa uma função Query do chaincode e retorna os dados do envelope de resposta
+func (p *Peer) Query(function string, args ...string) ([]byte, error) {
+	message, err := p.chamar("query", function, args)
+	if err != nil {
+		return nil, err
+	}
+	var res respostaChaincode
+	if err := json.Unmarshal(message, &res); err != nil {
+		return nil, fmt.Errorf("Resposta inválida do chaincode: %s", string(message))
+	}
+	if !res.Sucesso {
+		if res.Erro == nil {
+			return nil, fmt.Errorf("query %s: resposta sem sucesso", function)
+		}
+		return nil, fmt.Errorf("query %s: [%s] %s", function, res.Erro.Codigo, res.Erro.Mensagem)
+	}
+	return res.Dados, nil
+}
+
+// Invoke: submete uma transação Invoke do chaincode. O peer retorna o id da transação
+func (p *Peer) Invoke(function string, args ...string) ([]byte, error) {
+	return p.chamar("invoke", function, args)
+}
+
+// chamar: envia a requisição JSON-RPC para o peer
+func (p *Peer) chamar(metodo, function string, args []string) ([]byte, error) {
+	if args == nil {
+		args = []string{}
+	}
+	req := requisicaoRPC{
+		JSONRPC: "2.0",
+		Method:  metodo,
+		Params: parametrosRPC{
+			Type:          1, // GOLANG
+			ChaincodeID:   chaincodeID{Name: p.Chaincode},
+			CtorMsg:       chaincodeArgs{Function: function, Args: args},
+			SecureContext: p.SecureContext,
+			Metadata:      p.Metadata,
+		},
+		ID: 1,
+	}
+	reqAsBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client.Post(p.URL+"/chaincode", "application/json", bytes.NewBuffer(reqAsBytes))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var res respostaRPC
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("Resposta inválida do peer (%s): %s", resp.Status, string(body))
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%s %s: %s (%s)", metodo, function, res.Error.Message, res.Error.Data)
+	}
+	if res.Result == nil {
+		return nil, errors.New("Resposta do peer sem resultado")
+	}
+	return []byte(res.Result.Message), nil
+}